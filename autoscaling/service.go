@@ -0,0 +1,243 @@
+package autoscaling
+
+import (
+	"context"
+	"fmt"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/applicationautoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/applicationautoscaling/types"
+)
+
+const (
+	namespace = types.ServiceNamespaceLambda
+	dimension = types.ScalableDimensionLambdaFunctionProvisionedConcurrency
+)
+
+func Client(ctx context.Context) *applicationautoscaling.Client {
+	cfg, _ := config.LoadDefaultConfig(ctx)
+	return applicationautoscaling.NewFromConfig(cfg)
+}
+
+// ResourceId identifies the provisioned concurrency of the version or alias in Application Auto Scaling
+func ResourceId(functionName string, qualifier string) string {
+	return "function:" + functionName + ":" + qualifier
+}
+
+// policyName is the name of the target tracking policy. Scheduled actions are named the same way after the schedule.
+func policyName(functionName string, name string) string {
+	return functionName + "-" + name
+}
+
+func ValidateAutoscaling(provisioned *common.ProvisionedConcurrencyConfig) error {
+	if provisioned == nil || provisioned.Autoscaling == nil {
+		return nil
+	}
+	var errorMessage strings.Builder
+	autoscaling := provisioned.Autoscaling
+	if autoscaling.Min < 1 {
+		errorMessage.WriteString("Autoscaling min must be at least 1.\n")
+	}
+	if autoscaling.Max < autoscaling.Min {
+		errorMessage.WriteString("Autoscaling max cannot be less than min.\n")
+	}
+	if provisioned.Count < autoscaling.Min || provisioned.Count > autoscaling.Max {
+		errorMessage.WriteString(fmt.Sprintf("Provisioned Concurrency count %d must be between the autoscaling min and max.\n", provisioned.Count))
+	}
+	if autoscaling.TargetUtilization < 0.1 || autoscaling.TargetUtilization > 0.9 {
+		errorMessage.WriteString("Autoscaling target utilization must be between 0.1 and 0.9.\n")
+	}
+	var names []string
+	for _, schedule := range autoscaling.Schedules {
+		if common.TrimAndCheckEmptyString(&schedule.Name) {
+			errorMessage.WriteString("Autoscaling schedule Name cannot be null.\n")
+			continue
+		}
+		if slices.Contains(names, schedule.Name) {
+			errorMessage.WriteString(fmt.Sprintf("Autoscaling schedule %s is declared more than once.\n", schedule.Name))
+		}
+		names = append(names, schedule.Name)
+		if !strings.HasPrefix(schedule.Schedule, "at(") && !strings.HasPrefix(schedule.Schedule, "rate(") && !strings.HasPrefix(schedule.Schedule, "cron(") {
+			errorMessage.WriteString(fmt.Sprintf("Autoscaling schedule %s must be an at, rate or cron expression.\n", schedule.Name))
+		}
+		if schedule.Min == nil && schedule.Max == nil {
+			errorMessage.WriteString(fmt.Sprintf("Autoscaling schedule %s must set min, max or both.\n", schedule.Name))
+		}
+		if schedule.Min != nil && schedule.Max != nil && *schedule.Max < *schedule.Min {
+			errorMessage.WriteString(fmt.Sprintf("Autoscaling schedule %s has a max less than its min.\n", schedule.Name))
+		}
+	}
+	if len(errorMessage.String()) > 0 {
+		return &common.InputError{
+			Message: errorMessage.String(),
+		}
+	}
+	return nil
+}
+
+// registeredTargets returns the resource ids of the scalable targets of the function
+func (wrapper ServiceWrapper) registeredTargets(ctx context.Context, functionName string) ([]string, error) {
+	var resourceIds []string
+	describeInput := &applicationautoscaling.DescribeScalableTargetsInput{
+		ServiceNamespace:  namespace,
+		ScalableDimension: dimension,
+	}
+	for {
+		output, err := wrapper.Client.DescribeScalableTargets(ctx, describeInput)
+		if err != nil {
+			return nil, err
+		}
+		for _, target := range output.ScalableTargets {
+			if strings.HasPrefix(aws.ToString(target.ResourceId), ResourceId(functionName, "")) {
+				resourceIds = append(resourceIds, aws.ToString(target.ResourceId))
+			}
+		}
+		if output.NextToken == nil {
+			return resourceIds, nil
+		}
+		describeInput.NextToken = output.NextToken
+	}
+}
+
+func (wrapper ServiceWrapper) deregister(ctx context.Context, resourceId string) error {
+	log.Println("Removing autoscaling of", resourceId)
+	// Deregistering the target deletes its scaling policies and scheduled actions as well
+	_, err := wrapper.Client.DeregisterScalableTarget(ctx, &applicationautoscaling.DeregisterScalableTargetInput{
+		ServiceNamespace:  namespace,
+		ScalableDimension: dimension,
+		ResourceId:        aws.String(resourceId),
+	})
+	if err != nil {
+		log.Printf("Not able to remove autoscaling of %s. The reason is %s", resourceId, err.Error())
+	}
+	return err
+}
+
+// Reconcile registers the provisioned concurrency of the function as a scalable target with a target
+// tracking policy and the scheduled actions. Targets of other qualifiers, or all of them without
+// autoscaling, are deregistered.
+func (wrapper ServiceWrapper) Reconcile(ctx context.Context, functionName string, provisioned *common.ProvisionedConcurrencyConfig) error {
+	existing, err := wrapper.registeredTargets(ctx, functionName)
+	if err != nil {
+		log.Printf("Not able to list the scalable targets. The reason is %s", err.Error())
+		return err
+	}
+	declared := ""
+	if provisioned != nil && provisioned.Autoscaling != nil {
+		declared = ResourceId(functionName, provisioned.Qualifier)
+	}
+	for _, resourceId := range existing {
+		if resourceId == declared {
+			continue
+		}
+		if err = wrapper.deregister(ctx, resourceId); err != nil {
+			return err
+		}
+	}
+	if declared == "" {
+		return nil
+	}
+	autoscaling := provisioned.Autoscaling
+	log.Printf("Scaling provisioned concurrency of %s between %d and %d\n", declared, autoscaling.Min, autoscaling.Max)
+	_, err = wrapper.Client.RegisterScalableTarget(ctx, &applicationautoscaling.RegisterScalableTargetInput{
+		ServiceNamespace:  namespace,
+		ScalableDimension: dimension,
+		ResourceId:        aws.String(declared),
+		MinCapacity:       aws.Int32(int32(autoscaling.Min)),
+		MaxCapacity:       aws.Int32(int32(autoscaling.Max)),
+	})
+	if err != nil {
+		log.Printf("Not able to register the scalable target %s. The reason is %s", declared, err.Error())
+		return err
+	}
+	_, err = wrapper.Client.PutScalingPolicy(ctx, &applicationautoscaling.PutScalingPolicyInput{
+		PolicyName:        aws.String(policyName(functionName, "utilization")),
+		ServiceNamespace:  namespace,
+		ScalableDimension: dimension,
+		ResourceId:        aws.String(declared),
+		PolicyType:        types.PolicyTypeTargetTrackingScaling,
+		TargetTrackingScalingPolicyConfiguration: &types.TargetTrackingScalingPolicyConfiguration{
+			TargetValue: aws.Float64(autoscaling.TargetUtilization),
+			PredefinedMetricSpecification: &types.PredefinedMetricSpecification{
+				PredefinedMetricType: types.MetricTypeLambdaProvisionedConcurrencyUtilization,
+			},
+		},
+	})
+	if err != nil {
+		log.Printf("Not able to put the scaling policy of %s. The reason is %s", declared, err.Error())
+		return err
+	}
+	return wrapper.reconcileScheduledActions(ctx, functionName, declared, autoscaling.Schedules)
+}
+
+func (wrapper ServiceWrapper) reconcileScheduledActions(ctx context.Context, functionName string, resourceId string, schedules []common.AutoscalingSchedule) error {
+	var declared []string
+	for _, schedule := range schedules {
+		actionName := policyName(functionName, schedule.Name)
+		declared = append(declared, actionName)
+		action := &types.ScalableTargetAction{}
+		if schedule.Min != nil {
+			action.MinCapacity = aws.Int32(int32(*schedule.Min))
+		}
+		if schedule.Max != nil {
+			action.MaxCapacity = aws.Int32(int32(*schedule.Max))
+		}
+		actionInput := &applicationautoscaling.PutScheduledActionInput{
+			ScheduledActionName:  aws.String(actionName),
+			ServiceNamespace:     namespace,
+			ScalableDimension:    dimension,
+			ResourceId:           aws.String(resourceId),
+			Schedule:             aws.String(schedule.Schedule),
+			ScalableTargetAction: action,
+		}
+		if schedule.Timezone != "" {
+			actionInput.Timezone = aws.String(schedule.Timezone)
+		}
+		log.Printf("Updating scheduled action %s with %s\n", actionName, schedule.Schedule)
+		if _, err := wrapper.Client.PutScheduledAction(ctx, actionInput); err != nil {
+			log.Printf("Not able to put scheduled action %s. The reason is %s", actionName, err.Error())
+			return err
+		}
+	}
+	describeInput := &applicationautoscaling.DescribeScheduledActionsInput{
+		ServiceNamespace:  namespace,
+		ScalableDimension: dimension,
+		ResourceId:        aws.String(resourceId),
+	}
+	for {
+		output, err := wrapper.Client.DescribeScheduledActions(ctx, describeInput)
+		if err != nil {
+			return err
+		}
+		for _, action := range output.ScheduledActions {
+			actionName := aws.ToString(action.ScheduledActionName)
+			if slices.Contains(declared, actionName) {
+				continue
+			}
+			log.Println("Deleting scheduled action", actionName)
+			_, err = wrapper.Client.DeleteScheduledAction(ctx, &applicationautoscaling.DeleteScheduledActionInput{
+				ScheduledActionName: action.ScheduledActionName,
+				ServiceNamespace:    namespace,
+				ScalableDimension:   dimension,
+				ResourceId:          aws.String(resourceId),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if output.NextToken == nil {
+			return nil
+		}
+		describeInput.NextToken = output.NextToken
+	}
+}
+
+// Delete deregisters every scalable target of the function
+func (wrapper ServiceWrapper) Delete(ctx context.Context, functionName string) error {
+	return wrapper.Reconcile(ctx, functionName, nil)
+}
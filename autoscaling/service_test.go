@@ -0,0 +1,154 @@
+package autoscaling
+
+import (
+	"context"
+	"testing"
+
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/applicationautoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/applicationautoscaling/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockAutoscalingApi struct {
+	targets  map[string]*applicationautoscaling.RegisterScalableTargetInput
+	policies map[string]*applicationautoscaling.PutScalingPolicyInput
+	actions  map[string]*applicationautoscaling.PutScheduledActionInput
+}
+
+func newMock() *mockAutoscalingApi {
+	return &mockAutoscalingApi{
+		targets:  map[string]*applicationautoscaling.RegisterScalableTargetInput{},
+		policies: map[string]*applicationautoscaling.PutScalingPolicyInput{},
+		actions:  map[string]*applicationautoscaling.PutScheduledActionInput{},
+	}
+}
+
+func (m *mockAutoscalingApi) RegisterScalableTarget(ctx context.Context, params *applicationautoscaling.RegisterScalableTargetInput, optFns ...func(*applicationautoscaling.Options)) (*applicationautoscaling.RegisterScalableTargetOutput, error) {
+	m.targets[*params.ResourceId] = params
+	return &applicationautoscaling.RegisterScalableTargetOutput{}, nil
+}
+
+func (m *mockAutoscalingApi) DescribeScalableTargets(ctx context.Context, params *applicationautoscaling.DescribeScalableTargetsInput, optFns ...func(*applicationautoscaling.Options)) (*applicationautoscaling.DescribeScalableTargetsOutput, error) {
+	output := &applicationautoscaling.DescribeScalableTargetsOutput{}
+	for resourceId := range m.targets {
+		output.ScalableTargets = append(output.ScalableTargets, types.ScalableTarget{ResourceId: aws.String(resourceId)})
+	}
+	return output, nil
+}
+
+func (m *mockAutoscalingApi) DeregisterScalableTarget(ctx context.Context, params *applicationautoscaling.DeregisterScalableTargetInput, optFns ...func(*applicationautoscaling.Options)) (*applicationautoscaling.DeregisterScalableTargetOutput, error) {
+	delete(m.targets, *params.ResourceId)
+	for name, action := range m.actions {
+		if *action.ResourceId == *params.ResourceId {
+			delete(m.actions, name)
+		}
+	}
+	return &applicationautoscaling.DeregisterScalableTargetOutput{}, nil
+}
+
+func (m *mockAutoscalingApi) PutScalingPolicy(ctx context.Context, params *applicationautoscaling.PutScalingPolicyInput, optFns ...func(*applicationautoscaling.Options)) (*applicationautoscaling.PutScalingPolicyOutput, error) {
+	m.policies[*params.PolicyName] = params
+	return &applicationautoscaling.PutScalingPolicyOutput{}, nil
+}
+
+func (m *mockAutoscalingApi) PutScheduledAction(ctx context.Context, params *applicationautoscaling.PutScheduledActionInput, optFns ...func(*applicationautoscaling.Options)) (*applicationautoscaling.PutScheduledActionOutput, error) {
+	m.actions[*params.ScheduledActionName] = params
+	return &applicationautoscaling.PutScheduledActionOutput{}, nil
+}
+
+func (m *mockAutoscalingApi) DescribeScheduledActions(ctx context.Context, params *applicationautoscaling.DescribeScheduledActionsInput, optFns ...func(*applicationautoscaling.Options)) (*applicationautoscaling.DescribeScheduledActionsOutput, error) {
+	output := &applicationautoscaling.DescribeScheduledActionsOutput{}
+	for name, action := range m.actions {
+		if *action.ResourceId == *params.ResourceId {
+			output.ScheduledActions = append(output.ScheduledActions, types.ScheduledAction{ScheduledActionName: aws.String(name)})
+		}
+	}
+	return output, nil
+}
+
+func (m *mockAutoscalingApi) DeleteScheduledAction(ctx context.Context, params *applicationautoscaling.DeleteScheduledActionInput, optFns ...func(*applicationautoscaling.Options)) (*applicationautoscaling.DeleteScheduledActionOutput, error) {
+	delete(m.actions, *params.ScheduledActionName)
+	return &applicationautoscaling.DeleteScheduledActionOutput{}, nil
+}
+
+func peakHours() *common.ProvisionedConcurrencyConfig {
+	return &common.ProvisionedConcurrencyConfig{
+		Qualifier: "live",
+		Count:     2,
+		Autoscaling: &common.AutoscalingConfig{
+			Min:               2,
+			Max:               20,
+			TargetUtilization: 0.7,
+			Schedules: []common.AutoscalingSchedule{
+				{Name: "morning", Schedule: "cron(0 8 ? * MON-FRI *)", Timezone: "Europe/Berlin", Min: aws.Int(10)},
+				{Name: "evening", Schedule: "cron(0 19 ? * MON-FRI *)", Timezone: "Europe/Berlin", Min: aws.Int(2)},
+			},
+		},
+	}
+}
+
+func TestValidateAutoscaling(t *testing.T) {
+	assert.NoError(t, ValidateAutoscaling(nil))
+	assert.NoError(t, ValidateAutoscaling(&common.ProvisionedConcurrencyConfig{Qualifier: "live", Count: 2}))
+	assert.NoError(t, ValidateAutoscaling(peakHours()))
+
+	provisioned := peakHours()
+	provisioned.Autoscaling.TargetUtilization = 70
+	assert.Error(t, ValidateAutoscaling(provisioned))
+
+	provisioned = peakHours()
+	provisioned.Count = 30
+	assert.Error(t, ValidateAutoscaling(provisioned))
+
+	provisioned = peakHours()
+	provisioned.Autoscaling.Schedules[1].Name = "morning"
+	assert.Error(t, ValidateAutoscaling(provisioned))
+
+	provisioned = peakHours()
+	provisioned.Autoscaling.Schedules[0].Schedule = "0 8 * * *"
+	assert.Error(t, ValidateAutoscaling(provisioned))
+}
+
+func TestReconcile(t *testing.T) {
+	mock := newMock()
+	mock.targets[ResourceId("orders", "blue")] = &applicationautoscaling.RegisterScalableTargetInput{}
+	mock.targets[ResourceId("users", "live")] = &applicationautoscaling.RegisterScalableTargetInput{}
+	wrapper := ServiceWrapper{Client: mock}
+
+	assert.NoError(t, wrapper.Reconcile(context.Background(), "orders", peakHours()))
+	assert.NotContains(t, mock.targets, ResourceId("orders", "blue"))
+	assert.Contains(t, mock.targets, ResourceId("users", "live"))
+	target := mock.targets[ResourceId("orders", "live")]
+	assert.Equal(t, int32(2), *target.MinCapacity)
+	assert.Equal(t, int32(20), *target.MaxCapacity)
+	policy := mock.policies["orders-utilization"]
+	assert.Equal(t, 0.7, *policy.TargetTrackingScalingPolicyConfiguration.TargetValue)
+	assert.Equal(t, types.MetricTypeLambdaProvisionedConcurrencyUtilization, policy.TargetTrackingScalingPolicyConfiguration.PredefinedMetricSpecification.PredefinedMetricType)
+	assert.Equal(t, int32(10), *mock.actions["orders-morning"].ScalableTargetAction.MinCapacity)
+	assert.Nil(t, mock.actions["orders-morning"].ScalableTargetAction.MaxCapacity)
+	assert.Equal(t, "Europe/Berlin", *mock.actions["orders-morning"].Timezone)
+
+	provisioned := peakHours()
+	provisioned.Autoscaling.Schedules = provisioned.Autoscaling.Schedules[:1]
+	assert.NoError(t, wrapper.Reconcile(context.Background(), "orders", provisioned))
+	assert.Contains(t, mock.actions, "orders-morning")
+	assert.NotContains(t, mock.actions, "orders-evening")
+
+	// Without autoscaling the registration is removed
+	provisioned.Autoscaling = nil
+	assert.NoError(t, wrapper.Reconcile(context.Background(), "orders", provisioned))
+	assert.NotContains(t, mock.targets, ResourceId("orders", "live"))
+	assert.Empty(t, mock.actions)
+	assert.Contains(t, mock.targets, ResourceId("users", "live"))
+}
+
+func TestDelete(t *testing.T) {
+	mock := newMock()
+	wrapper := ServiceWrapper{Client: mock}
+	assert.NoError(t, wrapper.Reconcile(context.Background(), "orders", peakHours()))
+	assert.NoError(t, wrapper.Delete(context.Background(), "orders"))
+	assert.Empty(t, mock.targets)
+	assert.Empty(t, mock.actions)
+}
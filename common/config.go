@@ -0,0 +1,21 @@
+package common
+
+import (
+	"gopkg.in/yaml.v3"
+	"os"
+)
+
+func ReadConfigFile(fileName string) (*ConfigFile, error) {
+	configFile := ConfigFile{}
+	if TrimAndCheckEmptyString(&fileName) {
+		return &configFile, nil
+	}
+	contents, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(contents, &configFile); err != nil {
+		return nil, err
+	}
+	return &configFile, nil
+}
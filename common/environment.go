@@ -0,0 +1,191 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Values of env_merge. replace makes the declared variables the whole environment, merge keeps
+// variables set on the function by others and overrides only the declared ones.
+const (
+	EnvMergeReplace = "replace"
+	EnvMergeMerge   = "merge"
+)
+
+// Values of secret_scan, what happens when a variable looks like a secret
+const (
+	SecretScanWarn = "warn"
+	SecretScanFail = "fail"
+	SecretScanOff  = "off"
+)
+
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// EnvironmentMap holds environment variables written as a YAML map or, as before, as a JSON object in a string
+type EnvironmentMap map[string]string
+
+func (environment *EnvironmentMap) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		var text string
+		if err := node.Decode(&text); err != nil {
+			return err
+		}
+		if strings.TrimSpace(text) == "" {
+			return nil
+		}
+		variables := map[string]string{}
+		if err := json.Unmarshal([]byte(text), &variables); err != nil {
+			return fmt.Errorf("environment_variables must be a map or a JSON object: %w", err)
+		}
+		*environment = variables
+		return nil
+	}
+	variables := map[string]string{}
+	if err := node.Decode(&variables); err != nil {
+		return err
+	}
+	*environment = variables
+	return nil
+}
+
+// Environment combines the sources of environment variables. Later sources override earlier ones:
+// the env file, then the environment_variables of the config file, then the JSON of the flag.
+// It is nil when there is no source, which leaves the environment of the function untouched.
+func Environment(envFile string, declared EnvironmentMap, flagValue string) (map[string]string, error) {
+	var variables map[string]string
+	merge := func(source map[string]string) {
+		if source == nil {
+			return
+		}
+		if variables == nil {
+			variables = map[string]string{}
+		}
+		for key, value := range source {
+			variables[key] = value
+		}
+	}
+	if !TrimAndCheckEmptyString(&envFile) {
+		fileVariables, err := ReadEnvFile(envFile)
+		if err != nil {
+			return nil, err
+		}
+		merge(fileVariables)
+	}
+	merge(declared)
+	if !TrimAndCheckEmptyString(&flagValue) {
+		flagVariables := map[string]string{}
+		if err := json.Unmarshal([]byte(flagValue), &flagVariables); err != nil {
+			return nil, err
+		}
+		merge(flagVariables)
+	}
+	return variables, nil
+}
+
+func ReadEnvFile(fileName string) (map[string]string, error) {
+	contents, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	variables, err := ParseDotenv(string(contents))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+	return variables, nil
+}
+
+// ParseDotenv reads KEY=value lines. Lines may start with export, # starts a comment outside of quotes.
+// Single quoted values are taken as they are, double quoted ones understand \n, \r, \t, \" and \\.
+// Quoted values may span lines. Variables are not expanded.
+func ParseDotenv(contents string) (map[string]string, error) {
+	variables := map[string]string{}
+	contents = strings.ReplaceAll(contents, "\r\n", "\n")
+	line := 1
+	for position := 0; position < len(contents); {
+		end := strings.IndexByte(contents[position:], '\n')
+		if end < 0 {
+			end = len(contents)
+		} else {
+			end += position
+		}
+		text := strings.TrimSpace(contents[position:end])
+		if text == "" || strings.HasPrefix(text, "#") {
+			position = end + 1
+			line++
+			continue
+		}
+		key, rest, found := strings.Cut(text, "=")
+		key = strings.TrimSpace(strings.TrimPrefix(key, "export "))
+		if !found || !envKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("line %d is not a KEY=value assignment", line)
+		}
+		rest = strings.TrimLeft(rest, " \t")
+		if rest == "" || (rest[0] != '"' && rest[0] != '\'') {
+			// Unquoted values end at the line, an inline comment needs a space before #
+			if comment := strings.Index(rest, " #"); comment >= 0 {
+				rest = rest[:comment]
+			}
+			variables[key] = strings.TrimSpace(rest)
+			position = end + 1
+			line++
+			continue
+		}
+		// Quoted values may continue on the following lines
+		valueStart := strings.Index(contents[position:end], rest) + position
+		value, consumed, err := parseQuoted(contents[valueStart:])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		variables[key] = value
+		line += strings.Count(contents[position:valueStart+consumed], "\n")
+		position = valueStart + consumed
+		// Skip to the end of the line, allowing only a comment after the closing quote
+		end = strings.IndexByte(contents[position:], '\n')
+		if end < 0 {
+			end = len(contents)
+		} else {
+			end += position
+		}
+		if trailing := strings.TrimSpace(contents[position:end]); trailing != "" && !strings.HasPrefix(trailing, "#") {
+			return nil, fmt.Errorf("line %d has text after the closing quote", line)
+		}
+		position = end + 1
+		line++
+	}
+	return variables, nil
+}
+
+// parseQuoted reads the quoted value at the start of text and returns it with the number of bytes read
+func parseQuoted(text string) (string, int, error) {
+	quote := text[0]
+	var value strings.Builder
+	for index := 1; index < len(text); index++ {
+		character := text[index]
+		if character == quote {
+			return value.String(), index + 1, nil
+		}
+		if character == '\\' && quote == '"' && index+1 < len(text) {
+			index++
+			switch text[index] {
+			case 'n':
+				value.WriteByte('\n')
+			case 'r':
+				value.WriteByte('\r')
+			case 't':
+				value.WriteByte('\t')
+			case '"', '\\', '$':
+				value.WriteByte(text[index])
+			default:
+				value.WriteByte('\\')
+				value.WriteByte(text[index])
+			}
+			continue
+		}
+		value.WriteByte(character)
+	}
+	return "", 0, fmt.Errorf("value has no closing %c", quote)
+}
@@ -0,0 +1,78 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseDotenv(t *testing.T) {
+	variables, err := ParseDotenv(`# database
+DB_HOST=localhost
+export DB_PORT = 5432
+EMPTY=
+URL=http://host/#anchor # comment
+SINGLE='no \n escapes # here'
+DOUBLE="tab\there \"quoted\" \$HOME"
+MULTILINE="-----BEGIN KEY-----
+line two
+-----END KEY-----" # trailing comment
+
+LAST='multi
+line'`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"DB_HOST":   "localhost",
+		"DB_PORT":   "5432",
+		"EMPTY":     "",
+		"URL":       "http://host/#anchor",
+		"SINGLE":    `no \n escapes # here`,
+		"DOUBLE":    "tab\there \"quoted\" $HOME",
+		"MULTILINE": "-----BEGIN KEY-----\nline two\n-----END KEY-----",
+		"LAST":      "multi\nline",
+	}, variables)
+
+	_, err = ParseDotenv("A=1\nB=\"open\nC=3\n")
+	assert.EqualError(t, err, "line 2: value has no closing \"")
+
+	_, err = ParseDotenv("A=1\nB=\"x\ny\" z\n")
+	assert.EqualError(t, err, "line 3 has text after the closing quote")
+
+	_, err = ParseDotenv("1A=1")
+	assert.Error(t, err)
+}
+
+func TestEnvironmentMap(t *testing.T) {
+	var config ConfigFile
+	assert.NoError(t, yaml.Unmarshal([]byte("environment_variables:\n  STAGE: prod\n  PORT: 8080\n"), &config))
+	assert.Equal(t, EnvironmentMap{"STAGE": "prod", "PORT": "8080"}, config.EnvironmentVariables)
+
+	config = ConfigFile{}
+	assert.NoError(t, yaml.Unmarshal([]byte("environment_variables: |\n  {\"STAGE\": \"prod\"}\n"), &config))
+	assert.Equal(t, EnvironmentMap{"STAGE": "prod"}, config.EnvironmentVariables)
+
+	config = ConfigFile{}
+	assert.NoError(t, yaml.Unmarshal([]byte("name: orders\n"), &config))
+	assert.Nil(t, config.EnvironmentVariables)
+
+	assert.Error(t, yaml.Unmarshal([]byte("environment_variables: not json\n"), &config))
+}
+
+func TestEnvironment(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), ".env")
+	assert.NoError(t, os.WriteFile(envFile, []byte("STAGE=dev\nLOG_LEVEL=debug\nREGION=eu-west-1\n"), 0600))
+
+	variables, err := Environment(envFile, EnvironmentMap{"STAGE": "prod", "LOG_LEVEL": "info"}, `{"LOG_LEVEL":"warn"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"STAGE": "prod", "LOG_LEVEL": "warn", "REGION": "eu-west-1"}, variables)
+
+	variables, err = Environment("", nil, "")
+	assert.NoError(t, err)
+	assert.Nil(t, variables)
+
+	_, err = Environment(filepath.Join(t.TempDir(), "missing.env"), nil, "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,94 @@
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Tags the tool owns. They are set on every deploy and cannot be declared in the config.
+const (
+	TagManagedBy  = "managed-by"
+	TagDeployedAt = "deployed-at"
+	TagGitSha     = "git-sha"
+	TagConfigHash = "config-hash"
+)
+
+const ManagedByValue = "lambda-deploy"
+
+var ToolTagKeys = []string{TagManagedBy, TagDeployedAt, TagGitSha, TagConfigHash}
+
+// Variables CI systems set to the commit being built, checked before asking git
+var gitShaVariables = []string{"GITHUB_SHA", "CI_COMMIT_SHA", "BITBUCKET_COMMIT", "GIT_COMMIT"}
+
+// ToolTags returns the tags this tool puts on the function and the role it creates. git-sha and
+// config-hash are left out when there is no git checkout or config file.
+func ToolTags(configFile string, now time.Time) map[string]string {
+	tags := map[string]string{
+		TagManagedBy:  ManagedByValue,
+		TagDeployedAt: now.UTC().Format(time.RFC3339),
+	}
+	if sha := gitSha(); sha != "" {
+		tags[TagGitSha] = sha
+	}
+	if !TrimAndCheckEmptyString(&configFile) {
+		if contents, err := os.ReadFile(configFile); err == nil {
+			sum := sha256.Sum256(contents)
+			tags[TagConfigHash] = hex.EncodeToString(sum[:8])
+		}
+	}
+	return tags
+}
+
+func gitSha() string {
+	for _, name := range gitShaVariables {
+		if sha := strings.TrimSpace(os.Getenv(name)); sha != "" {
+			return sha
+		}
+	}
+	output, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// ResourceTags returns the declared tags along with the tool tags
+func (p DeployParams) ResourceTags() map[string]string {
+	tags := map[string]string{}
+	for key, value := range p.Tags {
+		tags[key] = value
+	}
+	for key, value := range p.ToolTags {
+		tags[key] = value
+	}
+	return tags
+}
+
+// TagChanges returns the tags to set and the keys to remove to get from the current tags of a
+// resource to the desired ones. Keys are removed when tags are declared or when the resource was
+// tagged by this tool before, so removing the tags block clears the declared tags. aws: tags are
+// never removed.
+func (p DeployParams) TagChanges(current map[string]string) (map[string]string, []string) {
+	desired := p.ResourceTags()
+	added := map[string]string{}
+	for key, value := range desired {
+		if currentValue, found := current[key]; !found || currentValue != value {
+			added[key] = value
+		}
+	}
+	var removed []string
+	if p.Tags != nil || current[TagManagedBy] == ManagedByValue {
+		for key := range current {
+			if _, found := desired[key]; !found && !strings.HasPrefix(key, "aws:") {
+				removed = append(removed, key)
+			}
+		}
+	}
+	sort.Strings(removed)
+	return added, removed
+}
@@ -1,12 +1,49 @@
 package common
 
+import "strings"
+
 type DeployParams struct {
 	FunctionName                string
 	BucketName                  string
 	KeyName                     string
 	Region                      string
 	ZipFile                     string
+	ImageUri                    string
+	PackageType                 string
+	ImageConfig                 *ImageConfig
+	Architectures               []string
+	ArchitectureRebuilt         bool
+	Layers                      []string
+	Vpc                         *VpcConfig
+	DeadLetterTargetArn         string
+	Async                       *AsyncConfig
+	EventSources                []EventSource
+	Permissions                 []Permission
+	FunctionUrl                 *FunctionUrlConfig
+	Schedules                   []Schedule
+	HttpApi                     *HttpApiConfig
+	S3Triggers                  []S3Trigger
+	SnsTriggers                 []SnsTrigger
+	SmokeTests                  []SmokeTest
+	ReservedConcurrency         *int
+	ProvisionedConcurrency      *ProvisionedConcurrencyConfig
+	Tags                        map[string]string
+	ToolTags                    map[string]string
+	SmokeTestReport             string
+	EventsDirectory             string
 	EnvironmentVariables        map[string]string
+	EnvMerge                    string
+	KmsKeyArn                   string
+	EncryptedVariables          map[string]string
+	SecretScan                  string
+	Tracing                     string
+	Logging                     *LoggingConfig
+	LogRetentionDays            int
+	LogKmsKeyArn                string
+	EphemeralStorage            int
+	FileSystemConfigs           []FileSystemConfig
+	SnapStart                   string
+	RuntimeManagement           *RuntimeManagementConfig
 	Memory                      int
 	Timeout                     int
 	Policy                      string
@@ -16,3 +53,223 @@ type DeployParams struct {
 	AutogenerateExecutionPolicy bool
 	RoleArn                     string
 }
+
+// ImageConfig overrides the values baked into the container image of an Image package function.
+type ImageConfig struct {
+	EntryPoint       []string `yaml:"entry_point"`
+	Command          []string `yaml:"command"`
+	WorkingDirectory string   `yaml:"working_directory"`
+}
+
+// VpcConfig attaches the function to a VPC. An explicit empty config detaches it.
+type VpcConfig struct {
+	SubnetIds               []string `yaml:"subnet_ids"`
+	SecurityGroupIds        []string `yaml:"security_group_ids"`
+	Ipv6AllowedForDualStack bool     `yaml:"ipv6_allowed_for_dual_stack"`
+}
+
+// Attached reports whether the config places the function in a VPC.
+func (v *VpcConfig) Attached() bool {
+	return v != nil && len(v.SubnetIds) > 0
+}
+
+// AsyncConfig controls retries and destinations of asynchronous invocations.
+type AsyncConfig struct {
+	MaximumRetryAttempts   *int   `yaml:"maximum_retry_attempts"`
+	MaximumEventAgeSeconds *int   `yaml:"maximum_event_age_seconds"`
+	OnSuccess              string `yaml:"on_success"`
+	OnFailure              string `yaml:"on_failure"`
+}
+
+// EventSource maps an SQS queue, Kinesis stream, DynamoDB stream or MSK cluster to the function.
+// FilterCriteria holds the filter patterns as JSON strings.
+type EventSource struct {
+	Arn                          string   `yaml:"arn"`
+	BatchSize                    *int     `yaml:"batch_size"`
+	MaximumBatchingWindowSeconds *int     `yaml:"maximum_batching_window_seconds"`
+	FilterCriteria               []string `yaml:"filter_criteria"`
+	ReportBatchItemFailures      bool     `yaml:"report_batch_item_failures"`
+	MaximumConcurrency           *int     `yaml:"maximum_concurrency"`
+	StartingPosition             string   `yaml:"starting_position"`
+	Topics                       []string `yaml:"topics"`
+}
+
+// Permission is a statement of the function's resource based policy allowing a principal to invoke it.
+// Action defaults to lambda:InvokeFunction, or lambda:InvokeFunctionUrl when FunctionUrlAuthType is set.
+type Permission struct {
+	Principal           string `yaml:"principal"`
+	Action              string `yaml:"action"`
+	SourceArn           string `yaml:"source_arn"`
+	SourceAccount       string `yaml:"source_account"`
+	Qualifier           string `yaml:"qualifier"`
+	FunctionUrlAuthType string `yaml:"function_url_auth_type"`
+}
+
+// FunctionUrlConfig configures the HTTPS endpoint of the function. An empty config deletes the URL.
+// AllowPublic acknowledges that the NONE auth type makes the URL public.
+type FunctionUrlConfig struct {
+	AuthType    string      `yaml:"auth_type"`
+	Cors        *CorsConfig `yaml:"cors"`
+	InvokeMode  string      `yaml:"invoke_mode"`
+	Qualifier   string      `yaml:"qualifier"`
+	AllowPublic bool        `yaml:"allow_public"`
+}
+
+// Deleted reports whether the config asks for the URL to be removed.
+func (f *FunctionUrlConfig) Deleted() bool {
+	return f != nil && strings.TrimSpace(f.AuthType) == ""
+}
+
+type CorsConfig struct {
+	AllowCredentials bool     `yaml:"allow_credentials"`
+	AllowHeaders     []string `yaml:"allow_headers"`
+	AllowMethods     []string `yaml:"allow_methods"`
+	AllowOrigins     []string `yaml:"allow_origins"`
+	ExposeHeaders    []string `yaml:"expose_headers"`
+	MaxAge           *int     `yaml:"max_age"`
+}
+
+// Schedule invokes the function from an EventBridge rule with a cron or rate expression.
+// Input is sent as the JSON payload and Enabled defaults to true.
+type Schedule struct {
+	Name       string `yaml:"name"`
+	Expression string `yaml:"expression"`
+	Input      any    `yaml:"input"`
+	Enabled    *bool  `yaml:"enabled"`
+}
+
+// HttpApiConfig puts an API Gateway HTTP API in front of the function. Stage defaults to $default
+// and an empty config, without routes, deletes the API.
+type HttpApiConfig struct {
+	Routes         []string `yaml:"routes"`
+	Stage          string   `yaml:"stage"`
+	DomainName     string   `yaml:"domain_name"`
+	CertificateArn string   `yaml:"certificate_arn"`
+	BasePath       string   `yaml:"base_path"`
+}
+
+// Deleted reports whether the config asks for the API to be removed.
+func (h *HttpApiConfig) Deleted() bool {
+	return h != nil && len(h.Routes) == 0
+}
+
+// S3Trigger invokes the function for events of objects in the bucket matching the prefix and suffix.
+type S3Trigger struct {
+	Bucket string   `yaml:"bucket"`
+	Events []string `yaml:"events"`
+	Prefix string   `yaml:"prefix"`
+	Suffix string   `yaml:"suffix"`
+}
+
+// SnsTrigger subscribes the function to the topic. FilterPolicy is either a map or a JSON string.
+type SnsTrigger struct {
+	TopicArn     string `yaml:"topic_arn"`
+	FilterPolicy any    `yaml:"filter_policy"`
+}
+
+// SmokeTest invokes the function after deploy and checks the response. The payload is given inline, as a
+// file or as the name of an event in the event library. StatusCode defaults to 200.
+// Equals compares the value at JsonPath, Matches is a regular expression checked against the value at
+// JsonPath or the whole response when no path is given. Qualifier can only be $LATEST, as the tool
+// does not publish versions or move aliases.
+type SmokeTest struct {
+	Name               string `yaml:"name"`
+	Payload            any    `yaml:"payload"`
+	PayloadFile        string `yaml:"payload_file"`
+	Event              string `yaml:"event"`
+	Qualifier          string `yaml:"qualifier"`
+	StatusCode         int    `yaml:"status_code"`
+	AllowFunctionError bool   `yaml:"allow_function_error"`
+	JsonPath           string `yaml:"json_path"`
+	Equals             any    `yaml:"equals"`
+	Matches            string `yaml:"matches"`
+	MaxLatencyMs       int    `yaml:"max_latency_ms"`
+}
+
+// ProvisionedConcurrencyConfig keeps Count execution environments of the version or alias initialized.
+// With Autoscaling, Count is only the initial value and Application Auto Scaling adjusts it afterwards.
+type ProvisionedConcurrencyConfig struct {
+	Qualifier   string             `yaml:"qualifier"`
+	Count       int                `yaml:"count"`
+	Autoscaling *AutoscalingConfig `yaml:"autoscaling"`
+}
+
+// AutoscalingConfig scales the provisioned concurrency between Min and Max to keep its utilization
+// at TargetUtilization, a fraction between 0.1 and 0.9. Schedules change Min and Max at given times.
+type AutoscalingConfig struct {
+	Min               int                   `yaml:"min"`
+	Max               int                   `yaml:"max"`
+	TargetUtilization float64               `yaml:"target_utilization"`
+	Schedules         []AutoscalingSchedule `yaml:"schedules"`
+}
+
+// AutoscalingSchedule is a scheduled action. Schedule is an at, rate or cron expression evaluated in Timezone (UTC by default).
+type AutoscalingSchedule struct {
+	Name     string `yaml:"name"`
+	Schedule string `yaml:"schedule"`
+	Timezone string `yaml:"timezone"`
+	Min      *int   `yaml:"min"`
+	Max      *int   `yaml:"max"`
+}
+
+// LoggingConfig controls the log format and levels of the function and the log group it writes to.
+// The levels only apply to the JSON format. LogGroup defaults to /aws/lambda/<function name>.
+type LoggingConfig struct {
+	Format              string `yaml:"format"`
+	ApplicationLogLevel string `yaml:"application_log_level"`
+	SystemLogLevel      string `yaml:"system_log_level"`
+	LogGroup            string `yaml:"log_group"`
+}
+
+// FileSystemConfig mounts an EFS access point at LocalMountPath, which has to be below /mnt.
+// The function has to be in a VPC with a route to the mount targets of the file system.
+type FileSystemConfig struct {
+	Arn            string `yaml:"arn"`
+	LocalMountPath string `yaml:"local_mount_path"`
+}
+
+// RuntimeManagementConfig decides when the runtime of the function is updated. RuntimeVersionArn
+// pins the runtime version and is only used with the Manual mode.
+type RuntimeManagementConfig struct {
+	UpdateRuntimeOn   string `yaml:"update_runtime_on"`
+	RuntimeVersionArn string `yaml:"runtime_version_arn"`
+}
+
+// ConfigFile holds the nested blocks of the yaml config file which cannot be expressed as flags.
+type ConfigFile struct {
+	ImageConfig  *ImageConfig       `yaml:"image_config"`
+	Vpc          *VpcConfig         `yaml:"vpc"`
+	Async        *AsyncConfig       `yaml:"async"`
+	EventSources []EventSource      `yaml:"event_sources"`
+	Permissions  []Permission       `yaml:"permissions"`
+	FunctionUrl  *FunctionUrlConfig `yaml:"function_url"`
+	Schedules    []Schedule         `yaml:"schedules"`
+	HttpApi      *HttpApiConfig     `yaml:"http_api"`
+	S3Triggers   []S3Trigger        `yaml:"s3_triggers"`
+	SnsTriggers  []SnsTrigger       `yaml:"sns_triggers"`
+	SmokeTests   []SmokeTest        `yaml:"smoke_tests"`
+	// A pointer, as 0 throttles the function while leaving it out removes the reservation
+	ReservedConcurrency    *int                          `yaml:"reserved_concurrency"`
+	ProvisionedConcurrency *ProvisionedConcurrencyConfig `yaml:"provisioned_concurrency"`
+	// Without tags (nil) tags added outside of the tool are kept until the tool has tagged the resource
+	Tags                          map[string]string `yaml:"tags"`
+	EnvironmentVariables          EnvironmentMap    `yaml:"environment_variables"`
+	EncryptedEnvironmentVariables map[string]string `yaml:"encrypted_environment_variables"`
+	Logging                       *LoggingConfig    `yaml:"logging"`
+	// Without file_system_configs (nil) the mounts are removed on update, the same as with an empty list
+	FileSystemConfigs []FileSystemConfig       `yaml:"file_system_configs"`
+	RuntimeManagement *RuntimeManagementConfig `yaml:"runtime_management"`
+}
+
+// IsImage reports whether the function is deployed from a container image rather than a zip archive.
+func (p DeployParams) IsImage() bool {
+	return strings.EqualFold(strings.TrimSpace(p.PackageType), "Image") || strings.TrimSpace(p.ImageUri) != ""
+}
+
+// LogGroupName returns the log group the function writes to
+func (p DeployParams) LogGroupName() string {
+	if p.Logging != nil && strings.TrimSpace(p.Logging.LogGroup) != "" {
+		return strings.TrimSpace(p.Logging.LogGroup)
+	}
+	return "/aws/lambda/" + p.FunctionName
+}
@@ -19,3 +19,21 @@ func TrimAndCheckEmptyString(s *string) bool {
 	*s = strings.TrimSpace(*s)
 	return len(*s) == 0
 }
+
+// ArnService returns the service part of an ARN, e.g. sqs for arn:aws:sqs:us-east-1:123456789012:queue
+func ArnService(arn string) string {
+	parts := strings.Split(arn, ":")
+	if len(parts) < 6 || parts[0] != "arn" {
+		return ""
+	}
+	return parts[2]
+}
+
+// ArnAccount returns the account part of an ARN, e.g. 123456789012 for arn:aws:sqs:us-east-1:123456789012:queue
+func ArnAccount(arn string) string {
+	parts := strings.Split(arn, ":")
+	if len(parts) < 6 || parts[0] != "arn" {
+		return ""
+	}
+	return parts[4]
+}
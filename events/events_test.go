@@ -0,0 +1,76 @@
+package events
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var now = time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)
+
+func decode(t *testing.T, event []byte) map[string]any {
+	var decoded map[string]any
+	assert.NoError(t, json.Unmarshal(event, &decoded))
+	return decoded
+}
+
+func TestGenerate(t *testing.T) {
+	for _, eventType := range Types() {
+		event, err := Generate(eventType, now, nil)
+		assert.NoError(t, err, eventType)
+		assert.NotEmpty(t, decode(t, event), eventType)
+	}
+	_, err := Generate("kafka", now, nil)
+	assert.ErrorContains(t, err, "Event type kafka is not supported")
+}
+
+func TestGenerateOverrides(t *testing.T) {
+	event, err := Generate("sqs", now, []string{`Records[0].body={"orderId":"7"}`, "Records[0].messageAttributes.tenant=acme"})
+	assert.NoError(t, err)
+	record := decode(t, event)["Records"].([]any)[0].(map[string]any)
+	assert.Equal(t, map[string]any{"orderId": "7"}, record["body"])
+	assert.Equal(t, map[string]any{"tenant": "acme"}, record["messageAttributes"])
+	assert.Equal(t, "1709281800000", record["attributes"].(map[string]any)["SentTimestamp"])
+
+	event, err = Generate("eventbridge", now, []string{"detail.total=12.5", "detail.customer.id=c1"})
+	assert.NoError(t, err)
+	detail := decode(t, event)["detail"].(map[string]any)
+	assert.Equal(t, 12.5, detail["total"])
+	assert.Equal(t, map[string]any{"id": "c1"}, detail["customer"])
+
+	event, err = Generate("kinesis", now, nil)
+	assert.NoError(t, err)
+	data := decode(t, event)["Records"].([]any)[0].(map[string]any)["kinesis"].(map[string]any)["data"].(string)
+	decoded, _ := base64.StdEncoding.DecodeString(data)
+	assert.JSONEq(t, `{"orderId":"42"}`, string(decoded))
+
+	_, err = Generate("sqs", now, []string{"Records[1].body=x"})
+	assert.ErrorContains(t, err, "has no element [1]")
+	_, err = Generate("sqs", now, []string{"Records"})
+	assert.ErrorContains(t, err, "must have the form path=value")
+	_, err = Generate("eventbridge", now, []string{"source.name=x"})
+	assert.ErrorContains(t, err, "is not an object at name")
+}
+
+func TestLibrary(t *testing.T) {
+	directory := t.TempDir()
+	fileName, err := Save(directory, "orders", "placed", []byte(`{"orderId":"42"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(directory, "orders", "placed.json"), fileName)
+	assert.NoError(t, os.WriteFile(filepath.Join(directory, "ping.json"), []byte(`{}`), 0644))
+
+	found, err := Find(directory, "orders", "placed.json")
+	assert.NoError(t, err)
+	assert.Equal(t, fileName, found)
+	// Shared events are found for every function
+	found, err = Find(directory, "orders", "ping")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(directory, "ping.json"), found)
+	_, err = Find(directory, "payments", "placed")
+	assert.ErrorContains(t, err, "Event placed not found")
+}
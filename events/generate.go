@@ -0,0 +1,267 @@
+package events
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	region    = "us-east-1"
+	accountId = "123456789012"
+)
+
+// templates builds the sample payload of each event type as it is delivered to the function
+var templates = map[string]func(now time.Time) map[string]any{
+	"sqs": func(now time.Time) map[string]any {
+		millis := strconv.FormatInt(now.UnixMilli(), 10)
+		return records(map[string]any{
+			"messageId":     "059f36b4-87a3-44ab-83d2-661975830a7d",
+			"receiptHandle": "AQEBwJnKyrHigUMZj6rYigCgxlaS3SLy0a",
+			"body":          `{"orderId":"42"}`,
+			"attributes": map[string]any{
+				"ApproximateReceiveCount":          "1",
+				"SentTimestamp":                    millis,
+				"SenderId":                         "AIDAIENQZJOLO23YVJ4VO",
+				"ApproximateFirstReceiveTimestamp": millis,
+			},
+			"messageAttributes": map[string]any{},
+			"md5OfBody":         "e4e68fb7bd0e697a0ae8f1bb342846b3",
+			"eventSource":       "aws:sqs",
+			"eventSourceARN":    fmt.Sprintf("arn:aws:sqs:%s:%s:orders", region, accountId),
+			"awsRegion":         region,
+		})
+	},
+	"s3": func(now time.Time) map[string]any {
+		return records(map[string]any{
+			"eventVersion":      "2.1",
+			"eventSource":       "aws:s3",
+			"awsRegion":         region,
+			"eventTime":         now.Format("2006-01-02T15:04:05.000Z"),
+			"eventName":         "ObjectCreated:Put",
+			"userIdentity":      map[string]any{"principalId": "EXAMPLE"},
+			"requestParameters": map[string]any{"sourceIPAddress": "127.0.0.1"},
+			"responseElements": map[string]any{
+				"x-amz-request-id": "EXAMPLE123456789",
+				"x-amz-id-2":       "EXAMPLE123/5678abcdefghijklambdaisawesome/mnopqrstuvwxyzABCDEFGH",
+			},
+			"s3": map[string]any{
+				"s3SchemaVersion": "1.0",
+				"configurationId": "testConfigRule",
+				"bucket": map[string]any{
+					"name":          "uploads",
+					"ownerIdentity": map[string]any{"principalId": "EXAMPLE"},
+					"arn":           "arn:aws:s3:::uploads",
+				},
+				"object": map[string]any{
+					"key":       "incoming/orders.csv",
+					"size":      1024,
+					"eTag":      "0123456789abcdef0123456789abcdef",
+					"sequencer": "0A1B2C3D4E5F678901",
+				},
+			},
+		})
+	},
+	"apigw-v2": func(now time.Time) map[string]any {
+		return map[string]any{
+			"version":        "2.0",
+			"routeKey":       "$default",
+			"rawPath":        "/orders",
+			"rawQueryString": "",
+			"headers": map[string]any{
+				"accept":       "application/json",
+				"content-type": "application/json",
+				"host":         "abc123.execute-api.us-east-1.amazonaws.com",
+				"user-agent":   "curl/8.4.0",
+			},
+			"requestContext": map[string]any{
+				"accountId":    accountId,
+				"apiId":        "abc123",
+				"domainName":   "abc123.execute-api.us-east-1.amazonaws.com",
+				"domainPrefix": "abc123",
+				"http": map[string]any{
+					"method":    "POST",
+					"path":      "/orders",
+					"protocol":  "HTTP/1.1",
+					"sourceIp":  "127.0.0.1",
+					"userAgent": "curl/8.4.0",
+				},
+				"requestId": "JKJaXmPLvHcESHA=",
+				"routeKey":  "$default",
+				"stage":     "$default",
+				"time":      now.Format("02/Jan/2006:15:04:05 -0700"),
+				"timeEpoch": now.UnixMilli(),
+			},
+			"body":            `{"orderId":"42"}`,
+			"isBase64Encoded": false,
+		}
+	},
+	"eventbridge": func(now time.Time) map[string]any {
+		return map[string]any{
+			"version":     "0",
+			"id":          "6a7e8feb-b491-4cf7-a9f1-bf3703467718",
+			"detail-type": "Order Placed",
+			"source":      "com.example.orders",
+			"account":     accountId,
+			"time":        now.Format(time.RFC3339),
+			"region":      region,
+			"resources":   []any{},
+			"detail":      map[string]any{"orderId": "42"},
+		}
+	},
+	"dynamodb": func(now time.Time) map[string]any {
+		return records(map[string]any{
+			"eventID":      "c4ca4238a0b923820dcc509a6f75849b",
+			"eventName":    "INSERT",
+			"eventVersion": "1.1",
+			"eventSource":  "aws:dynamodb",
+			"awsRegion":    region,
+			"dynamodb": map[string]any{
+				"ApproximateCreationDateTime": now.Unix(),
+				"Keys":                        map[string]any{"id": map[string]any{"S": "42"}},
+				"NewImage": map[string]any{
+					"id":     map[string]any{"S": "42"},
+					"status": map[string]any{"S": "PLACED"},
+				},
+				"SequenceNumber": "4421584500000000017450439091",
+				"SizeBytes":      26,
+				"StreamViewType": "NEW_AND_OLD_IMAGES",
+			},
+			"eventSourceARN": fmt.Sprintf("arn:aws:dynamodb:%s:%s:table/orders/stream/2024-01-01T00:00:00.000", region, accountId),
+		})
+	},
+	"kinesis": func(now time.Time) map[string]any {
+		return records(map[string]any{
+			"kinesis": map[string]any{
+				"kinesisSchemaVersion":        "1.0",
+				"partitionKey":                "42",
+				"sequenceNumber":              "49590338271490256608559692538361571095921575989136588898",
+				"data":                        base64.StdEncoding.EncodeToString([]byte(`{"orderId":"42"}`)),
+				"approximateArrivalTimestamp": float64(now.UnixMilli()) / 1000,
+			},
+			"eventSource":       "aws:kinesis",
+			"eventVersion":      "1.0",
+			"eventID":           "shardId-000000000006:49590338271490256608559692538361571095921575989136588898",
+			"eventName":         "aws:kinesis:record",
+			"invokeIdentityArn": fmt.Sprintf("arn:aws:iam::%s:role/lambda-role", accountId),
+			"awsRegion":         region,
+			"eventSourceARN":    fmt.Sprintf("arn:aws:kinesis:%s:%s:stream/orders", region, accountId),
+		})
+	},
+	"sns": func(now time.Time) map[string]any {
+		topicArn := fmt.Sprintf("arn:aws:sns:%s:%s:orders", region, accountId)
+		return records(map[string]any{
+			"EventSource":          "aws:sns",
+			"EventVersion":         "1.0",
+			"EventSubscriptionArn": topicArn + ":2bcfbf39-05c3-41de-beaa-fcfcc21c8f55",
+			"Sns": map[string]any{
+				"Type":              "Notification",
+				"MessageId":         "95df01b4-ee98-5cb9-9903-4c221d41eb5e",
+				"TopicArn":          topicArn,
+				"Subject":           "Order placed",
+				"Message":           `{"orderId":"42"}`,
+				"Timestamp":         now.Format("2006-01-02T15:04:05.000Z"),
+				"SignatureVersion":  "1",
+				"Signature":         "EXAMPLE",
+				"SigningCertUrl":    "https://sns.us-east-1.amazonaws.com/SimpleNotificationService-0000000000000000000000.pem",
+				"UnsubscribeUrl":    "https://sns.us-east-1.amazonaws.com/?Action=Unsubscribe&SubscriptionArn=" + topicArn,
+				"MessageAttributes": map[string]any{},
+			},
+		})
+	},
+}
+
+func records(record map[string]any) map[string]any {
+	return map[string]any{"Records": []any{record}}
+}
+
+// Types returns the event types which can be generated
+func Types() []string {
+	var types []string
+	for eventType := range templates {
+		types = append(types, eventType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Generate builds a sample event of the type and applies the overrides to it. An override has the form
+// path=value, e.g. Records[0].body=hello or detail.orderId=42. The value is used as JSON when it parses
+// as JSON, otherwise as a string.
+func Generate(eventType string, now time.Time, overrides []string) ([]byte, error) {
+	template, found := templates[eventType]
+	if !found {
+		return nil, &common.InputError{
+			Message: fmt.Sprintf("Event type %s is not supported. Possible values %s", eventType, strings.Join(Types(), ", ")),
+		}
+	}
+	event := template(now.UTC())
+	for _, override := range overrides {
+		path, value, found := strings.Cut(override, "=")
+		if !found {
+			return nil, &common.InputError{
+				Message: fmt.Sprintf("Override %s must have the form path=value", override),
+			}
+		}
+		var parsed any
+		if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+			parsed = value
+		}
+		if err := set(event, path, parsed); err != nil {
+			return nil, &common.InputError{
+				Message: err.Error(),
+			}
+		}
+	}
+	return json.MarshalIndent(event, "", "  ")
+}
+
+// set assigns the value at the path made of .key and [index] steps. Missing keys are created,
+// indexes must exist.
+func set(event map[string]any, path string, value any) error {
+	steps := strings.FieldsFunc(strings.ReplaceAll(path, "[", ".["), func(r rune) bool { return r == '.' })
+	if len(steps) == 0 {
+		return fmt.Errorf("override path %q is empty", path)
+	}
+	var current any = event
+	for index, step := range steps {
+		last := index == len(steps)-1
+		if strings.HasPrefix(step, "[") {
+			position, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(step, "["), "]"))
+			array, ok := current.([]any)
+			if err != nil || !ok || position < 0 || position >= len(array) {
+				return fmt.Errorf("override path %s has no element %s", path, step)
+			}
+			if last {
+				array[position] = value
+				return nil
+			}
+			current = array[position]
+			continue
+		}
+		object, ok := current.(map[string]any)
+		if !ok {
+			return fmt.Errorf("override path %s is not an object at %s", path, step)
+		}
+		if last {
+			object[step] = value
+			return nil
+		}
+		next, found := object[step]
+		if !found || next == nil {
+			// Only create objects when the next step is a key, arrays cannot be grown by index
+			if strings.HasPrefix(steps[index+1], "[") {
+				return fmt.Errorf("override path %s has no element %s", path, steps[index+1])
+			}
+			next = map[string]any{}
+			object[step] = next
+		}
+		current = next
+	}
+	return nil
+}
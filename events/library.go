@@ -0,0 +1,44 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultDirectory is the directory of the event library, relative to where the tool runs
+const DefaultDirectory = "events"
+
+// Path returns the file of the named event of the function, <directory>/<function>/<name>.json
+func Path(directory string, functionName string, name string) string {
+	return filepath.Join(directory, functionName, strings.TrimSuffix(name, ".json")+".json")
+}
+
+// Find returns the file of the named event. Events of the function take precedence over the shared
+// events at the top of the directory.
+func Find(directory string, functionName string, name string) (string, error) {
+	candidates := []string{Path(directory, functionName, name), Path(directory, "", name)}
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+	return "", &common.InputError{
+		Message: fmt.Sprintf("Event %s not found in %s", name, strings.Join(candidates, " or ")),
+	}
+}
+
+// Save writes the event of the function into the library and returns its file
+func Save(directory string, functionName string, name string, event []byte) (string, error) {
+	fileName := Path(directory, functionName, name)
+	if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
+		return "", err
+	}
+	return fileName, os.WriteFile(fileName, append(event, '\n'), 0644)
+}
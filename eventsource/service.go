@@ -0,0 +1,267 @@
+package eventsource
+
+import (
+	"context"
+	"fmt"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+// Source types are derived from the service in the event source ARN
+const (
+	SourceSqs      = "sqs"
+	SourceKinesis  = "kinesis"
+	SourceDynamoDB = "dynamodb"
+	SourceKafka    = "kafka"
+)
+
+func Client(ctx context.Context) *lambda.Client {
+	cfg, _ := config.LoadDefaultConfig(ctx)
+	return lambda.NewFromConfig(cfg)
+}
+
+// SourceType returns the kind of event source, or an empty string when the ARN is not supported.
+func SourceType(arn string) string {
+	service := common.ArnService(arn)
+	switch service {
+	case SourceSqs, SourceKinesis, SourceKafka:
+		return service
+	case SourceDynamoDB:
+		if strings.Contains(arn, "/stream/") {
+			return SourceDynamoDB
+		}
+	}
+	return ""
+}
+
+func isStream(sourceType string) bool {
+	return sourceType == SourceKinesis || sourceType == SourceDynamoDB || sourceType == SourceKafka
+}
+
+func ValidateEventSources(sources []common.EventSource) error {
+	var errorMessage strings.Builder
+	var keys []string
+	for _, source := range sources {
+		if common.TrimAndCheckEmptyString(&source.Arn) {
+			errorMessage.WriteString("Event Source ARN cannot be null.\n")
+			continue
+		}
+		if slices.Contains(keys, mappingKey(source.Arn, source.Topics)) {
+			errorMessage.WriteString(fmt.Sprintf("Event Source %s is declared more than once.\n", source.Arn))
+		}
+		keys = append(keys, mappingKey(source.Arn, source.Topics))
+		sourceType := SourceType(source.Arn)
+		if sourceType == "" {
+			errorMessage.WriteString(fmt.Sprintf("Event Source %s must be an SQS queue, Kinesis stream, DynamoDB stream or MSK cluster.\n", source.Arn))
+			continue
+		}
+		if sourceType == SourceKafka && len(source.Topics) != 1 {
+			errorMessage.WriteString(fmt.Sprintf("Event Source %s must have exactly one topic.\n", source.Arn))
+		}
+		if sourceType == SourceSqs && !common.TrimAndCheckEmptyString(&source.StartingPosition) {
+			errorMessage.WriteString(fmt.Sprintf("Starting Position cannot be used with SQS queue %s.\n", source.Arn))
+		}
+		if sourceType != SourceSqs && source.MaximumConcurrency != nil {
+			errorMessage.WriteString(fmt.Sprintf("Maximum Concurrency can only be used with SQS queues, not %s.\n", source.Arn))
+		}
+		if source.MaximumConcurrency != nil && (*source.MaximumConcurrency < 2 || *source.MaximumConcurrency > 1000) {
+			errorMessage.WriteString(fmt.Sprintf("Maximum Concurrency of %s must be between 2 and 1000.\n", source.Arn))
+		}
+		if source.StartingPosition != "" && source.StartingPosition != string(types.EventSourcePositionLatest) &&
+			source.StartingPosition != string(types.EventSourcePositionTrimHorizon) {
+			errorMessage.WriteString(fmt.Sprintf("Starting Position of %s must be either LATEST or TRIM_HORIZON.\n", source.Arn))
+		}
+	}
+	if len(errorMessage.String()) > 0 {
+		return &common.InputError{
+			Message: errorMessage.String(),
+		}
+	}
+	return nil
+}
+
+func (wrapper ServiceWrapper) ListMappings(ctx context.Context, functionName string) ([]types.EventSourceMappingConfiguration, error) {
+	var mappings []types.EventSourceMappingConfiguration
+	input := &lambda.ListEventSourceMappingsInput{FunctionName: &functionName}
+	for {
+		output, err := wrapper.Client.ListEventSourceMappings(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, output.EventSourceMappings...)
+		if output.NextMarker == nil {
+			break
+		}
+		input.Marker = output.NextMarker
+	}
+	return mappings, nil
+}
+
+// mappingKey identifies the mapping of an event source. An MSK cluster can be mapped once per topic.
+func mappingKey(arn string, topics []string) string {
+	sorted := slices.Clone(topics)
+	slices.Sort(sorted)
+	return arn + "#" + strings.Join(sorted, ",")
+}
+
+// Reconcile creates the declared event sources which have no mapping yet, updates the mappings
+// whose settings changed and deletes the mappings of event sources which are no longer declared.
+// Mappings without an event source ARN, like self-managed Kafka, are not managed by the tool and left alone.
+func (wrapper ServiceWrapper) Reconcile(ctx context.Context, functionName string, sources []common.EventSource) error {
+	mappings, err := wrapper.ListMappings(ctx, functionName)
+	if err != nil {
+		log.Printf("Not able to list event source mappings. The reason is %s", err.Error())
+		return err
+	}
+	existing := map[string][]types.EventSourceMappingConfiguration{}
+	for _, mapping := range mappings {
+		if mapping.EventSourceArn == nil {
+			continue
+		}
+		key := mappingKey(*mapping.EventSourceArn, mapping.Topics)
+		existing[key] = append(existing[key], mapping)
+	}
+	for _, source := range sources {
+		key := mappingKey(source.Arn, source.Topics)
+		found := existing[key]
+		if len(found) == 0 {
+			log.Println("Creating event source mapping for", source.Arn)
+			if _, err := wrapper.Client.CreateEventSourceMapping(ctx, createInput(functionName, source)); err != nil {
+				log.Printf("Not able to create event source mapping for %s. The reason is %s", source.Arn, err.Error())
+				return err
+			}
+			continue
+		}
+		// The first mapping is kept, any further mapping of the same source is deleted below
+		mapping := found[0]
+		existing[key] = found[1:]
+		if source.StartingPosition != "" && source.StartingPosition != string(mapping.StartingPosition) {
+			log.Printf("Starting Position of %s cannot be changed on an existing mapping. Keeping %s\n", source.Arn, mapping.StartingPosition)
+		}
+		if !changed(mapping, source) {
+			continue
+		}
+		log.Println("Updating event source mapping for", source.Arn)
+		if _, err := wrapper.Client.UpdateEventSourceMapping(ctx, updateInput(functionName, *mapping.UUID, source)); err != nil {
+			log.Printf("Not able to update event source mapping for %s. The reason is %s", source.Arn, err.Error())
+			return err
+		}
+	}
+	for _, remaining := range existing {
+		for _, mapping := range remaining {
+			arn := *mapping.EventSourceArn
+			log.Println("Deleting event source mapping for", arn)
+			if _, err := wrapper.Client.DeleteEventSourceMapping(ctx, &lambda.DeleteEventSourceMappingInput{UUID: mapping.UUID}); err != nil {
+				log.Printf("Not able to delete event source mapping for %s. The reason is %s", arn, err.Error())
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func int32Pointer(value *int) *int32 {
+	if value == nil {
+		return nil
+	}
+	result := int32(*value)
+	return &result
+}
+
+func filterCriteria(source common.EventSource) *types.FilterCriteria {
+	// An empty filter list removes the filters of an existing mapping
+	criteria := &types.FilterCriteria{Filters: []types.Filter{}}
+	for _, pattern := range source.FilterCriteria {
+		pattern := pattern
+		criteria.Filters = append(criteria.Filters, types.Filter{Pattern: &pattern})
+	}
+	return criteria
+}
+
+func functionResponseTypes(source common.EventSource) []types.FunctionResponseType {
+	if source.ReportBatchItemFailures {
+		return []types.FunctionResponseType{types.FunctionResponseTypeReportBatchItemFailures}
+	}
+	return []types.FunctionResponseType{}
+}
+
+func scalingConfig(source common.EventSource) *types.ScalingConfig {
+	if source.MaximumConcurrency == nil {
+		return nil
+	}
+	return &types.ScalingConfig{MaximumConcurrency: int32Pointer(source.MaximumConcurrency)}
+}
+
+func createInput(functionName string, source common.EventSource) *lambda.CreateEventSourceMappingInput {
+	input := &lambda.CreateEventSourceMappingInput{
+		FunctionName:                   &functionName,
+		EventSourceArn:                 &source.Arn,
+		BatchSize:                      int32Pointer(source.BatchSize),
+		MaximumBatchingWindowInSeconds: int32Pointer(source.MaximumBatchingWindowSeconds),
+		FunctionResponseTypes:          functionResponseTypes(source),
+		ScalingConfig:                  scalingConfig(source),
+		Topics:                         source.Topics,
+	}
+	if len(source.FilterCriteria) > 0 {
+		input.FilterCriteria = filterCriteria(source)
+	}
+	if isStream(SourceType(source.Arn)) {
+		input.StartingPosition = types.EventSourcePositionLatest
+		if source.StartingPosition != "" {
+			input.StartingPosition = types.EventSourcePosition(source.StartingPosition)
+		}
+	}
+	return input
+}
+
+func updateInput(functionName string, uuid string, source common.EventSource) *lambda.UpdateEventSourceMappingInput {
+	return &lambda.UpdateEventSourceMappingInput{
+		UUID:                           &uuid,
+		FunctionName:                   &functionName,
+		BatchSize:                      int32Pointer(source.BatchSize),
+		MaximumBatchingWindowInSeconds: int32Pointer(source.MaximumBatchingWindowSeconds),
+		FilterCriteria:                 filterCriteria(source),
+		FunctionResponseTypes:          functionResponseTypes(source),
+		ScalingConfig:                  scalingConfig(source),
+	}
+}
+
+func int32Changed(current *int32, desired *int) bool {
+	if desired == nil {
+		return false
+	}
+	return current == nil || *current != int32(*desired)
+}
+
+// changed compares the settings of the mapping with the declared ones. Settings which are
+// not declared keep whatever value the mapping has.
+func changed(mapping types.EventSourceMappingConfiguration, source common.EventSource) bool {
+	if int32Changed(mapping.BatchSize, source.BatchSize) ||
+		int32Changed(mapping.MaximumBatchingWindowInSeconds, source.MaximumBatchingWindowSeconds) {
+		return true
+	}
+	var currentConcurrency *int32
+	if mapping.ScalingConfig != nil {
+		currentConcurrency = mapping.ScalingConfig.MaximumConcurrency
+	}
+	if int32Changed(currentConcurrency, source.MaximumConcurrency) {
+		return true
+	}
+	reportsFailures := slices.Contains(mapping.FunctionResponseTypes, types.FunctionResponseTypeReportBatchItemFailures)
+	if reportsFailures != source.ReportBatchItemFailures {
+		return true
+	}
+	var currentPatterns []string
+	if mapping.FilterCriteria != nil {
+		for _, filter := range mapping.FilterCriteria.Filters {
+			currentPatterns = append(currentPatterns, *filter.Pattern)
+		}
+	}
+	return !slices.Equal(currentPatterns, source.FilterCriteria)
+}
@@ -0,0 +1,135 @@
+package eventsource
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	queueArn  = "arn:aws:sqs:us-east-1:123456789012:orders"
+	streamArn = "arn:aws:dynamodb:us-east-1:123456789012:table/orders/stream/2024-01-01T00:00:00.000"
+)
+
+type mockEventSourceApi struct {
+	mappings map[string]types.EventSourceMappingConfiguration
+	created  []*lambda.CreateEventSourceMappingInput
+	updated  []*lambda.UpdateEventSourceMappingInput
+	deleted  []string
+}
+
+func (m *mockEventSourceApi) ListEventSourceMappings(ctx context.Context, params *lambda.ListEventSourceMappingsInput, optFns ...func(*lambda.Options)) (*lambda.ListEventSourceMappingsOutput, error) {
+	output := &lambda.ListEventSourceMappingsOutput{}
+	for _, mapping := range m.mappings {
+		output.EventSourceMappings = append(output.EventSourceMappings, mapping)
+	}
+	return output, nil
+}
+
+func (m *mockEventSourceApi) CreateEventSourceMapping(ctx context.Context, params *lambda.CreateEventSourceMappingInput, optFns ...func(*lambda.Options)) (*lambda.CreateEventSourceMappingOutput, error) {
+	m.created = append(m.created, params)
+	uuid := strconv.Itoa(len(m.mappings) + 1)
+	m.mappings[uuid] = types.EventSourceMappingConfiguration{
+		UUID:           aws.String(uuid),
+		EventSourceArn: params.EventSourceArn,
+		BatchSize:      params.BatchSize,
+		Topics:         params.Topics,
+	}
+	return &lambda.CreateEventSourceMappingOutput{UUID: aws.String(uuid)}, nil
+}
+
+func (m *mockEventSourceApi) UpdateEventSourceMapping(ctx context.Context, params *lambda.UpdateEventSourceMappingInput, optFns ...func(*lambda.Options)) (*lambda.UpdateEventSourceMappingOutput, error) {
+	m.updated = append(m.updated, params)
+	mapping := m.mappings[*params.UUID]
+	mapping.BatchSize = params.BatchSize
+	m.mappings[*params.UUID] = mapping
+	return &lambda.UpdateEventSourceMappingOutput{UUID: params.UUID}, nil
+}
+
+func (m *mockEventSourceApi) DeleteEventSourceMapping(ctx context.Context, params *lambda.DeleteEventSourceMappingInput, optFns ...func(*lambda.Options)) (*lambda.DeleteEventSourceMappingOutput, error) {
+	m.deleted = append(m.deleted, *params.UUID)
+	delete(m.mappings, *params.UUID)
+	return &lambda.DeleteEventSourceMappingOutput{UUID: params.UUID}, nil
+}
+
+func TestSourceType(t *testing.T) {
+	assert.Equal(t, SourceSqs, SourceType(queueArn))
+	assert.Equal(t, SourceDynamoDB, SourceType(streamArn))
+	assert.Equal(t, SourceKinesis, SourceType("arn:aws:kinesis:us-east-1:123456789012:stream/clicks"))
+	assert.Equal(t, "", SourceType("arn:aws:dynamodb:us-east-1:123456789012:table/orders"))
+}
+
+func TestValidateEventSources(t *testing.T) {
+	concurrency := 5
+	assert.NoError(t, ValidateEventSources([]common.EventSource{
+		{Arn: queueArn, MaximumConcurrency: &concurrency},
+		{Arn: streamArn, StartingPosition: "TRIM_HORIZON"},
+	}))
+	assert.Error(t, ValidateEventSources([]common.EventSource{{Arn: queueArn}, {Arn: queueArn}}))
+	assert.Error(t, ValidateEventSources([]common.EventSource{{Arn: streamArn, MaximumConcurrency: &concurrency}}))
+	assert.Error(t, ValidateEventSources([]common.EventSource{{Arn: "arn:aws:kafka:us-east-1:123456789012:cluster/events/abc"}}))
+}
+
+func TestReconcile(t *testing.T) {
+	mock := &mockEventSourceApi{mappings: map[string]types.EventSourceMappingConfiguration{
+		"stale": {UUID: aws.String("stale"), EventSourceArn: aws.String("arn:aws:sqs:us-east-1:123456789012:old")},
+	}}
+	wrapper := ServiceWrapper{Client: mock}
+	batchSize := 10
+	sources := []common.EventSource{
+		{Arn: queueArn, BatchSize: &batchSize},
+		{Arn: streamArn},
+	}
+
+	assert.NoError(t, wrapper.Reconcile(context.TODO(), "test", sources))
+	assert.Len(t, mock.created, 2)
+	assert.Equal(t, types.EventSourcePosition(""), mock.created[0].StartingPosition)
+	assert.Equal(t, types.EventSourcePositionLatest, mock.created[1].StartingPosition)
+	assert.Equal(t, []string{"stale"}, mock.deleted)
+	assert.Empty(t, mock.updated)
+
+	// Nothing changed, nothing to do
+	assert.NoError(t, wrapper.Reconcile(context.TODO(), "test", sources))
+	assert.Len(t, mock.created, 2)
+	assert.Empty(t, mock.updated)
+
+	batchSize = 20
+	assert.NoError(t, wrapper.Reconcile(context.TODO(), "test", sources))
+	assert.Len(t, mock.updated, 1)
+	assert.Equal(t, int32(20), *mock.updated[0].BatchSize)
+
+	assert.NoError(t, wrapper.Reconcile(context.TODO(), "test", []common.EventSource{}))
+	assert.Empty(t, mock.mappings)
+}
+
+func TestReconcileUnmanagedAndDuplicateMappings(t *testing.T) {
+	clusterArn := "arn:aws:kafka:us-east-1:123456789012:cluster/events/abc"
+	mock := &mockEventSourceApi{mappings: map[string]types.EventSourceMappingConfiguration{
+		// Self-managed Kafka mappings have no event source ARN
+		"kafka": {UUID: aws.String("kafka"), Topics: []string{"orders"}},
+		"first": {UUID: aws.String("first"), EventSourceArn: aws.String(queueArn)},
+		"twin":  {UUID: aws.String("twin"), EventSourceArn: aws.String(queueArn)},
+		"topic": {UUID: aws.String("topic"), EventSourceArn: aws.String(clusterArn), Topics: []string{"orders"}},
+	}}
+	wrapper := ServiceWrapper{Client: mock}
+	sources := []common.EventSource{
+		{Arn: queueArn},
+		{Arn: clusterArn, Topics: []string{"orders"}},
+		{Arn: clusterArn, Topics: []string{"payments"}},
+	}
+	assert.NoError(t, ValidateEventSources(sources))
+
+	assert.NoError(t, wrapper.Reconcile(context.TODO(), "test", sources))
+	assert.Len(t, mock.created, 1)
+	assert.Equal(t, []string{"payments"}, mock.created[0].Topics)
+	assert.Len(t, mock.deleted, 1)
+	assert.Contains(t, []string{"first", "twin"}, mock.deleted[0])
+	assert.Contains(t, mock.mappings, "kafka")
+	assert.Contains(t, mock.mappings, "topic")
+}
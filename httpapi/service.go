@@ -0,0 +1,321 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2/types"
+	"github.com/aws/smithy-go"
+)
+
+const defaultStage = "$default"
+
+var routeMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS", "ANY"}
+
+func Client(ctx context.Context) *apigatewayv2.Client {
+	cfg, _ := config.LoadDefaultConfig(ctx)
+	return apigatewayv2.NewFromConfig(cfg)
+}
+
+func ValidateHttpApi(httpApi *common.HttpApiConfig) error {
+	if httpApi == nil || httpApi.Deleted() {
+		return nil
+	}
+	var errorMessage strings.Builder
+	for _, route := range httpApi.Routes {
+		if route == defaultStage {
+			continue
+		}
+		parts := strings.Fields(route)
+		if len(parts) != 2 || !slices.Contains(routeMethods, parts[0]) || !strings.HasPrefix(parts[1], "/") {
+			errorMessage.WriteString(fmt.Sprintf("Route %q must be $default or a method followed by a path, e.g. GET /items.\n", route))
+		}
+	}
+	if !common.TrimAndCheckEmptyString(&httpApi.DomainName) && common.TrimAndCheckEmptyString(&httpApi.CertificateArn) {
+		errorMessage.WriteString("Certificate ARN must be specified along with Domain Name.\n")
+	}
+	if len(errorMessage.String()) > 0 {
+		return &common.InputError{
+			Message: errorMessage.String(),
+		}
+	}
+	return nil
+}
+
+func isNotFound(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		_, ok := apiErr.(*types.NotFoundException)
+		return ok
+	}
+	return false
+}
+
+func stageName(httpApi *common.HttpApiConfig) string {
+	if common.TrimAndCheckEmptyString(&httpApi.Stage) {
+		return defaultStage
+	}
+	return httpApi.Stage
+}
+
+// findApi returns the HTTP API named after the function
+func (wrapper ServiceWrapper) findApi(ctx context.Context, functionName string) (*types.Api, error) {
+	apisInput := &apigatewayv2.GetApisInput{}
+	for {
+		output, err := wrapper.Client.GetApis(ctx, apisInput)
+		if err != nil {
+			return nil, err
+		}
+		for _, api := range output.Items {
+			if aws.ToString(api.Name) == functionName && api.ProtocolType == types.ProtocolTypeHttp {
+				return &api, nil
+			}
+		}
+		if output.NextToken == nil {
+			return nil, nil
+		}
+		apisInput.NextToken = output.NextToken
+	}
+}
+
+func (wrapper ServiceWrapper) integrationId(ctx context.Context, apiId string, functionArn string) (string, error) {
+	integrationsInput := &apigatewayv2.GetIntegrationsInput{ApiId: &apiId}
+	for {
+		output, err := wrapper.Client.GetIntegrations(ctx, integrationsInput)
+		if err != nil {
+			return "", err
+		}
+		for _, integration := range output.Items {
+			if aws.ToString(integration.IntegrationUri) == functionArn {
+				return *integration.IntegrationId, nil
+			}
+		}
+		if output.NextToken == nil {
+			break
+		}
+		integrationsInput.NextToken = output.NextToken
+	}
+	log.Println("Creating Lambda proxy integration-----")
+	integration, err := wrapper.Client.CreateIntegration(ctx, &apigatewayv2.CreateIntegrationInput{
+		ApiId:                &apiId,
+		IntegrationType:      types.IntegrationTypeAwsProxy,
+		IntegrationUri:       &functionArn,
+		PayloadFormatVersion: aws.String("2.0"),
+	})
+	if err != nil {
+		return "", err
+	}
+	return *integration.IntegrationId, nil
+}
+
+// reconcileRoutes creates the declared routes and deletes the routes to the function which are no longer declared
+func (wrapper ServiceWrapper) reconcileRoutes(ctx context.Context, apiId string, integrationId string, routes []string) error {
+	target := "integrations/" + integrationId
+	existing := map[string]types.Route{}
+	routesInput := &apigatewayv2.GetRoutesInput{ApiId: &apiId}
+	for {
+		output, err := wrapper.Client.GetRoutes(ctx, routesInput)
+		if err != nil {
+			return err
+		}
+		for _, route := range output.Items {
+			existing[*route.RouteKey] = route
+		}
+		if output.NextToken == nil {
+			break
+		}
+		routesInput.NextToken = output.NextToken
+	}
+	for _, routeKey := range routes {
+		if _, ok := existing[routeKey]; ok {
+			continue
+		}
+		log.Println("Creating route", routeKey)
+		_, err := wrapper.Client.CreateRoute(ctx, &apigatewayv2.CreateRouteInput{
+			ApiId:    &apiId,
+			RouteKey: aws.String(routeKey),
+			Target:   &target,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for routeKey, route := range existing {
+		if slices.Contains(routes, routeKey) || aws.ToString(route.Target) != target {
+			continue
+		}
+		log.Println("Deleting route", routeKey)
+		if _, err := wrapper.Client.DeleteRoute(ctx, &apigatewayv2.DeleteRouteInput{ApiId: &apiId, RouteId: route.RouteId}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (wrapper ServiceWrapper) ensureStage(ctx context.Context, apiId string, stage string) error {
+	_, err := wrapper.Client.GetStage(ctx, &apigatewayv2.GetStageInput{ApiId: &apiId, StageName: &stage})
+	if err == nil || !isNotFound(err) {
+		return err
+	}
+	log.Println("Creating stage", stage)
+	_, err = wrapper.Client.CreateStage(ctx, &apigatewayv2.CreateStageInput{
+		ApiId:      &apiId,
+		StageName:  &stage,
+		AutoDeploy: aws.Bool(true),
+	})
+	return err
+}
+
+func (wrapper ServiceWrapper) ensureDomain(ctx context.Context, apiId string, httpApi *common.HttpApiConfig) error {
+	_, err := wrapper.Client.GetDomainName(ctx, &apigatewayv2.GetDomainNameInput{DomainName: &httpApi.DomainName})
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+	if err != nil {
+		log.Println("Creating custom domain", httpApi.DomainName)
+		_, err = wrapper.Client.CreateDomainName(ctx, &apigatewayv2.CreateDomainNameInput{
+			DomainName: &httpApi.DomainName,
+			DomainNameConfigurations: []types.DomainNameConfiguration{{
+				CertificateArn: &httpApi.CertificateArn,
+				EndpointType:   types.EndpointTypeRegional,
+				SecurityPolicy: types.SecurityPolicyTls12,
+			}},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	stage := stageName(httpApi)
+	common.TrimAndCheckEmptyString(&httpApi.BasePath)
+	mappingsInput := &apigatewayv2.GetApiMappingsInput{DomainName: &httpApi.DomainName}
+	for {
+		mappings, err := wrapper.Client.GetApiMappings(ctx, mappingsInput)
+		if err != nil {
+			return err
+		}
+		for _, mapping := range mappings.Items {
+			if aws.ToString(mapping.ApiId) != apiId {
+				continue
+			}
+			if aws.ToString(mapping.Stage) == stage && aws.ToString(mapping.ApiMappingKey) == httpApi.BasePath {
+				return nil
+			}
+			log.Println("Updating the mapping of the API to", httpApi.DomainName)
+			// An empty key maps the API at the root of the domain
+			_, err = wrapper.Client.UpdateApiMapping(ctx, &apigatewayv2.UpdateApiMappingInput{
+				ApiId:         &apiId,
+				ApiMappingId:  mapping.ApiMappingId,
+				DomainName:    &httpApi.DomainName,
+				Stage:         &stage,
+				ApiMappingKey: &httpApi.BasePath,
+			})
+			return err
+		}
+		if mappings.NextToken == nil {
+			break
+		}
+		mappingsInput.NextToken = mappings.NextToken
+	}
+	log.Println("Mapping the API to", httpApi.DomainName)
+	mappingInput := &apigatewayv2.CreateApiMappingInput{
+		ApiId:      &apiId,
+		DomainName: &httpApi.DomainName,
+		Stage:      &stage,
+	}
+	if httpApi.BasePath != "" {
+		mappingInput.ApiMappingKey = &httpApi.BasePath
+	}
+	_, err = wrapper.Client.CreateApiMapping(ctx, mappingInput)
+	return err
+}
+
+// Put creates or updates the HTTP API named after the function with a Lambda proxy integration,
+// the declared routes, an auto deployed stage and the custom domain mapping. An empty config
+// deletes the API and without a config (nil) the API is left as it is.
+func (wrapper ServiceWrapper) Put(ctx context.Context, functionName string, functionArn string, httpApi *common.HttpApiConfig) (*HttpApi, error) {
+	api, err := wrapper.findApi(ctx, functionName)
+	if err != nil {
+		log.Printf("Not able to list the HTTP APIs. The reason is %s", err.Error())
+		return nil, err
+	}
+	if httpApi == nil {
+		if api == nil {
+			return nil, nil
+		}
+		return &HttpApi{ApiId: *api.ApiId, Endpoint: aws.ToString(api.ApiEndpoint)}, nil
+	}
+	if httpApi.Deleted() {
+		if api == nil {
+			return nil, nil
+		}
+		log.Println("Deleting HTTP API", *api.ApiId)
+		_, err = wrapper.Client.DeleteApi(ctx, &apigatewayv2.DeleteApiInput{ApiId: api.ApiId})
+		return nil, err
+	}
+	if api == nil {
+		log.Println("Creating HTTP API", functionName)
+		output, err := wrapper.Client.CreateApi(ctx, &apigatewayv2.CreateApiInput{
+			Name:         &functionName,
+			ProtocolType: types.ProtocolTypeHttp,
+		})
+		if err != nil {
+			log.Printf("Not able to create the HTTP API. The reason is %s", err.Error())
+			return nil, err
+		}
+		api = &types.Api{ApiId: output.ApiId, ApiEndpoint: output.ApiEndpoint}
+	}
+	apiId := *api.ApiId
+	integrationId, err := wrapper.integrationId(ctx, apiId, functionArn)
+	if err != nil {
+		log.Printf("Not able to set up the Lambda integration. The reason is %s", err.Error())
+		return nil, err
+	}
+	if err = wrapper.reconcileRoutes(ctx, apiId, integrationId, httpApi.Routes); err != nil {
+		log.Printf("Not able to update the routes. The reason is %s", err.Error())
+		return nil, err
+	}
+	stage := stageName(httpApi)
+	if err = wrapper.ensureStage(ctx, apiId, stage); err != nil {
+		log.Printf("Not able to create stage %s. The reason is %s", stage, err.Error())
+		return nil, err
+	}
+	result := &HttpApi{ApiId: apiId, Endpoint: aws.ToString(api.ApiEndpoint)}
+	if stage != defaultStage {
+		result.Endpoint += "/" + stage
+	}
+	if !common.TrimAndCheckEmptyString(&httpApi.DomainName) {
+		if err = wrapper.ensureDomain(ctx, apiId, httpApi); err != nil {
+			log.Printf("Not able to map the custom domain. The reason is %s", err.Error())
+			return nil, err
+		}
+		result.DomainEndpoint = "https://" + httpApi.DomainName
+		if httpApi.BasePath != "" {
+			result.DomainEndpoint += "/" + httpApi.BasePath
+		}
+	}
+	return result, nil
+}
+
+// Permissions returns the permission allowing the API to invoke the function
+func Permissions(httpApi *HttpApi, functionArn string) []common.Permission {
+	if httpApi == nil {
+		return nil
+	}
+	// arn:aws:lambda:region:account:function:name
+	parts := strings.Split(functionArn, ":")
+	if len(parts) < 5 {
+		return nil
+	}
+	return []common.Permission{{
+		Principal: "apigateway.amazonaws.com",
+		SourceArn: fmt.Sprintf("arn:%s:execute-api:%s:%s:%s/*", parts[1], parts[3], parts[4], httpApi.ApiId),
+	}}
+}
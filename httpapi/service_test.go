@@ -0,0 +1,230 @@
+package httpapi
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const functionArn = "arn:aws:lambda:us-east-1:123456789012:function:orders"
+
+// fakeApiGateway keeps the APIs, integrations, routes, stages and domains in memory
+type fakeApiGateway struct {
+	apis         []types.Api
+	integrations map[string][]types.Integration
+	routes       map[string][]types.Route
+	stages       map[string][]string
+	domains      map[string]bool
+	mappings     map[string][]types.ApiMapping
+	nextId       int
+	// pageSize splits the integrations and routes into pages when set
+	pageSize int
+}
+
+func newFakeApiGateway() *fakeApiGateway {
+	return &fakeApiGateway{
+		integrations: map[string][]types.Integration{},
+		routes:       map[string][]types.Route{},
+		stages:       map[string][]string{},
+		domains:      map[string]bool{},
+		mappings:     map[string][]types.ApiMapping{},
+	}
+}
+
+func (f *fakeApiGateway) id() *string {
+	f.nextId++
+	return aws.String("id" + strconv.Itoa(f.nextId))
+}
+
+// page returns the items from the token on, pageSize items at a time
+func page[T any](items []T, token *string, pageSize int) ([]T, *string) {
+	start := 0
+	if token != nil {
+		start, _ = strconv.Atoi(*token)
+	}
+	if pageSize == 0 || start+pageSize >= len(items) {
+		return items[start:], nil
+	}
+	return items[start : start+pageSize], aws.String(strconv.Itoa(start + pageSize))
+}
+
+func (f *fakeApiGateway) GetApis(ctx context.Context, params *apigatewayv2.GetApisInput, optFns ...func(*apigatewayv2.Options)) (*apigatewayv2.GetApisOutput, error) {
+	return &apigatewayv2.GetApisOutput{Items: f.apis}, nil
+}
+
+func (f *fakeApiGateway) CreateApi(ctx context.Context, params *apigatewayv2.CreateApiInput, optFns ...func(*apigatewayv2.Options)) (*apigatewayv2.CreateApiOutput, error) {
+	apiId := f.id()
+	endpoint := aws.String("https://" + *apiId + ".execute-api.us-east-1.amazonaws.com")
+	f.apis = append(f.apis, types.Api{ApiId: apiId, Name: params.Name, ProtocolType: params.ProtocolType, ApiEndpoint: endpoint})
+	return &apigatewayv2.CreateApiOutput{ApiId: apiId, ApiEndpoint: endpoint}, nil
+}
+
+func (f *fakeApiGateway) DeleteApi(ctx context.Context, params *apigatewayv2.DeleteApiInput, optFns ...func(*apigatewayv2.Options)) (*apigatewayv2.DeleteApiOutput, error) {
+	var apis []types.Api
+	for _, api := range f.apis {
+		if *api.ApiId != *params.ApiId {
+			apis = append(apis, api)
+		}
+	}
+	f.apis = apis
+	return &apigatewayv2.DeleteApiOutput{}, nil
+}
+
+func (f *fakeApiGateway) GetIntegrations(ctx context.Context, params *apigatewayv2.GetIntegrationsInput, optFns ...func(*apigatewayv2.Options)) (*apigatewayv2.GetIntegrationsOutput, error) {
+	items, nextToken := page(f.integrations[*params.ApiId], params.NextToken, f.pageSize)
+	return &apigatewayv2.GetIntegrationsOutput{Items: items, NextToken: nextToken}, nil
+}
+
+func (f *fakeApiGateway) CreateIntegration(ctx context.Context, params *apigatewayv2.CreateIntegrationInput, optFns ...func(*apigatewayv2.Options)) (*apigatewayv2.CreateIntegrationOutput, error) {
+	integrationId := f.id()
+	f.integrations[*params.ApiId] = append(f.integrations[*params.ApiId], types.Integration{
+		IntegrationId:   integrationId,
+		IntegrationUri:  params.IntegrationUri,
+		IntegrationType: params.IntegrationType,
+	})
+	return &apigatewayv2.CreateIntegrationOutput{IntegrationId: integrationId}, nil
+}
+
+func (f *fakeApiGateway) GetRoutes(ctx context.Context, params *apigatewayv2.GetRoutesInput, optFns ...func(*apigatewayv2.Options)) (*apigatewayv2.GetRoutesOutput, error) {
+	items, nextToken := page(f.routes[*params.ApiId], params.NextToken, f.pageSize)
+	return &apigatewayv2.GetRoutesOutput{Items: items, NextToken: nextToken}, nil
+}
+
+func (f *fakeApiGateway) CreateRoute(ctx context.Context, params *apigatewayv2.CreateRouteInput, optFns ...func(*apigatewayv2.Options)) (*apigatewayv2.CreateRouteOutput, error) {
+	routeId := f.id()
+	f.routes[*params.ApiId] = append(f.routes[*params.ApiId], types.Route{RouteId: routeId, RouteKey: params.RouteKey, Target: params.Target})
+	return &apigatewayv2.CreateRouteOutput{RouteId: routeId}, nil
+}
+
+func (f *fakeApiGateway) DeleteRoute(ctx context.Context, params *apigatewayv2.DeleteRouteInput, optFns ...func(*apigatewayv2.Options)) (*apigatewayv2.DeleteRouteOutput, error) {
+	var routes []types.Route
+	for _, route := range f.routes[*params.ApiId] {
+		if *route.RouteId != *params.RouteId {
+			routes = append(routes, route)
+		}
+	}
+	f.routes[*params.ApiId] = routes
+	return &apigatewayv2.DeleteRouteOutput{}, nil
+}
+
+func (f *fakeApiGateway) GetStage(ctx context.Context, params *apigatewayv2.GetStageInput, optFns ...func(*apigatewayv2.Options)) (*apigatewayv2.GetStageOutput, error) {
+	for _, stage := range f.stages[*params.ApiId] {
+		if stage == *params.StageName {
+			return &apigatewayv2.GetStageOutput{StageName: params.StageName}, nil
+		}
+	}
+	return nil, &types.NotFoundException{}
+}
+
+func (f *fakeApiGateway) CreateStage(ctx context.Context, params *apigatewayv2.CreateStageInput, optFns ...func(*apigatewayv2.Options)) (*apigatewayv2.CreateStageOutput, error) {
+	f.stages[*params.ApiId] = append(f.stages[*params.ApiId], *params.StageName)
+	return &apigatewayv2.CreateStageOutput{StageName: params.StageName, AutoDeploy: params.AutoDeploy}, nil
+}
+
+func (f *fakeApiGateway) GetDomainName(ctx context.Context, params *apigatewayv2.GetDomainNameInput, optFns ...func(*apigatewayv2.Options)) (*apigatewayv2.GetDomainNameOutput, error) {
+	if !f.domains[*params.DomainName] {
+		return nil, &types.NotFoundException{}
+	}
+	return &apigatewayv2.GetDomainNameOutput{DomainName: params.DomainName}, nil
+}
+
+func (f *fakeApiGateway) CreateDomainName(ctx context.Context, params *apigatewayv2.CreateDomainNameInput, optFns ...func(*apigatewayv2.Options)) (*apigatewayv2.CreateDomainNameOutput, error) {
+	f.domains[*params.DomainName] = true
+	return &apigatewayv2.CreateDomainNameOutput{DomainName: params.DomainName}, nil
+}
+
+func (f *fakeApiGateway) GetApiMappings(ctx context.Context, params *apigatewayv2.GetApiMappingsInput, optFns ...func(*apigatewayv2.Options)) (*apigatewayv2.GetApiMappingsOutput, error) {
+	return &apigatewayv2.GetApiMappingsOutput{Items: f.mappings[*params.DomainName]}, nil
+}
+
+func (f *fakeApiGateway) CreateApiMapping(ctx context.Context, params *apigatewayv2.CreateApiMappingInput, optFns ...func(*apigatewayv2.Options)) (*apigatewayv2.CreateApiMappingOutput, error) {
+	f.mappings[*params.DomainName] = append(f.mappings[*params.DomainName], types.ApiMapping{
+		ApiId:         params.ApiId,
+		Stage:         params.Stage,
+		ApiMappingKey: params.ApiMappingKey,
+		ApiMappingId:  f.id(),
+	})
+	return &apigatewayv2.CreateApiMappingOutput{}, nil
+}
+
+func (f *fakeApiGateway) UpdateApiMapping(ctx context.Context, params *apigatewayv2.UpdateApiMappingInput, optFns ...func(*apigatewayv2.Options)) (*apigatewayv2.UpdateApiMappingOutput, error) {
+	for index, mapping := range f.mappings[*params.DomainName] {
+		if *mapping.ApiMappingId == *params.ApiMappingId {
+			f.mappings[*params.DomainName][index].Stage = params.Stage
+			f.mappings[*params.DomainName][index].ApiMappingKey = params.ApiMappingKey
+		}
+	}
+	return &apigatewayv2.UpdateApiMappingOutput{}, nil
+}
+
+func routeKeys(routes []types.Route) []string {
+	var keys []string
+	for _, route := range routes {
+		keys = append(keys, *route.RouteKey)
+	}
+	return keys
+}
+
+func TestValidateHttpApi(t *testing.T) {
+	assert.NoError(t, ValidateHttpApi(&common.HttpApiConfig{Routes: []string{"$default", "GET /items/{id}"}}))
+	assert.NoError(t, ValidateHttpApi(&common.HttpApiConfig{}))
+	assert.Error(t, ValidateHttpApi(&common.HttpApiConfig{Routes: []string{"FETCH /items"}}))
+	assert.Error(t, ValidateHttpApi(&common.HttpApiConfig{Routes: []string{"GET items"}}))
+	assert.Error(t, ValidateHttpApi(&common.HttpApiConfig{Routes: []string{"$default"}, DomainName: "api.example.com"}))
+}
+
+func TestPut(t *testing.T) {
+	fake := newFakeApiGateway()
+	wrapper := ServiceWrapper{Client: fake}
+	config := &common.HttpApiConfig{
+		Routes:         []string{"GET /orders", "POST /orders"},
+		Stage:          "v1",
+		DomainName:     "api.example.com",
+		CertificateArn: "arn:aws:acm:us-east-1:123456789012:certificate/abc",
+		BasePath:       "orders",
+	}
+
+	httpApi, err := wrapper.Put(context.TODO(), "orders", functionArn, config)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://id1.execute-api.us-east-1.amazonaws.com/v1", httpApi.Endpoint)
+	assert.Equal(t, "https://api.example.com/orders", httpApi.DomainEndpoint)
+	assert.Len(t, fake.integrations["id1"], 1)
+	assert.ElementsMatch(t, []string{"GET /orders", "POST /orders"}, routeKeys(fake.routes["id1"]))
+	assert.Equal(t, []string{"v1"}, fake.stages["id1"])
+	assert.Len(t, fake.mappings["api.example.com"], 1)
+	assert.Equal(t, []common.Permission{{
+		Principal: "apigateway.amazonaws.com",
+		SourceArn: "arn:aws:execute-api:us-east-1:123456789012:id1/*",
+	}}, Permissions(httpApi, functionArn))
+
+	// Integrations and routes are read page by page
+	fake.pageSize = 1
+	config.Routes = []string{"GET /orders", "DELETE /orders/{id}"}
+	config.Stage = "v2"
+	config.BasePath = ""
+	_, err = wrapper.Put(context.TODO(), "orders", functionArn, config)
+	assert.NoError(t, err)
+	assert.Len(t, fake.apis, 1)
+	assert.Len(t, fake.integrations["id1"], 1)
+	assert.ElementsMatch(t, []string{"GET /orders", "DELETE /orders/{id}"}, routeKeys(fake.routes["id1"]))
+	// A changed stage or base path updates the mapping
+	assert.Len(t, fake.mappings["api.example.com"], 1)
+	assert.Equal(t, "v2", *fake.mappings["api.example.com"][0].Stage)
+	assert.Equal(t, "", *fake.mappings["api.example.com"][0].ApiMappingKey)
+
+	// Without a config the API is kept
+	httpApi, err = wrapper.Put(context.TODO(), "orders", functionArn, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "id1", httpApi.ApiId)
+
+	httpApi, err = wrapper.Put(context.TODO(), "orders", functionArn, &common.HttpApiConfig{})
+	assert.NoError(t, err)
+	assert.Nil(t, httpApi)
+	assert.Empty(t, fake.apis)
+}
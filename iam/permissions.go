@@ -0,0 +1,209 @@
+package iam
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/a-pavithraa/lambda-deploy/eventsource"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/smithy-go"
+)
+
+const permissionsPolicySuffix = "_permissions"
+
+var destinationActions = map[string]string{
+	"sqs":    "sqs:SendMessage",
+	"sns":    "sns:Publish",
+	"lambda": "lambda:InvokeFunction",
+	"events": "events:PutEvents",
+}
+
+var pollerActions = map[string][]string{
+	eventsource.SourceSqs: {
+		"sqs:ReceiveMessage",
+		"sqs:DeleteMessage",
+		"sqs:GetQueueAttributes",
+	},
+	eventsource.SourceKinesis: {
+		"kinesis:GetRecords",
+		"kinesis:GetShardIterator",
+		"kinesis:DescribeStream",
+		"kinesis:DescribeStreamSummary",
+		"kinesis:ListShards",
+		"kinesis:SubscribeToShard",
+	},
+	eventsource.SourceDynamoDB: {
+		"dynamodb:GetRecords",
+		"dynamodb:GetShardIterator",
+		"dynamodb:DescribeStream",
+		"dynamodb:ListStreams",
+	},
+	eventsource.SourceKafka: {
+		"kafka:DescribeCluster",
+		"kafka:DescribeClusterV2",
+		"kafka:GetBootstrapBrokers",
+	},
+}
+
+func destinationStatement(destination string) *PolicyStatement {
+	if common.TrimAndCheckEmptyString(&destination) {
+		return nil
+	}
+	action, ok := destinationActions[common.ArnService(destination)]
+	if !ok {
+		return nil
+	}
+	return &PolicyStatement{
+		Effect:   "Allow",
+		Action:   []string{action},
+		Resource: aws.String(destination),
+	}
+}
+
+// ManagedPermissionStatements returns the statements the function needs for the features
+// configured in lambdaParams, on top of the basic execution policy.
+func ManagedPermissionStatements(lambdaParams common.DeployParams) []PolicyStatement {
+	var statements []PolicyStatement
+	// Functions mounting a file system are always in a VPC, even when vpc is left out on update
+	if lambdaParams.Vpc.Attached() || len(lambdaParams.FileSystemConfigs) > 0 {
+		statements = append(statements, PolicyStatement{
+			Effect: "Allow",
+			Action: []string{
+				"ec2:CreateNetworkInterface",
+				"ec2:DescribeNetworkInterfaces",
+				"ec2:DescribeSubnets",
+				"ec2:DeleteNetworkInterface",
+				"ec2:AssignPrivateIpAddresses",
+				"ec2:UnassignPrivateIpAddresses",
+			},
+			Resource: aws.String("*"),
+		})
+	}
+	kafkaSource := false
+	for _, source := range lambdaParams.EventSources {
+		sourceType := eventsource.SourceType(source.Arn)
+		actions, ok := pollerActions[sourceType]
+		if !ok {
+			continue
+		}
+		kafkaSource = kafkaSource || sourceType == eventsource.SourceKafka
+		statements = append(statements, PolicyStatement{
+			Effect:   "Allow",
+			Action:   actions,
+			Resource: aws.String(source.Arn),
+		})
+	}
+	if kafkaSource {
+		// MSK is polled from inside the cluster's VPC
+		statements = append(statements, PolicyStatement{
+			Effect: "Allow",
+			Action: []string{
+				"ec2:CreateNetworkInterface",
+				"ec2:DescribeNetworkInterfaces",
+				"ec2:DescribeVpcs",
+				"ec2:DeleteNetworkInterface",
+				"ec2:DescribeSubnets",
+				"ec2:DescribeSecurityGroups",
+			},
+			Resource: aws.String("*"),
+		})
+	}
+	destinations := []string{lambdaParams.DeadLetterTargetArn}
+	if lambdaParams.Async != nil {
+		destinations = append(destinations, lambdaParams.Async.OnSuccess, lambdaParams.Async.OnFailure)
+	}
+	for _, destination := range destinations {
+		if statement := destinationStatement(destination); statement != nil {
+			statements = append(statements, *statement)
+		}
+	}
+	if !common.TrimAndCheckEmptyString(&lambdaParams.KmsKeyArn) {
+		// Lambda decrypts the environment with the role of the function, as does the function for encrypted values
+		statements = append(statements, PolicyStatement{
+			Effect:   "Allow",
+			Action:   []string{"kms:Decrypt"},
+			Resource: aws.String(lambdaParams.KmsKeyArn),
+		})
+	}
+	for _, fileSystem := range lambdaParams.FileSystemConfigs {
+		// EFS authorizes the mount on the file system, so the access point is only known from the condition
+		statements = append(statements, PolicyStatement{
+			Effect: "Allow",
+			Action: []string{
+				"elasticfilesystem:ClientMount",
+				"elasticfilesystem:ClientWrite",
+			},
+			Resource: aws.String(strings.SplitN(fileSystem.Arn, ":access-point/", 2)[0] + ":file-system/*"),
+			Condition: map[string]map[string]string{
+				"StringEquals": {"elasticfilesystem:AccessPointArn": fileSystem.Arn},
+			},
+		})
+	}
+	if lambdaParams.Tracing != "" {
+		// With PassThrough the function still sends segments for requests sampled upstream
+		statements = append(statements, PolicyStatement{
+			Effect: "Allow",
+			Action: []string{
+				"xray:PutTraceSegments",
+				"xray:PutTelemetryRecords",
+			},
+			Resource: aws.String("*"),
+		})
+	}
+	if logGroup := lambdaParams.LogGroupName(); logGroup != "/aws/lambda/"+lambdaParams.FunctionName {
+		// The basic policy is only generated along with the role, so a log group configured later is covered here
+		statements = append(statements, PolicyStatement{
+			Effect: "Allow",
+			Action: []string{
+				"logs:CreateLogStream",
+				"logs:PutLogEvents",
+			},
+			Resource: aws.String("arn:aws:logs:*:*:log-group:" + logGroup + ":*"),
+		})
+	}
+	return statements
+}
+
+// PutManagedPermissions keeps the inline policy of a tool managed role in line with the
+// configured features. The inline policy is removed when no feature needs extra permissions.
+func (wrapper ServiceWrapper) PutManagedPermissions(ctx context.Context, lambdaParams common.DeployParams) error {
+	roleName := lambdaParams.FunctionName
+	policyName := roleName + permissionsPolicySuffix
+	statements := ManagedPermissionStatements(lambdaParams)
+	if len(statements) == 0 {
+		_, err := wrapper.Client.DeleteRolePolicy(ctx, &iam.DeleteRolePolicyInput{
+			PolicyName: aws.String(policyName),
+			RoleName:   aws.String(roleName),
+		})
+		var apiErr smithy.APIError
+		if err != nil && errors.As(err, &apiErr) {
+			if _, ok := apiErr.(*types.NoSuchEntityException); ok {
+				return nil
+			}
+		}
+		return err
+	}
+	policyBytes, err := json.Marshal(PolicyDocument{
+		Version:   "2012-10-17",
+		Statement: statements,
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("Updating permissions policy of role %v\n", roleName)
+	_, err = wrapper.Client.PutRolePolicy(ctx, &iam.PutRolePolicyInput{
+		PolicyDocument: aws.String(string(policyBytes)),
+		PolicyName:     aws.String(policyName),
+		RoleName:       aws.String(roleName),
+	})
+	if err != nil {
+		log.Printf("Couldn't put policy %v on role %v. Here's why: %v\n", policyName, roleName, err)
+	}
+	return err
+}
@@ -0,0 +1,111 @@
+package iam
+
+import (
+	"context"
+	"testing"
+
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManagedPermissionStatementsVpc(t *testing.T) {
+	lambdaParams := common.DeployParams{FunctionName: "test"}
+	assert.Empty(t, ManagedPermissionStatements(lambdaParams))
+
+	lambdaParams.Vpc = &common.VpcConfig{}
+	assert.Empty(t, ManagedPermissionStatements(lambdaParams))
+
+	lambdaParams.Vpc = &common.VpcConfig{SubnetIds: []string{"subnet-1"}, SecurityGroupIds: []string{"sg-1"}}
+	statements := ManagedPermissionStatements(lambdaParams)
+	assert.Len(t, statements, 1)
+	assert.Contains(t, statements[0].Action, "ec2:CreateNetworkInterface")
+}
+
+func TestPutManagedPermissions(t *testing.T) {
+	mock := &mockIAMClient{}
+	wrapper := ServiceWrapper{Client: mock}
+	lambdaParams := common.DeployParams{
+		FunctionName: "test",
+		Vpc:          &common.VpcConfig{SubnetIds: []string{"subnet-1"}, SecurityGroupIds: []string{"sg-1"}},
+	}
+	assert.NoError(t, wrapper.PutManagedPermissions(context.TODO(), lambdaParams))
+	assert.Contains(t, mock.rolePolicies["test_permissions"], "ec2:DeleteNetworkInterface")
+
+	lambdaParams.Vpc = &common.VpcConfig{}
+	assert.NoError(t, wrapper.PutManagedPermissions(context.TODO(), lambdaParams))
+	assert.Empty(t, mock.rolePolicies)
+	assert.Equal(t, []string{"test_permissions"}, mock.deletedPolicies)
+
+	// Removing an inline policy that doesn't exist is not an error
+	assert.NoError(t, wrapper.PutManagedPermissions(context.TODO(), lambdaParams))
+}
+
+func TestManagedPermissionStatementsDestinations(t *testing.T) {
+	lambdaParams := common.DeployParams{
+		FunctionName:        "test",
+		DeadLetterTargetArn: "arn:aws:sqs:us-east-1:123456789012:dlq",
+		Async: &common.AsyncConfig{
+			OnSuccess: "arn:aws:events:us-east-1:123456789012:event-bus/default",
+			OnFailure: "arn:aws:sns:us-east-1:123456789012:failures",
+		},
+	}
+	statements := ManagedPermissionStatements(lambdaParams)
+	assert.Len(t, statements, 3)
+	assert.Equal(t, []string{"sqs:SendMessage"}, statements[0].Action)
+	assert.Equal(t, "arn:aws:sqs:us-east-1:123456789012:dlq", *statements[0].Resource)
+	assert.Equal(t, []string{"events:PutEvents"}, statements[1].Action)
+	assert.Equal(t, []string{"sns:Publish"}, statements[2].Action)
+}
+
+func TestManagedPermissionStatementsEventSources(t *testing.T) {
+	lambdaParams := common.DeployParams{
+		FunctionName: "test",
+		EventSources: []common.EventSource{
+			{Arn: "arn:aws:sqs:us-east-1:123456789012:orders"},
+			{Arn: "arn:aws:kafka:us-east-1:123456789012:cluster/events/abc", Topics: []string{"orders"}},
+		},
+	}
+	statements := ManagedPermissionStatements(lambdaParams)
+	assert.Len(t, statements, 3)
+	assert.Contains(t, statements[0].Action, "sqs:ReceiveMessage")
+	assert.Contains(t, statements[1].Action, "kafka:GetBootstrapBrokers")
+	assert.Contains(t, statements[2].Action, "ec2:CreateNetworkInterface")
+}
+
+func TestManagedPermissionStatementsKms(t *testing.T) {
+	keyArn := "arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+	statements := ManagedPermissionStatements(common.DeployParams{FunctionName: "test", KmsKeyArn: keyArn})
+	assert.Len(t, statements, 1)
+	assert.Equal(t, []string{"kms:Decrypt"}, statements[0].Action)
+	assert.Equal(t, keyArn, *statements[0].Resource)
+}
+
+func TestManagedPermissionStatementsObservability(t *testing.T) {
+	lambdaParams := common.DeployParams{
+		FunctionName: "test",
+		Tracing:      "Active",
+		Logging:      &common.LoggingConfig{Format: "JSON"},
+	}
+	statements := ManagedPermissionStatements(lambdaParams)
+	assert.Len(t, statements, 1)
+	assert.Equal(t, []string{"xray:PutTraceSegments", "xray:PutTelemetryRecords"}, statements[0].Action)
+
+	lambdaParams.Logging.LogGroup = "/shared/orders"
+	statements = ManagedPermissionStatements(lambdaParams)
+	assert.Len(t, statements, 2)
+	assert.Equal(t, "arn:aws:logs:*:*:log-group:/shared/orders:*", *statements[1].Resource)
+}
+
+func TestManagedPermissionStatementsFileSystems(t *testing.T) {
+	accessPoint := "arn:aws:elasticfilesystem:us-east-1:123456789012:access-point/fsap-0123456789abcdef0"
+	lambdaParams := common.DeployParams{
+		FunctionName:      "test",
+		FileSystemConfigs: []common.FileSystemConfig{{Arn: accessPoint, LocalMountPath: "/mnt/data"}},
+	}
+	statements := ManagedPermissionStatements(lambdaParams)
+	assert.Len(t, statements, 2)
+	assert.Contains(t, statements[0].Action, "ec2:CreateNetworkInterface")
+	assert.Equal(t, []string{"elasticfilesystem:ClientMount", "elasticfilesystem:ClientWrite"}, statements[1].Action)
+	assert.Equal(t, "arn:aws:elasticfilesystem:us-east-1:123456789012:file-system/*", *statements[1].Resource)
+	assert.Equal(t, accessPoint, statements[1].Condition["StringEquals"]["elasticfilesystem:AccessPointArn"])
+}
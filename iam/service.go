@@ -6,6 +6,7 @@ import (
 	"github.com/a-pavithraa/lambda-deploy/common"
 
 	"log"
+	"sort"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -21,6 +22,11 @@ type Api interface {
 	AttachRolePolicy(ctx context.Context, params *iam.AttachRolePolicyInput, optFns ...func(*iam.Options)) (*iam.AttachRolePolicyOutput, error)
 	CreateRole(ctx context.Context, params *iam.CreateRoleInput, optFns ...func(*iam.Options)) (*iam.CreateRoleOutput, error)
 	ListAttachedRolePolicies(ctx context.Context, params *iam.ListAttachedRolePoliciesInput, optFns ...func(*iam.Options)) (*iam.ListAttachedRolePoliciesOutput, error)
+	PutRolePolicy(ctx context.Context, params *iam.PutRolePolicyInput, optFns ...func(*iam.Options)) (*iam.PutRolePolicyOutput, error)
+	DeleteRolePolicy(ctx context.Context, params *iam.DeleteRolePolicyInput, optFns ...func(*iam.Options)) (*iam.DeleteRolePolicyOutput, error)
+	TagRole(ctx context.Context, params *iam.TagRoleInput, optFns ...func(*iam.Options)) (*iam.TagRoleOutput, error)
+	UntagRole(ctx context.Context, params *iam.UntagRoleInput, optFns ...func(*iam.Options)) (*iam.UntagRoleOutput, error)
+	ListRoleTags(ctx context.Context, params *iam.ListRoleTagsInput, optFns ...func(*iam.Options)) (*iam.ListRoleTagsOutput, error)
 }
 type ServiceWrapper struct {
 	Client Api
@@ -34,8 +40,19 @@ type PolicyDocument struct {
 type PolicyStatement struct {
 	Effect    string
 	Action    []string
-	Principal map[string]string `json:",omitempty"`
-	Resource  *string           `json:",omitempty"`
+	Principal map[string]string            `json:",omitempty"`
+	Resource  *string                      `json:",omitempty"`
+	Condition map[string]map[string]string `json:",omitempty"`
+}
+
+// tagList converts the tags into the list IAM expects, sorted so requests are stable
+func tagList(tags map[string]string) []types.Tag {
+	var result []types.Tag
+	for key, value := range tags {
+		result = append(result, types.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+	sort.Slice(result, func(i, j int) bool { return *result[i].Key < *result[j].Key })
+	return result
 }
 
 func validatePolicy(lambdaExecutionRolePolicy string) error {
@@ -67,10 +84,11 @@ func (wrapper ServiceWrapper) CheckRoleExists(ctx context.Context, roleName stri
 
 	return role.Arn
 }
-func (wrapper ServiceWrapper) CreatePolicy(ctx context.Context, policyDocument string, policyName string) (*types.Policy, error) {
+func (wrapper ServiceWrapper) CreatePolicy(ctx context.Context, policyDocument string, policyName string, tags map[string]string) (*types.Policy, error) {
 	result, err := wrapper.Client.CreatePolicy(ctx, &iam.CreatePolicyInput{
 		PolicyDocument: aws.String(policyDocument),
 		PolicyName:     aws.String(policyName),
+		Tags:           tagList(tags),
 	})
 	if err != nil {
 		log.Printf("Couldn't create policy %v. Here's why: %v\n", policyName, err)
@@ -94,7 +112,7 @@ func (wrapper ServiceWrapper) AttachRolePolicy(ctx context.Context, policyArn st
 	return err
 }
 
-func (wrapper ServiceWrapper) NewRole(ctx context.Context, roleName string, trustPolicy PolicyDocument) (*types.Role, error) {
+func (wrapper ServiceWrapper) NewRole(ctx context.Context, roleName string, trustPolicy PolicyDocument, tags map[string]string) (*types.Role, error) {
 	var role *types.Role
 
 	policyBytes, err := json.Marshal(trustPolicy)
@@ -106,6 +124,7 @@ func (wrapper ServiceWrapper) NewRole(ctx context.Context, roleName string, trus
 		AssumeRolePolicyDocument: aws.String(string(policyBytes)),
 
 		RoleName: aws.String(roleName),
+		Tags:     tagList(tags),
 	})
 
 	if err != nil {
@@ -115,6 +134,51 @@ func (wrapper ServiceWrapper) NewRole(ctx context.Context, roleName string, trus
 	}
 	return role, err
 }
+
+// ReconcileRoleTags brings the tags of an existing role in line with the config, the same way as
+// the tags of the function
+func (wrapper ServiceWrapper) ReconcileRoleTags(ctx context.Context, lambdaParams common.DeployParams) error {
+	roleName := lambdaParams.FunctionName
+	current := map[string]string{}
+	listInput := &iam.ListRoleTagsInput{RoleName: aws.String(roleName)}
+	for {
+		output, err := wrapper.Client.ListRoleTags(ctx, listInput)
+		if err != nil {
+			log.Printf("Couldn't list the tags of role %v. Here's why: %v\n", roleName, err)
+			return err
+		}
+		for _, tag := range output.Tags {
+			current[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+		if !output.IsTruncated {
+			break
+		}
+		listInput.Marker = output.Marker
+	}
+	added, removed := lambdaParams.TagChanges(current)
+	if len(removed) > 0 {
+		log.Println("Removing role tags", removed)
+		_, err := wrapper.Client.UntagRole(ctx, &iam.UntagRoleInput{
+			RoleName: aws.String(roleName),
+			TagKeys:  removed,
+		})
+		if err != nil {
+			log.Printf("Couldn't remove the tags of role %v. Here's why: %v\n", roleName, err)
+			return err
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	_, err := wrapper.Client.TagRole(ctx, &iam.TagRoleInput{
+		RoleName: aws.String(roleName),
+		Tags:     tagList(added),
+	})
+	if err != nil {
+		log.Printf("Couldn't tag role %v. Here's why: %v\n", roleName, err)
+	}
+	return err
+}
 func (wrapper ServiceWrapper) ListAttachedRolePolicies(ctx context.Context, roleName string) ([]types.AttachedPolicy, error) {
 	var policies []types.AttachedPolicy
 	result, err := wrapper.Client.ListAttachedRolePolicies(ctx, &iam.ListAttachedRolePoliciesInput{
@@ -128,9 +192,9 @@ func (wrapper ServiceWrapper) ListAttachedRolePolicies(ctx context.Context, role
 	}
 	return policies, err
 }
-func (wrapper ServiceWrapper) SetupPolicesAndAttachPolicy(ctx context.Context, roleName string, lambdaExecutionRolePolicy string) error {
+func (wrapper ServiceWrapper) SetupPolicesAndAttachPolicy(ctx context.Context, roleName string, lambdaExecutionRolePolicy string, tags map[string]string) error {
 
-	policy, err := wrapper.CreatePolicy(ctx, strings.TrimSpace(lambdaExecutionRolePolicy), roleName+"_policy")
+	policy, err := wrapper.CreatePolicy(ctx, strings.TrimSpace(lambdaExecutionRolePolicy), roleName+"_policy", tags)
 	if err != nil {
 		return err
 	}
@@ -159,13 +223,15 @@ func (wrapper ServiceWrapper) CreateRole(ctx context.Context, lambdaParams commo
 	roleArn := wrapper.CheckRoleExists(ctx, lambdaParams.FunctionName)
 	if roleArn == nil {
 
-		role, err := wrapper.NewRole(ctx, lambdaParams.FunctionName, trustPolicy)
+		role, err := wrapper.NewRole(ctx, lambdaParams.FunctionName, trustPolicy, lambdaParams.ResourceTags())
 		if err != nil {
 			log.Println(err)
 			return nil, err
 		}
 
 		roleArn = role.Arn
+	} else if err := wrapper.ReconcileRoleTags(ctx, lambdaParams); err != nil {
+		return nil, err
 	}
 
 	accountId := strings.Split(*roleArn, ":")[4]
@@ -178,13 +244,13 @@ func (wrapper ServiceWrapper) CreateRole(ctx context.Context, lambdaParams commo
 	// To overwrite or not to overwrite the existing policy - going with not to overwrite
 	if len(policies) == 0 {
 		if lambdaParams.AutogenerateExecutionPolicy {
-			err = AutoGenerateBasicPolicy(ctx, lambdaParams.FunctionName, accountId, wrapper)
+			err = AutoGenerateBasicPolicy(ctx, lambdaParams.FunctionName, lambdaParams.LogGroupName(), accountId, wrapper, lambdaParams.ResourceTags())
 			if err != nil {
 				return nil, err
 			}
 		}
 		if strings.TrimSpace(lambdaParams.Policy) != "" {
-			err := wrapper.SetupPolicesAndAttachPolicy(ctx, lambdaParams.FunctionName, lambdaParams.Policy)
+			err := wrapper.SetupPolicesAndAttachPolicy(ctx, lambdaParams.FunctionName, lambdaParams.Policy, lambdaParams.ResourceTags())
 			if err != nil {
 
 				log.Println(err)
@@ -193,11 +259,16 @@ func (wrapper ServiceWrapper) CreateRole(ctx context.Context, lambdaParams commo
 		}
 
 	}
+	if lambdaParams.AutogenerateExecutionPolicy {
+		if err = wrapper.PutManagedPermissions(ctx, lambdaParams); err != nil {
+			return nil, err
+		}
+	}
 	return roleArn, nil
 
 }
 
-func AutoGenerateBasicPolicy(ctx context.Context, name string, accountId string, wrapper ServiceWrapper) error {
+func AutoGenerateBasicPolicy(ctx context.Context, name string, logGroup string, accountId string, wrapper ServiceWrapper, tags map[string]string) error {
 	lambdaExecutionRolePolicy := `
 	{
 		"Version": "2012-10-17",
@@ -213,13 +284,13 @@ func AutoGenerateBasicPolicy(ctx context.Context, name string, accountId string,
 					"logs:CreateLogStream",
 					"logs:PutLogEvents"
 				],
-				"Resource": ["arn:aws:logs:us-east-1:` + accountId + `:log-group:/aws/lambda/` + name + `:*"]
+				"Resource": ["arn:aws:logs:us-east-1:` + accountId + `:log-group:` + logGroup + `:*"]
 				
 			}
 		]
 	}
 `
-	err := wrapper.SetupPolicesAndAttachPolicy(ctx, name, lambdaExecutionRolePolicy)
+	err := wrapper.SetupPolicesAndAttachPolicy(ctx, name, lambdaExecutionRolePolicy, tags)
 	if err != nil {
 
 		log.Println(err)
@@ -2,7 +2,9 @@ package iam
 
 import (
 	"context"
+	"github.com/a-pavithraa/lambda-deploy/common"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"slices"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -12,7 +14,11 @@ import (
 )
 
 type mockIAMClient struct {
-	Client Api
+	Client          Api
+	rolePolicies    map[string]string
+	deletedPolicies []string
+	roleTags        []types.Tag
+	policyTags      []types.Tag
 }
 
 func (m *mockIAMClient) DeleteRole(ctx context.Context, input *iam.DeleteRoleInput, optFns ...func(*iam.Options)) (*iam.DeleteRoleOutput, error) {
@@ -28,6 +34,7 @@ func (m *mockIAMClient) GetRole(ctx context.Context, input *iam.GetRoleInput, op
 }
 
 func (m *mockIAMClient) CreatePolicy(ctx context.Context, input *iam.CreatePolicyInput, optFns ...func(*iam.Options)) (*iam.CreatePolicyOutput, error) {
+	m.policyTags = input.Tags
 	return &iam.CreatePolicyOutput{
 		Policy: &types.Policy{
 			Arn: aws.String("arn:aws:iam::123456789012:policy/test"),
@@ -40,6 +47,7 @@ func (m *mockIAMClient) AttachRolePolicy(ctx context.Context, input *iam.AttachR
 }
 
 func (m *mockIAMClient) CreateRole(ctx context.Context, input *iam.CreateRoleInput, optFns ...func(*iam.Options)) (*iam.CreateRoleOutput, error) {
+	m.roleTags = input.Tags
 	return &iam.CreateRoleOutput{
 		Role: &types.Role{
 			Arn: aws.String("arn:aws:iam::123456789012:role/test"),
@@ -51,6 +59,47 @@ func (m *mockIAMClient) ListAttachedRolePolicies(ctx context.Context, input *iam
 	return &iam.ListAttachedRolePoliciesOutput{}, nil
 }
 
+func (m *mockIAMClient) PutRolePolicy(ctx context.Context, input *iam.PutRolePolicyInput, optFns ...func(*iam.Options)) (*iam.PutRolePolicyOutput, error) {
+	if m.rolePolicies == nil {
+		m.rolePolicies = map[string]string{}
+	}
+	m.rolePolicies[*input.PolicyName] = *input.PolicyDocument
+	return &iam.PutRolePolicyOutput{}, nil
+}
+
+func (m *mockIAMClient) DeleteRolePolicy(ctx context.Context, input *iam.DeleteRolePolicyInput, optFns ...func(*iam.Options)) (*iam.DeleteRolePolicyOutput, error) {
+	if _, ok := m.rolePolicies[*input.PolicyName]; !ok {
+		return nil, &types.NoSuchEntityException{}
+	}
+	delete(m.rolePolicies, *input.PolicyName)
+	m.deletedPolicies = append(m.deletedPolicies, *input.PolicyName)
+	return &iam.DeleteRolePolicyOutput{}, nil
+}
+
+func (m *mockIAMClient) TagRole(ctx context.Context, input *iam.TagRoleInput, optFns ...func(*iam.Options)) (*iam.TagRoleOutput, error) {
+	tags := map[string]string{}
+	for _, tag := range append(m.roleTags, input.Tags...) {
+		tags[*tag.Key] = *tag.Value
+	}
+	m.roleTags = tagList(tags)
+	return &iam.TagRoleOutput{}, nil
+}
+
+func (m *mockIAMClient) UntagRole(ctx context.Context, input *iam.UntagRoleInput, optFns ...func(*iam.Options)) (*iam.UntagRoleOutput, error) {
+	var tags []types.Tag
+	for _, tag := range m.roleTags {
+		if !slices.Contains(input.TagKeys, *tag.Key) {
+			tags = append(tags, tag)
+		}
+	}
+	m.roleTags = tags
+	return &iam.UntagRoleOutput{}, nil
+}
+
+func (m *mockIAMClient) ListRoleTags(ctx context.Context, input *iam.ListRoleTagsInput, optFns ...func(*iam.Options)) (*iam.ListRoleTagsOutput, error) {
+	return &iam.ListRoleTagsOutput{Tags: m.roleTags}, nil
+}
+
 func TestServiceWrapper_DeleteRole(t *testing.T) {
 	sw := ServiceWrapper{
 		Client: &mockIAMClient{},
@@ -80,7 +129,7 @@ func TestCreatePolicy(t *testing.T) {
 		  }
 		]
 	  }`
-	policy, err := wrapper.CreatePolicy(context.TODO(), policyDocument, "test_policy")
+	policy, err := wrapper.CreatePolicy(context.TODO(), policyDocument, "test_policy", nil)
 	if err != nil {
 		t.Fatalf("Failed to create policy: %v", err)
 	}
@@ -117,8 +166,33 @@ func TestNewRole(t *testing.T) {
 			},
 		},
 	}
-	_, err := wrapper.NewRole(context.TODO(), "Test", trustPolicy)
+	_, err := wrapper.NewRole(context.TODO(), "Test", trustPolicy, nil)
 	if err != nil {
 		t.Fatalf("Failed to create role: %v", err)
 	}
 }
+
+func TestCreateRoleTags(t *testing.T) {
+	mock := &mockIAMClient{}
+	wrapper := ServiceWrapper{Client: mock}
+	lambdaParams := common.DeployParams{
+		FunctionName: "orders",
+		Tags:         map[string]string{"team": "payments"},
+		ToolTags:     map[string]string{common.TagManagedBy: common.ManagedByValue},
+		Policy:       `{"Version": "2012-10-17", "Statement": []}`,
+	}
+	_, err := wrapper.CreateRole(context.TODO(), lambdaParams)
+	assert.NoError(t, err)
+	expected := []types.Tag{
+		{Key: aws.String(common.TagManagedBy), Value: aws.String(common.ManagedByValue)},
+		{Key: aws.String("team"), Value: aws.String("payments")},
+	}
+	assert.Equal(t, expected, mock.roleTags)
+	assert.Equal(t, expected, mock.policyTags)
+
+	// Tags removed from the config are removed from the role as well
+	lambdaParams.Tags = nil
+	_, err = wrapper.CreateRole(context.TODO(), lambdaParams)
+	assert.NoError(t, err)
+	assert.Equal(t, expected[:1], mock.roleTags)
+}
@@ -0,0 +1,34 @@
+package kms
+
+import (
+	"context"
+	"encoding/base64"
+	"log"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+// Key of the encryption context the Lambda console helpers use. The function has to pass the same
+// context, with its own name, when it decrypts the value.
+const EncryptionContextKey = "LambdaFunctionName"
+
+func Client(ctx context.Context) *kms.Client {
+	cfg, _ := config.LoadDefaultConfig(ctx)
+	return kms.NewFromConfig(cfg)
+}
+
+// Encrypt encrypts the value for the function with the key and returns the base64 ciphertext
+func (wrapper ServiceWrapper) Encrypt(ctx context.Context, keyArn string, functionName string, plaintext []byte) (string, error) {
+	output, err := wrapper.Client.Encrypt(ctx, &kms.EncryptInput{
+		KeyId:             aws.String(keyArn),
+		Plaintext:         plaintext,
+		EncryptionContext: map[string]string{EncryptionContextKey: functionName},
+	})
+	if err != nil {
+		log.Printf("Not able to encrypt the value. The reason is %s", err.Error())
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(output.CiphertextBlob), nil
+}
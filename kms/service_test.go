@@ -0,0 +1,31 @@
+package kms
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockKmsApi struct {
+	input *kms.EncryptInput
+}
+
+func (m *mockKmsApi) Encrypt(ctx context.Context, params *kms.EncryptInput, optFns ...func(*kms.Options)) (*kms.EncryptOutput, error) {
+	m.input = params
+	return &kms.EncryptOutput{CiphertextBlob: append([]byte("cipher:"), params.Plaintext...)}, nil
+}
+
+func TestEncrypt(t *testing.T) {
+	mock := &mockKmsApi{}
+	wrapper := ServiceWrapper{Client: mock}
+	keyArn := "arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+
+	ciphertext, err := wrapper.Encrypt(context.Background(), keyArn, "orders", []byte("s3cr3t"))
+	assert.NoError(t, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("cipher:s3cr3t")), ciphertext)
+	assert.Equal(t, keyArn, *mock.input.KeyId)
+	assert.Equal(t, map[string]string{EncryptionContextKey: "orders"}, mock.input.EncryptionContext)
+}
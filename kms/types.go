@@ -0,0 +1,13 @@
+package kms
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+type Api interface {
+	Encrypt(ctx context.Context, params *kms.EncryptInput, optFns ...func(*kms.Options)) (*kms.EncryptOutput, error)
+}
+type ServiceWrapper struct {
+	Client Api
+}
@@ -0,0 +1,89 @@
+package lambda
+
+import (
+	"archive/zip"
+	"bytes"
+	"debug/elf"
+	"fmt"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"io"
+	"path"
+)
+
+var elfMachines = map[elf.Machine]types.Architecture{
+	elf.EM_X86_64:  types.ArchitectureX8664,
+	elf.EM_AARCH64: types.ArchitectureArm64,
+}
+
+// CheckBootstrapArchitecture inspects the ELF header of the bootstrap binary in the zip
+// and refuses a binary built for a different architecture than the function.
+// Zips without a bootstrap, or with a bootstrap that isn't an ELF binary, are not checked.
+func CheckBootstrapArchitecture(contents []byte, architectures []string) error {
+	architecture := string(types.ArchitectureX8664)
+	if len(architectures) > 0 {
+		architecture = architectures[0]
+	}
+	zipReader, err := zip.NewReader(bytes.NewReader(contents), int64(len(contents)))
+	if err != nil {
+		return err
+	}
+	for _, file := range zipReader.File {
+		if path.Base(file.Name) != "bootstrap" || file.FileInfo().IsDir() {
+			continue
+		}
+		binaryArchitecture, err := bootstrapArchitecture(file)
+		if err != nil {
+			return err
+		}
+		if binaryArchitecture != "" && string(binaryArchitecture) != architecture {
+			return &common.InputError{
+				Message: fmt.Sprintf("%s is built for %s but the function architecture is %s", file.Name, binaryArchitecture, architecture),
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+func bootstrapArchitecture(file *zip.File) (types.Architecture, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	binary, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	elfFile, err := elf.NewFile(bytes.NewReader(binary))
+	if err != nil {
+		// Not an ELF binary, e.g. a shell script bootstrap
+		return "", nil
+	}
+	return elfMachines[elfFile.Machine], nil
+}
+
+// ArchitectureChanged reports whether the requested architecture differs from the deployed one.
+// An empty request keeps the deployed architecture.
+func ArchitectureChanged(current []types.Architecture, requested []string) bool {
+	if len(requested) == 0 {
+		return false
+	}
+	if len(current) == 0 {
+		return requested[0] != string(types.ArchitectureX8664)
+	}
+	return string(current[0]) != requested[0]
+}
+
+// CheckArchitectureChange refuses to move an existing function to another architecture unless the
+// deploy confirms the code was rebuilt for it. Only Go bootstrap binaries in a zip can be checked,
+// any other code would fail when it is invoked.
+func CheckArchitectureChange(current []types.Architecture, lambdaParams common.DeployParams) error {
+	if !ArchitectureChanged(current, lambdaParams.Architectures) || lambdaParams.ArchitectureRebuilt {
+		return nil
+	}
+	return &common.InputError{
+		Message: fmt.Sprintf("Architecture changes from %v to %s. Rebuild the code for %s and deploy with --architecture_rebuilt.\n", current, lambdaParams.Architectures[0], lambdaParams.Architectures[0]),
+	}
+}
@@ -0,0 +1,64 @@
+package lambda
+
+import (
+	"archive/zip"
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func elfHeader(machine elf.Machine) []byte {
+	header := make([]byte, 64)
+	copy(header, elf.ELFMAG)
+	header[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	header[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	header[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+	binary.LittleEndian.PutUint16(header[16:], uint16(elf.ET_EXEC))
+	binary.LittleEndian.PutUint16(header[18:], uint16(machine))
+	binary.LittleEndian.PutUint32(header[20:], uint32(elf.EV_CURRENT))
+	return header
+}
+
+func zipWithBootstrap(t *testing.T, contents []byte) []byte {
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	file, err := writer.Create("bootstrap")
+	assert.NoError(t, err)
+	_, err = file.Write(contents)
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+	return buffer.Bytes()
+}
+
+func TestCheckBootstrapArchitecture(t *testing.T) {
+	amd64Zip := zipWithBootstrap(t, elfHeader(elf.EM_X86_64))
+	arm64Zip := zipWithBootstrap(t, elfHeader(elf.EM_AARCH64))
+
+	assert.NoError(t, CheckBootstrapArchitecture(amd64Zip, nil))
+	assert.NoError(t, CheckBootstrapArchitecture(arm64Zip, []string{"arm64"}))
+	assert.Error(t, CheckBootstrapArchitecture(amd64Zip, []string{"arm64"}))
+	assert.Error(t, CheckBootstrapArchitecture(arm64Zip, []string{"x86_64"}))
+}
+
+func TestCheckBootstrapArchitectureScript(t *testing.T) {
+	scriptZip := zipWithBootstrap(t, []byte("#!/bin/sh\nexec ./app\n"))
+	assert.NoError(t, CheckBootstrapArchitecture(scriptZip, []string{"arm64"}))
+}
+
+func TestArchitectureChanged(t *testing.T) {
+	assert.False(t, ArchitectureChanged([]types.Architecture{types.ArchitectureArm64}, nil))
+	assert.False(t, ArchitectureChanged(nil, []string{"x86_64"}))
+	assert.True(t, ArchitectureChanged(nil, []string{"arm64"}))
+	assert.True(t, ArchitectureChanged([]types.Architecture{types.ArchitectureArm64}, []string{"x86_64"}))
+}
+
+func TestCheckArchitectureChange(t *testing.T) {
+	current := []types.Architecture{types.ArchitectureX8664}
+	assert.NoError(t, CheckArchitectureChange(current, common.DeployParams{Architectures: []string{"x86_64"}}))
+	assert.Error(t, CheckArchitectureChange(current, common.DeployParams{Architectures: []string{"arm64"}}))
+	assert.NoError(t, CheckArchitectureChange(current, common.DeployParams{Architectures: []string{"arm64"}, ArchitectureRebuilt: true}))
+}
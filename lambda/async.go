@@ -0,0 +1,88 @@
+package lambda
+
+import (
+	"context"
+	"errors"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/smithy-go"
+)
+
+var destinationServices = []string{"sqs", "sns", "lambda", "events"}
+
+func validateAsyncParams(lambdaParams common.DeployParams, errorMessage *strings.Builder) {
+	if !common.TrimAndCheckEmptyString(&lambdaParams.DeadLetterTargetArn) {
+		service := common.ArnService(lambdaParams.DeadLetterTargetArn)
+		if service != "sqs" && service != "sns" {
+			errorMessage.WriteString("Dead Letter Target ARN must be an SQS queue or SNS topic.\n")
+		}
+	}
+	async := lambdaParams.Async
+	if async == nil {
+		return
+	}
+	if async.MaximumRetryAttempts != nil && (*async.MaximumRetryAttempts < 0 || *async.MaximumRetryAttempts > 2) {
+		errorMessage.WriteString("Maximum Retry Attempts must be between 0 and 2.\n")
+	}
+	if async.MaximumEventAgeSeconds != nil && (*async.MaximumEventAgeSeconds < 60 || *async.MaximumEventAgeSeconds > 21600) {
+		errorMessage.WriteString("Maximum Event Age must be between 60 and 21600 seconds.\n")
+	}
+	for _, destination := range []string{async.OnSuccess, async.OnFailure} {
+		if common.TrimAndCheckEmptyString(&destination) {
+			continue
+		}
+		if !slices.Contains(destinationServices, common.ArnService(destination)) {
+			errorMessage.WriteString("Destination " + destination + " must be an SQS queue, SNS topic, Lambda function or EventBridge event bus.\n")
+		}
+	}
+}
+
+// PutEventInvokeConfig sets retries, event age and destinations of asynchronous invocations. Without
+// an async block the config is deleted, so Lambda falls back to its defaults.
+func (wrapper ServiceWrapper) PutEventInvokeConfig(ctx context.Context, lambdaParams common.DeployParams) error {
+	async := lambdaParams.Async
+	if async == nil {
+		_, err := wrapper.Client.DeleteFunctionEventInvokeConfig(ctx, &lambda.DeleteFunctionEventInvokeConfigInput{
+			FunctionName: &lambdaParams.FunctionName,
+		})
+		var apiErr smithy.APIError
+		if err != nil && errors.As(err, &apiErr) {
+			if _, ok := apiErr.(*types.ResourceNotFoundException); ok {
+				return nil
+			}
+		}
+		if err != nil {
+			log.Printf("Not able to delete the asynchronous invocation configuration. The reason is %s", err.Error())
+		}
+		return err
+	}
+	log.Println("Updating asynchronous invocation configuration-----")
+	configInput := &lambda.PutFunctionEventInvokeConfigInput{
+		FunctionName:      &lambdaParams.FunctionName,
+		DestinationConfig: &types.DestinationConfig{},
+	}
+	if async.MaximumRetryAttempts != nil {
+		retryAttempts := int32(*async.MaximumRetryAttempts)
+		configInput.MaximumRetryAttempts = &retryAttempts
+	}
+	if async.MaximumEventAgeSeconds != nil {
+		eventAge := int32(*async.MaximumEventAgeSeconds)
+		configInput.MaximumEventAgeInSeconds = &eventAge
+	}
+	if !common.TrimAndCheckEmptyString(&async.OnSuccess) {
+		configInput.DestinationConfig.OnSuccess = &types.OnSuccess{Destination: &async.OnSuccess}
+	}
+	if !common.TrimAndCheckEmptyString(&async.OnFailure) {
+		configInput.DestinationConfig.OnFailure = &types.OnFailure{Destination: &async.OnFailure}
+	}
+	_, err := wrapper.Client.PutFunctionEventInvokeConfig(ctx, configInput)
+	if err != nil {
+		log.Printf("Not able to update the asynchronous invocation configuration. The reason is %s", err.Error())
+	}
+	return err
+}
@@ -0,0 +1,78 @@
+package lambda
+
+import (
+	"context"
+	"testing"
+
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func validZipParams() common.DeployParams {
+	return common.DeployParams{
+		FunctionName: "test-function",
+		ZipFile:      "test.zip",
+		HandlerName:  "handler",
+		Runtime:      "go",
+	}
+}
+
+func TestValidateAsyncParams(t *testing.T) {
+	retryAttempts := 2
+	lambdaParams := validZipParams()
+	lambdaParams.DeadLetterTargetArn = "arn:aws:sqs:us-east-1:123456789012:dlq"
+	lambdaParams.Async = &common.AsyncConfig{
+		MaximumRetryAttempts: &retryAttempts,
+		OnFailure:            "arn:aws:lambda:us-east-1:123456789012:function:failures",
+	}
+	assert.NoError(t, ValidateInputParams(lambdaParams, true))
+
+	retryAttempts = 3
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+
+	retryAttempts = 0
+	lambdaParams.DeadLetterTargetArn = "arn:aws:lambda:us-east-1:123456789012:function:dlq"
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.DeadLetterTargetArn = ""
+	lambdaParams.Async.OnSuccess = "arn:aws:dynamodb:us-east-1:123456789012:table/results"
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+}
+
+func TestUpdateFunctionConfigurationDeadLetter(t *testing.T) {
+	mock := &mockFunctionApi{}
+	wrapper := ServiceWrapper{Client: mock}
+	lambdaParams := common.DeployParams{FunctionName: "test", DeadLetterTargetArn: "arn:aws:sqs:us-east-1:123456789012:dlq"}
+	assert.NoError(t, wrapper.UpdateFunctionConfiguration(context.TODO(), lambdaParams))
+	assert.Equal(t, lambdaParams.DeadLetterTargetArn, *mock.configInput.DeadLetterConfig.TargetArn)
+
+	// Removing the dead letter queue detaches it
+	lambdaParams.DeadLetterTargetArn = ""
+	assert.NoError(t, wrapper.UpdateFunctionConfiguration(context.TODO(), lambdaParams))
+	assert.Equal(t, "", *mock.configInput.DeadLetterConfig.TargetArn)
+}
+
+func TestPutEventInvokeConfig(t *testing.T) {
+	mock := &mockFunctionApi{}
+	wrapper := ServiceWrapper{Client: mock}
+	lambdaParams := validZipParams()
+
+	assert.NoError(t, wrapper.PutEventInvokeConfig(context.TODO(), lambdaParams))
+	assert.Nil(t, mock.eventInvokeConfig)
+
+	eventAge := 3600
+	lambdaParams.Async = &common.AsyncConfig{
+		MaximumEventAgeSeconds: &eventAge,
+		OnSuccess:              "arn:aws:sqs:us-east-1:123456789012:results",
+	}
+	assert.NoError(t, wrapper.PutEventInvokeConfig(context.TODO(), lambdaParams))
+	assert.Equal(t, int32(3600), *mock.eventInvokeConfig.MaximumEventAgeInSeconds)
+	assert.Nil(t, mock.eventInvokeConfig.MaximumRetryAttempts)
+	assert.Equal(t, "arn:aws:sqs:us-east-1:123456789012:results", *mock.eventInvokeConfig.DestinationConfig.OnSuccess.Destination)
+	assert.Nil(t, mock.eventInvokeConfig.DestinationConfig.OnFailure)
+
+	// Removing the async block deletes the config
+	lambdaParams.Async = nil
+	assert.NoError(t, wrapper.PutEventInvokeConfig(context.TODO(), lambdaParams))
+	assert.Nil(t, mock.eventInvokeConfig)
+}
@@ -0,0 +1,207 @@
+package lambda
+
+import (
+	"context"
+	"fmt"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+// Lambda keeps this much of the account concurrency unreserved
+const minimumUnreservedConcurrency = 100
+
+var (
+	provisionedConcurrencyPollInterval = 5 * time.Second
+	provisionedConcurrencyMaxWait      = 15 * time.Minute
+)
+
+func validateConcurrency(lambdaParams common.DeployParams, errorMessage *strings.Builder) {
+	reserved := lambdaParams.ReservedConcurrency
+	if reserved != nil && *reserved < 0 {
+		errorMessage.WriteString("Reserved Concurrency cannot be negative.\n")
+	}
+	provisioned := lambdaParams.ProvisionedConcurrency
+	if provisioned == nil {
+		return
+	}
+	if common.TrimAndCheckEmptyString(&provisioned.Qualifier) || provisioned.Qualifier == "$LATEST" {
+		errorMessage.WriteString("Provisioned Concurrency needs the qualifier of a published version or alias.\n")
+	}
+	if provisioned.Count < 1 {
+		errorMessage.WriteString("Provisioned Concurrency count must be at least 1.\n")
+	}
+	if reserved != nil && peakProvisioned(provisioned) > *reserved {
+		errorMessage.WriteString(fmt.Sprintf("Provisioned Concurrency %d cannot exceed Reserved Concurrency %d.\n", peakProvisioned(provisioned), *reserved))
+	}
+}
+
+// peakProvisioned is the most provisioned concurrency the config can lead to, the autoscaling max if there is one
+func peakProvisioned(provisioned *common.ProvisionedConcurrencyConfig) int {
+	if provisioned.Autoscaling != nil && provisioned.Autoscaling.Max > provisioned.Count {
+		return provisioned.Autoscaling.Max
+	}
+	return provisioned.Count
+}
+
+// provisionedConfigs returns the provisioned concurrency configs of the function keyed by qualifier
+func (wrapper ServiceWrapper) provisionedConfigs(ctx context.Context, functionName string) (map[string]types.ProvisionedConcurrencyConfigListItem, error) {
+	configs := map[string]types.ProvisionedConcurrencyConfigListItem{}
+	listInput := &lambda.ListProvisionedConcurrencyConfigsInput{FunctionName: aws.String(functionName)}
+	for {
+		output, err := wrapper.Client.ListProvisionedConcurrencyConfigs(ctx, listInput)
+		if err != nil {
+			return nil, err
+		}
+		for _, config := range output.ProvisionedConcurrencyConfigs {
+			functionArn := aws.ToString(config.FunctionArn)
+			configs[functionArn[strings.LastIndex(functionArn, ":")+1:]] = config
+		}
+		if output.NextMarker == nil {
+			return configs, nil
+		}
+		listInput.Marker = output.NextMarker
+	}
+}
+
+// PutConcurrency applies the reserved and provisioned concurrency of the function. Unset values remove
+// the settings. The account headroom is checked before anything is changed.
+func (wrapper ServiceWrapper) PutConcurrency(ctx context.Context, lambdaParams common.DeployParams) error {
+	functionName := aws.String(lambdaParams.FunctionName)
+	settings, err := wrapper.Client.GetAccountSettings(ctx, &lambda.GetAccountSettingsInput{})
+	if err != nil {
+		log.Printf("Not able to read the account settings. The reason is %s", err.Error())
+		return err
+	}
+	current, err := wrapper.Client.GetFunctionConcurrency(ctx, &lambda.GetFunctionConcurrencyInput{FunctionName: functionName})
+	if err != nil {
+		return err
+	}
+	provisionedConfigs, err := wrapper.provisionedConfigs(ctx, lambdaParams.FunctionName)
+	if err != nil {
+		return err
+	}
+	if err = checkConcurrencyHeadroom(lambdaParams, settings.AccountLimit, current.ReservedConcurrentExecutions, provisionedConfigs); err != nil {
+		return err
+	}
+
+	reserved := lambdaParams.ReservedConcurrency
+	if reserved != nil {
+		log.Printf("Reserving concurrency %d for function %s\n", *reserved, lambdaParams.FunctionName)
+		_, err = wrapper.Client.PutFunctionConcurrency(ctx, &lambda.PutFunctionConcurrencyInput{
+			FunctionName:                 functionName,
+			ReservedConcurrentExecutions: aws.Int32(int32(*reserved)),
+		})
+	} else if current.ReservedConcurrentExecutions != nil {
+		log.Println("Removing reserved concurrency of function", lambdaParams.FunctionName)
+		_, err = wrapper.Client.DeleteFunctionConcurrency(ctx, &lambda.DeleteFunctionConcurrencyInput{FunctionName: functionName})
+	}
+	if err != nil {
+		log.Printf("Not able to update the reserved concurrency. The reason is %s", err.Error())
+		return err
+	}
+
+	provisioned := lambdaParams.ProvisionedConcurrency
+	for qualifier := range provisionedConfigs {
+		if provisioned != nil && provisioned.Qualifier == qualifier {
+			continue
+		}
+		log.Printf("Removing provisioned concurrency of %s:%s\n", lambdaParams.FunctionName, qualifier)
+		_, err = wrapper.Client.DeleteProvisionedConcurrencyConfig(ctx, &lambda.DeleteProvisionedConcurrencyConfigInput{
+			FunctionName: functionName,
+			Qualifier:    aws.String(qualifier),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if provisioned == nil {
+		return nil
+	}
+	existing, found := provisionedConfigs[provisioned.Qualifier]
+	// Once autoscaling manages the count, it is not reset to the initial count on every deploy
+	if !found || (provisioned.Autoscaling == nil && aws.ToInt32(existing.RequestedProvisionedConcurrentExecutions) != int32(provisioned.Count)) {
+		log.Printf("Provisioning concurrency %d for %s:%s\n", provisioned.Count, lambdaParams.FunctionName, provisioned.Qualifier)
+		_, err = wrapper.Client.PutProvisionedConcurrencyConfig(ctx, &lambda.PutProvisionedConcurrencyConfigInput{
+			FunctionName:                    functionName,
+			Qualifier:                       aws.String(provisioned.Qualifier),
+			ProvisionedConcurrentExecutions: aws.Int32(int32(provisioned.Count)),
+		})
+		if err != nil {
+			log.Printf("Not able to provision concurrency. The reason is %s", err.Error())
+			return err
+		}
+	}
+	return wrapper.waitForProvisionedConcurrency(ctx, lambdaParams.FunctionName, provisioned.Qualifier)
+}
+
+// checkConcurrencyHeadroom makes sure the account can take the requested concurrency, counting what the
+// function already holds as available
+func checkConcurrencyHeadroom(lambdaParams common.DeployParams, accountLimit *types.AccountLimit, currentReserved *int32, provisionedConfigs map[string]types.ProvisionedConcurrencyConfigListItem) error {
+	if accountLimit == nil || accountLimit.UnreservedConcurrentExecutions == nil {
+		return nil
+	}
+	available := int(*accountLimit.UnreservedConcurrentExecutions) - minimumUnreservedConcurrency
+	if currentReserved != nil {
+		available += int(*currentReserved)
+	}
+	reserved := lambdaParams.ReservedConcurrency
+	if reserved != nil && *reserved > available {
+		return &common.InputError{
+			Message: fmt.Sprintf("Reserved Concurrency %d exceeds the %d the account can still reserve. The account limit is %d and %d has to stay unreserved",
+				*reserved, available, accountLimit.ConcurrentExecutions, minimumUnreservedConcurrency),
+		}
+	}
+	provisioned := lambdaParams.ProvisionedConcurrency
+	if provisioned == nil || reserved != nil {
+		// Provisioned concurrency of a function with a reservation comes out of the reservation
+		return nil
+	}
+	if currentReserved == nil {
+		for _, config := range provisionedConfigs {
+			available += int(aws.ToInt32(config.RequestedProvisionedConcurrentExecutions))
+		}
+	}
+	if peakProvisioned(provisioned) > available {
+		return &common.InputError{
+			Message: fmt.Sprintf("Provisioned Concurrency %d exceeds the %d available in the account", peakProvisioned(provisioned), available),
+		}
+	}
+	return nil
+}
+
+func (wrapper ServiceWrapper) waitForProvisionedConcurrency(ctx context.Context, functionName string, qualifier string) error {
+	deadline := time.Now().Add(provisionedConcurrencyMaxWait)
+	for {
+		output, err := wrapper.Client.GetProvisionedConcurrencyConfig(ctx, &lambda.GetProvisionedConcurrencyConfigInput{
+			FunctionName: aws.String(functionName),
+			Qualifier:    aws.String(qualifier),
+		})
+		if err != nil {
+			return err
+		}
+		switch output.Status {
+		case types.ProvisionedConcurrencyStatusEnumReady:
+			log.Printf("Provisioned concurrency of %s:%s is ready with %d environments\n", functionName, qualifier, aws.ToInt32(output.AllocatedProvisionedConcurrentExecutions))
+			return nil
+		case types.ProvisionedConcurrencyStatusEnumFailed:
+			return fmt.Errorf("provisioned concurrency of %s:%s failed: %s", functionName, qualifier, aws.ToString(output.StatusReason))
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("provisioned concurrency of %s:%s is not ready after %v, %d of %d environments are allocated",
+				functionName, qualifier, provisionedConcurrencyMaxWait, aws.ToInt32(output.AllocatedProvisionedConcurrentExecutions), aws.ToInt32(output.RequestedProvisionedConcurrentExecutions))
+		}
+		log.Printf("Waiting for provisioned concurrency of %s:%s, %d of %d environments allocated\n",
+			functionName, qualifier, aws.ToInt32(output.AllocatedProvisionedConcurrentExecutions), aws.ToInt32(output.RequestedProvisionedConcurrentExecutions))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(provisionedConcurrencyPollInterval):
+		}
+	}
+}
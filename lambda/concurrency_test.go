@@ -0,0 +1,112 @@
+package lambda
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateConcurrency(t *testing.T) {
+	lambdaParams := validZipParams()
+	lambdaParams.ReservedConcurrency = aws.Int(0)
+	assert.NoError(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.ReservedConcurrency = aws.Int(-1)
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.ReservedConcurrency = aws.Int(5)
+	lambdaParams.ProvisionedConcurrency = &common.ProvisionedConcurrencyConfig{Qualifier: "live", Count: 10}
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.ProvisionedConcurrency = &common.ProvisionedConcurrencyConfig{Qualifier: "$LATEST", Count: 2}
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.ProvisionedConcurrency = &common.ProvisionedConcurrencyConfig{Qualifier: "live", Count: 2}
+	assert.NoError(t, ValidateInputParams(lambdaParams, true))
+}
+
+func TestPutConcurrency(t *testing.T) {
+	provisionedConcurrencyPollInterval = time.Millisecond
+	mock := &mockFunctionApi{
+		unreservedConcurrency: aws.Int32(900),
+		provisionedConfigs:    map[string]int32{"old": 3},
+		provisionedStatuses:   []types.ProvisionedConcurrencyStatusEnum{types.ProvisionedConcurrencyStatusEnumInProgress, types.ProvisionedConcurrencyStatusEnumReady},
+	}
+	wrapper := ServiceWrapper{Client: mock}
+	lambdaParams := validZipParams()
+	lambdaParams.ReservedConcurrency = aws.Int(20)
+	lambdaParams.ProvisionedConcurrency = &common.ProvisionedConcurrencyConfig{Qualifier: "live", Count: 5}
+
+	assert.NoError(t, wrapper.PutConcurrency(context.Background(), lambdaParams))
+	assert.Equal(t, int32(20), *mock.reservedConcurrency)
+	assert.Equal(t, []string{"old"}, mock.deletedProvisioned)
+	assert.Equal(t, map[string]int32{"live": 5}, mock.provisionedConfigs)
+
+	// Unset values remove the settings
+	lambdaParams.ReservedConcurrency = nil
+	lambdaParams.ProvisionedConcurrency = nil
+	assert.NoError(t, wrapper.PutConcurrency(context.Background(), lambdaParams))
+	assert.Nil(t, mock.reservedConcurrency)
+	assert.Empty(t, mock.provisionedConfigs)
+}
+
+func TestPutConcurrencyHeadroom(t *testing.T) {
+	mock := &mockFunctionApi{unreservedConcurrency: aws.Int32(150), reservedConcurrency: aws.Int32(10)}
+	wrapper := ServiceWrapper{Client: mock}
+	lambdaParams := validZipParams()
+
+	// What the function already reserves counts as available
+	lambdaParams.ReservedConcurrency = aws.Int(60)
+	assert.NoError(t, wrapper.PutConcurrency(context.Background(), lambdaParams))
+
+	lambdaParams.ReservedConcurrency = aws.Int(61)
+	mock.reservedConcurrency = aws.Int32(10)
+	err := wrapper.PutConcurrency(context.Background(), lambdaParams)
+	assert.IsType(t, &common.InputError{}, err)
+	assert.Equal(t, int32(10), *mock.reservedConcurrency)
+}
+
+func TestPutConcurrencyFailed(t *testing.T) {
+	provisionedConcurrencyPollInterval = time.Millisecond
+	mock := &mockFunctionApi{
+		unreservedConcurrency: aws.Int32(900),
+		provisionedStatuses:   []types.ProvisionedConcurrencyStatusEnum{types.ProvisionedConcurrencyStatusEnumFailed},
+	}
+	wrapper := ServiceWrapper{Client: mock}
+	lambdaParams := validZipParams()
+	lambdaParams.ProvisionedConcurrency = &common.ProvisionedConcurrencyConfig{Qualifier: "live", Count: 5}
+
+	err := wrapper.PutConcurrency(context.Background(), lambdaParams)
+	assert.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "FUNCTION_ERROR_INIT_FAILURE"))
+}
+
+func TestPutConcurrencyAutoscaled(t *testing.T) {
+	provisionedConcurrencyPollInterval = time.Millisecond
+	mock := &mockFunctionApi{
+		unreservedConcurrency: aws.Int32(900),
+		provisionedConfigs:    map[string]int32{"live": 12},
+		provisionedStatuses:   []types.ProvisionedConcurrencyStatusEnum{types.ProvisionedConcurrencyStatusEnumReady},
+	}
+	wrapper := ServiceWrapper{Client: mock}
+	lambdaParams := validZipParams()
+	lambdaParams.ProvisionedConcurrency = &common.ProvisionedConcurrencyConfig{
+		Qualifier:   "live",
+		Count:       2,
+		Autoscaling: &common.AutoscalingConfig{Min: 2, Max: 20, TargetUtilization: 0.7},
+	}
+
+	// The count autoscaling arrived at is kept
+	assert.NoError(t, wrapper.PutConcurrency(context.Background(), lambdaParams))
+	assert.Equal(t, int32(12), mock.provisionedConfigs["live"])
+
+	// The autoscaling max has to fit into the reservation
+	lambdaParams.ReservedConcurrency = aws.Int(10)
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+}
@@ -0,0 +1,163 @@
+package lambda
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+// Lambda limits the keys and values of all variables together to 4 KB
+const maxEnvironmentSize = 4096
+
+// Variables the Lambda runtime sets, which cannot be overridden
+var reservedVariables = []string{
+	"_HANDLER",
+	"_X_AMZN_TRACE_ID",
+	"AWS_ACCESS_KEY",
+	"AWS_ACCESS_KEY_ID",
+	"AWS_DEFAULT_REGION",
+	"AWS_EXECUTION_ENV",
+	"AWS_REGION",
+	"AWS_SECRET_ACCESS_KEY",
+	"AWS_SESSION_TOKEN",
+	"AWS_XRAY_CONTEXT_MISSING",
+	"AWS_XRAY_DAEMON_ADDRESS",
+	"LAMBDA_RUNTIME_DIR",
+	"LAMBDA_TASK_ROOT",
+}
+
+// AWS_LAMBDA_ variables are reserved except for these
+var allowedLambdaVariables = []string{"AWS_LAMBDA_EXEC_WRAPPER"}
+
+// How many of the largest variables are reported when the environment is too large
+const reportedOffenders = 3
+
+func validateEnvironment(lambdaParams common.DeployParams, errorMessage *strings.Builder) {
+	if lambdaParams.EnvMerge != "" && lambdaParams.EnvMerge != common.EnvMergeReplace && lambdaParams.EnvMerge != common.EnvMergeMerge {
+		errorMessage.WriteString(fmt.Sprintf("Env Merge must be either %s or %s.\n", common.EnvMergeReplace, common.EnvMergeMerge))
+	}
+	if lambdaParams.SecretScan != "" && !slices.Contains([]string{common.SecretScanWarn, common.SecretScanFail, common.SecretScanOff}, lambdaParams.SecretScan) {
+		errorMessage.WriteString(fmt.Sprintf("Secret Scan must be %s, %s or %s.\n", common.SecretScanWarn, common.SecretScanFail, common.SecretScanOff))
+	}
+	validateEncryptedVariables(lambdaParams, errorMessage)
+	variables := WithEncryptedVariables(lambdaParams.EnvironmentVariables, lambdaParams.EncryptedVariables)
+	for _, name := range sortedKeys(variables) {
+		if IsReservedVariable(name) {
+			errorMessage.WriteString(fmt.Sprintf("Environment variable %s is reserved by Lambda.\n", name))
+		}
+	}
+	if err := ValidateEnvironmentSize(variables); err != nil {
+		errorMessage.WriteString(err.Error())
+	}
+	if lambdaParams.SecretScan == common.SecretScanOff {
+		return
+	}
+	// Only the plain variables are scanned, ciphertext always looks random
+	for _, finding := range ScanSecrets(lambdaParams.EnvironmentVariables) {
+		if lambdaParams.SecretScan == common.SecretScanFail {
+			errorMessage.WriteString(finding.String() + ". Use a secret reference instead.\n")
+		} else {
+			log.Printf("Warning: %s. Consider a secret reference instead\n", finding)
+		}
+	}
+}
+
+func validateEncryptedVariables(lambdaParams common.DeployParams, errorMessage *strings.Builder) {
+	keyArn := lambdaParams.KmsKeyArn
+	if !common.TrimAndCheckEmptyString(&keyArn) && common.ArnService(keyArn) != "kms" {
+		errorMessage.WriteString("KMS Key ARN must be the ARN of a KMS key.\n")
+	}
+	if len(lambdaParams.EncryptedVariables) == 0 {
+		return
+	}
+	if common.TrimAndCheckEmptyString(&keyArn) {
+		errorMessage.WriteString("KMS Key ARN must be specified along with encrypted environment variables.\n")
+	}
+	for _, name := range sortedKeys(lambdaParams.EncryptedVariables) {
+		if _, found := lambdaParams.EnvironmentVariables[name]; found {
+			errorMessage.WriteString(fmt.Sprintf("Environment variable %s is declared both plain and encrypted.\n", name))
+		}
+		if _, err := base64.StdEncoding.DecodeString(lambdaParams.EncryptedVariables[name]); err != nil {
+			errorMessage.WriteString(fmt.Sprintf("Encrypted environment variable %s is not base64 ciphertext.\n", name))
+		}
+	}
+}
+
+// WithEncryptedVariables adds the encrypted variables to the plain ones. Lambda stores both the same way,
+// the encrypted ones are decrypted by the function itself.
+func WithEncryptedVariables(variables map[string]string, encrypted map[string]string) map[string]string {
+	if len(encrypted) == 0 {
+		return variables
+	}
+	combined := map[string]string{}
+	for name, value := range variables {
+		combined[name] = value
+	}
+	for name, value := range encrypted {
+		combined[name] = value
+	}
+	return combined
+}
+
+func IsReservedVariable(name string) bool {
+	if slices.Contains(allowedLambdaVariables, name) {
+		return false
+	}
+	return slices.Contains(reservedVariables, name) || strings.HasPrefix(name, "AWS_LAMBDA_")
+}
+
+// ValidateEnvironmentSize checks the 4 KB limit of the environment and names the largest variables when it is exceeded
+func ValidateEnvironmentSize(variables map[string]string) error {
+	total := 0
+	names := sortedKeys(variables)
+	for _, name := range names {
+		total += len(name) + len(variables[name])
+	}
+	if total <= maxEnvironmentSize {
+		return nil
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return len(names[i])+len(variables[names[i]]) > len(names[j])+len(variables[names[j]])
+	})
+	var offenders []string
+	for _, name := range names[:min(reportedOffenders, len(names))] {
+		offenders = append(offenders, fmt.Sprintf("%s (%d bytes)", name, len(name)+len(variables[name])))
+	}
+	return &common.InputError{
+		Message: fmt.Sprintf("Environment variables take %d bytes, more than the %d Lambda allows. The largest are %s.\n",
+			total, maxEnvironmentSize, strings.Join(offenders, ", ")),
+	}
+}
+
+func sortedKeys(variables map[string]string) []string {
+	var names []string
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MergeEnvironment returns the variables of the function overridden by the declared ones, so that
+// variables set by others are kept. Without declared variables (nil) the environment stays untouched.
+func MergeEnvironment(configuration *types.FunctionConfiguration, declared map[string]string) map[string]string {
+	if declared == nil {
+		return nil
+	}
+	merged := map[string]string{}
+	if configuration != nil && configuration.Environment != nil {
+		for key, value := range configuration.Environment.Variables {
+			merged[key] = value
+		}
+	}
+	for key, value := range declared {
+		merged[key] = value
+	}
+	return merged
+}
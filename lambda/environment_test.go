@@ -0,0 +1,98 @@
+package lambda
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateEnvMerge(t *testing.T) {
+	lambdaParams := validZipParams()
+	lambdaParams.EnvMerge = common.EnvMergeMerge
+	assert.NoError(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.EnvMerge = "append"
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+}
+
+func TestMergeEnvironment(t *testing.T) {
+	configuration := &types.FunctionConfiguration{
+		Environment: &types.EnvironmentResponse{Variables: map[string]string{"STAGE": "dev", "DD_API_KEY": "set-by-datadog"}},
+	}
+	merged := MergeEnvironment(configuration, map[string]string{"STAGE": "prod"})
+	assert.Equal(t, map[string]string{"STAGE": "prod", "DD_API_KEY": "set-by-datadog"}, merged)
+
+	assert.Nil(t, MergeEnvironment(configuration, nil))
+	assert.Equal(t, map[string]string{"STAGE": "prod"}, MergeEnvironment(&types.FunctionConfiguration{}, map[string]string{"STAGE": "prod"}))
+}
+
+func TestValidateReservedVariables(t *testing.T) {
+	lambdaParams := validZipParams()
+	lambdaParams.EnvironmentVariables = map[string]string{"AWS_LAMBDA_EXEC_WRAPPER": "/opt/wrapper", "STAGE": "prod"}
+	assert.NoError(t, ValidateInputParams(lambdaParams, true))
+
+	for _, name := range []string{"AWS_REGION", "_HANDLER", "AWS_LAMBDA_FUNCTION_NAME", "LAMBDA_TASK_ROOT"} {
+		lambdaParams.EnvironmentVariables = map[string]string{name: "value"}
+		assert.Error(t, ValidateInputParams(lambdaParams, true), name)
+	}
+}
+
+func TestValidateEnvironmentSize(t *testing.T) {
+	variables := map[string]string{"SMALL": "x"}
+	assert.NoError(t, ValidateEnvironmentSize(variables))
+
+	variables["CERT"] = strings.Repeat("c", 3000)
+	variables["CONFIG"] = strings.Repeat("j", 1000)
+	variables["TOKEN"] = strings.Repeat("t", 100)
+	err := ValidateEnvironmentSize(variables)
+	assert.IsType(t, &common.InputError{}, err)
+	assert.Contains(t, err.Error(), "4121 bytes")
+	assert.Contains(t, err.Error(), "CERT (3004 bytes), CONFIG (1006 bytes), TOKEN (105 bytes)")
+}
+
+func TestValidateEncryptedVariables(t *testing.T) {
+	keyArn := "arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+	lambdaParams := validZipParams()
+	lambdaParams.EnvironmentVariables = map[string]string{"STAGE": "prod"}
+	lambdaParams.EncryptedVariables = map[string]string{"DB_PASSWORD": "AQICAHh4bGVhZGVyAAAAAA=="}
+	lambdaParams.SecretScan = common.SecretScanFail
+	// Without a key the ciphertext cannot be decrypted
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.KmsKeyArn = keyArn
+	assert.NoError(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.EncryptedVariables["STAGE"] = "AQICAHh4bGVhZGVyAAAAAA=="
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.EncryptedVariables = map[string]string{"DB_PASSWORD": "not base64!"}
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.EncryptedVariables = nil
+	lambdaParams.KmsKeyArn = "arn:aws:sqs:us-east-1:123456789012:queue"
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+}
+
+func TestUpdateFunctionConfigurationKmsKey(t *testing.T) {
+	mock := &mockFunctionApi{}
+	wrapper := ServiceWrapper{Client: mock}
+	lambdaParams := common.DeployParams{FunctionName: "test", KmsKeyArn: "arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"}
+	assert.NoError(t, wrapper.UpdateFunctionConfiguration(context.TODO(), lambdaParams))
+	assert.Equal(t, lambdaParams.KmsKeyArn, *mock.configInput.KMSKeyArn)
+
+	// Removing the key goes back to the Lambda managed key
+	lambdaParams.KmsKeyArn = ""
+	assert.NoError(t, wrapper.UpdateFunctionConfiguration(context.TODO(), lambdaParams))
+	assert.Equal(t, "", *mock.configInput.KMSKeyArn)
+}
+
+func TestWithEncryptedVariables(t *testing.T) {
+	assert.Nil(t, WithEncryptedVariables(nil, nil))
+	plain := map[string]string{"STAGE": "prod"}
+	assert.Equal(t, map[string]string{"STAGE": "prod", "DB_PASSWORD": "cipher"}, WithEncryptedVariables(plain, map[string]string{"DB_PASSWORD": "cipher"}))
+	assert.Equal(t, map[string]string{"STAGE": "prod"}, plain)
+}
@@ -0,0 +1,215 @@
+package lambda
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+type InvokeParams struct {
+	FunctionName string
+	Qualifier    string
+	Payload      []byte
+	Async        bool
+}
+
+// InvokeResult holds the outcome of an invocation. Logs and Report are only filled for synchronous invocations.
+type InvokeResult struct {
+	StatusCode      int32
+	ExecutedVersion string
+	Payload         []byte
+	FunctionError   string
+	Logs            []string
+	Report          *Report
+}
+
+// Report holds the figures of the REPORT line Lambda writes at the end of each invocation.
+// Durations are in milliseconds, memory in MB. InitDuration is only set on cold starts.
+type Report struct {
+	RequestId      string
+	Duration       float64
+	BilledDuration float64
+	MemorySize     int
+	MaxMemoryUsed  int
+	InitDuration   float64
+}
+
+func (report Report) String() string {
+	summary := fmt.Sprintf("Duration: %.2f ms, Billed Duration: %.0f ms, Memory Size: %d MB, Max Memory Used: %d MB",
+		report.Duration, report.BilledDuration, report.MemorySize, report.MaxMemoryUsed)
+	if report.InitDuration > 0 {
+		summary += fmt.Sprintf(", Init Duration: %.2f ms", report.InitDuration)
+	}
+	return summary
+}
+
+// ReadPayload reads the payload from the file, or from the reader when the file is -. The payload must be JSON.
+func ReadPayload(fileName string, stdin io.Reader) ([]byte, error) {
+	if common.TrimAndCheckEmptyString(&fileName) {
+		return nil, nil
+	}
+	var payload []byte
+	var err error
+	if fileName == "-" {
+		payload, err = io.ReadAll(stdin)
+	} else {
+		payload, err = os.ReadFile(fileName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(payload) {
+		return nil, &common.InputError{
+			Message: fmt.Sprintf("Payload %s is not valid JSON", fileName),
+		}
+	}
+	return payload, nil
+}
+
+// ParseReport parses a line like
+// REPORT RequestId: 1f2e Duration: 12.34 ms Billed Duration: 13 ms Memory Size: 128 MB Max Memory Used: 35 MB Init Duration: 120.50 ms
+// where the fields are tab separated. It returns nil when the line is not a REPORT line.
+func ParseReport(line string) *Report {
+	if !strings.HasPrefix(line, "REPORT ") {
+		return nil
+	}
+	report := &Report{}
+	for _, field := range strings.Split(strings.TrimPrefix(line, "REPORT "), "\t") {
+		name, value, found := strings.Cut(strings.TrimSpace(field), ": ")
+		if !found {
+			continue
+		}
+		// Values carry their unit, e.g. 12.34 ms or 128 MB
+		number, _, _ := strings.Cut(value, " ")
+		switch name {
+		case "RequestId":
+			report.RequestId = value
+		case "Duration":
+			report.Duration, _ = strconv.ParseFloat(number, 64)
+		case "Billed Duration":
+			report.BilledDuration, _ = strconv.ParseFloat(number, 64)
+		case "Memory Size":
+			report.MemorySize, _ = strconv.Atoi(number)
+		case "Max Memory Used":
+			report.MaxMemoryUsed, _ = strconv.Atoi(number)
+		case "Init Duration":
+			report.InitDuration, _ = strconv.ParseFloat(number, 64)
+		}
+	}
+	return report
+}
+
+// DecodeLogResult decodes the base64 log tail into its lines and the parsed REPORT line if present
+func DecodeLogResult(logResult *string) ([]string, *Report, error) {
+	if logResult == nil {
+		return nil, nil, nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(*logResult)
+	if err != nil {
+		return nil, nil, err
+	}
+	var lines []string
+	var report *Report
+	for _, line := range strings.Split(strings.TrimRight(string(decoded), "\n"), "\n") {
+		if parsed := ParseReport(line); parsed != nil {
+			report = parsed
+		}
+		lines = append(lines, line)
+	}
+	return lines, report, nil
+}
+
+func (wrapper ServiceWrapper) Invoke(ctx context.Context, invokeParams InvokeParams) (*InvokeResult, error) {
+	invokeInput := &lambda.InvokeInput{
+		FunctionName:   aws.String(invokeParams.FunctionName),
+		Payload:        invokeParams.Payload,
+		InvocationType: types.InvocationTypeRequestResponse,
+		LogType:        types.LogTypeTail,
+	}
+	if invokeParams.Qualifier != "" {
+		invokeInput.Qualifier = aws.String(invokeParams.Qualifier)
+	}
+	if invokeParams.Async {
+		invokeInput.InvocationType = types.InvocationTypeEvent
+		invokeInput.LogType = types.LogTypeNone
+	}
+	output, err := wrapper.Client.Invoke(ctx, invokeInput)
+	if err != nil {
+		log.Printf("Not able to invoke function %s. The reason is %s", invokeParams.FunctionName, err.Error())
+		return nil, err
+	}
+	result := &InvokeResult{
+		StatusCode:      output.StatusCode,
+		ExecutedVersion: aws.ToString(output.ExecutedVersion),
+		Payload:         output.Payload,
+		FunctionError:   aws.ToString(output.FunctionError),
+	}
+	result.Logs, result.Report, err = DecodeLogResult(output.LogResult)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// InvokeStream invokes the function with response streaming, writing the payload chunks to out as they arrive
+func (wrapper ServiceWrapper) InvokeStream(ctx context.Context, invokeParams InvokeParams, out io.Writer) (*InvokeResult, error) {
+	invokeInput := &lambda.InvokeWithResponseStreamInput{
+		FunctionName: aws.String(invokeParams.FunctionName),
+		Payload:      invokeParams.Payload,
+		LogType:      types.LogTypeTail,
+	}
+	if invokeParams.Qualifier != "" {
+		invokeInput.Qualifier = aws.String(invokeParams.Qualifier)
+	}
+	output, err := wrapper.Client.InvokeWithResponseStream(ctx, invokeInput)
+	if err != nil {
+		log.Printf("Not able to invoke function %s. The reason is %s", invokeParams.FunctionName, err.Error())
+		return nil, err
+	}
+	stream := output.GetStream()
+	defer stream.Close()
+	result, err := readResponseStream(stream.Reader, out)
+	if err != nil {
+		return nil, err
+	}
+	result.StatusCode = output.StatusCode
+	result.ExecutedVersion = aws.ToString(output.ExecutedVersion)
+	return result, nil
+}
+
+func readResponseStream(reader lambda.InvokeWithResponseStreamResponseEventReader, out io.Writer) (*InvokeResult, error) {
+	result := &InvokeResult{}
+	for event := range reader.Events() {
+		switch value := event.(type) {
+		case *types.InvokeWithResponseStreamResponseEventMemberPayloadChunk:
+			if _, err := out.Write(value.Value.Payload); err != nil {
+				return nil, err
+			}
+		case *types.InvokeWithResponseStreamResponseEventMemberInvokeComplete:
+			result.FunctionError = aws.ToString(value.Value.ErrorCode)
+			if value.Value.ErrorDetails != nil {
+				result.Payload = []byte(*value.Value.ErrorDetails)
+			}
+			var err error
+			result.Logs, result.Report, err = DecodeLogResult(value.Value.LogResult)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := reader.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
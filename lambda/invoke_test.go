@@ -0,0 +1,98 @@
+package lambda
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const logTail = "START RequestId: 1f2e Version: $LATEST\n" +
+	"processing order 42\n" +
+	"END RequestId: 1f2e\n" +
+	"REPORT RequestId: 1f2e\tDuration: 12.34 ms\tBilled Duration: 13 ms\tMemory Size: 128 MB\tMax Memory Used: 35 MB\tInit Duration: 120.50 ms\t\n"
+
+// fakeEventReader replays the events of a response stream
+type fakeEventReader struct {
+	events chan types.InvokeWithResponseStreamResponseEvent
+}
+
+func (f *fakeEventReader) Events() <-chan types.InvokeWithResponseStreamResponseEvent {
+	return f.events
+}
+
+func (f *fakeEventReader) Close() error {
+	return nil
+}
+
+func (f *fakeEventReader) Err() error {
+	return nil
+}
+
+func TestParseReport(t *testing.T) {
+	assert.Nil(t, ParseReport("END RequestId: 1f2e"))
+	report := ParseReport("REPORT RequestId: 1f2e\tDuration: 12.34 ms\tBilled Duration: 13 ms\tMemory Size: 128 MB\tMax Memory Used: 35 MB\t")
+	assert.Equal(t, &Report{RequestId: "1f2e", Duration: 12.34, BilledDuration: 13, MemorySize: 128, MaxMemoryUsed: 35}, report)
+	assert.Equal(t, "Duration: 12.34 ms, Billed Duration: 13 ms, Memory Size: 128 MB, Max Memory Used: 35 MB", report.String())
+}
+
+func TestReadPayload(t *testing.T) {
+	payload, err := ReadPayload("-", strings.NewReader(`{"orderId":42}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"orderId":42}`, string(payload))
+	payload, err = ReadPayload("", nil)
+	assert.NoError(t, err)
+	assert.Nil(t, payload)
+	_, err = ReadPayload("-", strings.NewReader("orderId=42"))
+	assert.ErrorContains(t, err, "not valid JSON")
+}
+
+func TestInvoke(t *testing.T) {
+	mock := &mockFunctionApi{invokeOutput: &lambda.InvokeOutput{
+		StatusCode:    200,
+		Payload:       []byte(`{"errorMessage":"boom"}`),
+		FunctionError: aws.String("Unhandled"),
+		LogResult:     aws.String(base64.StdEncoding.EncodeToString([]byte(logTail))),
+	}}
+	wrapper := ServiceWrapper{Client: mock}
+	result, err := wrapper.Invoke(context.TODO(), InvokeParams{FunctionName: "test", Qualifier: "live", Payload: []byte(`{}`)})
+	assert.NoError(t, err)
+	assert.Equal(t, "live", *mock.invokeInput.Qualifier)
+	assert.Equal(t, types.LogTypeTail, mock.invokeInput.LogType)
+	assert.Equal(t, "Unhandled", result.FunctionError)
+	assert.Len(t, result.Logs, 4)
+	assert.Equal(t, "processing order 42", result.Logs[1])
+	assert.Equal(t, 120.5, result.Report.InitDuration)
+	assert.Equal(t, 35, result.Report.MaxMemoryUsed)
+
+	mock.invokeOutput = &lambda.InvokeOutput{StatusCode: 202}
+	result, err = wrapper.Invoke(context.TODO(), InvokeParams{FunctionName: "test", Async: true})
+	assert.NoError(t, err)
+	assert.Equal(t, types.InvocationTypeEvent, mock.invokeInput.InvocationType)
+	assert.Nil(t, mock.invokeInput.Qualifier)
+	assert.Equal(t, int32(202), result.StatusCode)
+	assert.Nil(t, result.Report)
+}
+
+func TestReadResponseStream(t *testing.T) {
+	reader := &fakeEventReader{events: make(chan types.InvokeWithResponseStreamResponseEvent, 3)}
+	reader.events <- &types.InvokeWithResponseStreamResponseEventMemberPayloadChunk{Value: types.InvokeResponseStreamUpdate{Payload: []byte("hello ")}}
+	reader.events <- &types.InvokeWithResponseStreamResponseEventMemberPayloadChunk{Value: types.InvokeResponseStreamUpdate{Payload: []byte("world")}}
+	reader.events <- &types.InvokeWithResponseStreamResponseEventMemberInvokeComplete{Value: types.InvokeWithResponseStreamCompleteEvent{
+		LogResult: aws.String(base64.StdEncoding.EncodeToString([]byte(logTail))),
+	}}
+	close(reader.events)
+
+	var out bytes.Buffer
+	result, err := readResponseStream(reader, &out)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", out.String())
+	assert.Empty(t, result.FunctionError)
+	assert.Equal(t, 12.34, result.Report.Duration)
+}
@@ -0,0 +1,190 @@
+package lambda
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+type LayerParams struct {
+	LayerName               string
+	Directory               string
+	Description             string
+	CompatibleRuntimes      []string
+	CompatibleArchitectures []string
+}
+
+// ZipDirectory archives the contents of the directory, keeping paths relative to it.
+func ZipDirectory(directory string) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	err := filepath.WalkDir(directory, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		relativePath, err := filepath.Rel(directory, filePath)
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(relativePath)
+		header.Method = zip.Deflate
+		fileWriter, err := writer.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(fileWriter, file)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func ValidateLayerParams(layerParams LayerParams) error {
+	var errorMessage strings.Builder
+	if common.TrimAndCheckEmptyString(&layerParams.LayerName) {
+		errorMessage.WriteString("Layer Name cannot be null.\n")
+	}
+	if common.TrimAndCheckEmptyString(&layerParams.Directory) {
+		errorMessage.WriteString("Directory cannot be null.\n")
+	} else if info, err := os.Stat(layerParams.Directory); err != nil || !info.IsDir() {
+		errorMessage.WriteString("Directory must be an existing directory.\n")
+	}
+	if len(errorMessage.String()) > 0 {
+		return &common.InputError{
+			Message: errorMessage.String(),
+		}
+	}
+	return nil
+}
+
+func (wrapper ServiceWrapper) PublishLayer(ctx context.Context, layerParams LayerParams) (*lambda.PublishLayerVersionOutput, error) {
+	contents, err := ZipDirectory(layerParams.Directory)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	layerInput := &lambda.PublishLayerVersionInput{
+		LayerName:               &layerParams.LayerName,
+		Content:                 &types.LayerVersionContentInput{ZipFile: contents},
+		CompatibleArchitectures: architectures(layerParams.CompatibleArchitectures),
+	}
+	for _, runtime := range layerParams.CompatibleRuntimes {
+		layerInput.CompatibleRuntimes = append(layerInput.CompatibleRuntimes, types.Runtime(runtime))
+	}
+	if !common.TrimAndCheckEmptyString(&layerParams.Description) {
+		layerInput.Description = &layerParams.Description
+	}
+	log.Println("Publishing layer--", layerParams.LayerName)
+	output, err := wrapper.Client.PublishLayerVersion(ctx, layerInput)
+	if err != nil {
+		log.Printf("Not able to publish the layer. The reason is %s", err.Error())
+		return nil, err
+	}
+	return output, nil
+}
+
+// ListLayerVersions returns all versions of the layer, newest first.
+func (wrapper ServiceWrapper) ListLayerVersions(ctx context.Context, layerName string) ([]types.LayerVersionsListItem, error) {
+	var layerVersions []types.LayerVersionsListItem
+	input := &lambda.ListLayerVersionsInput{LayerName: &layerName}
+	for {
+		output, err := wrapper.Client.ListLayerVersions(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		layerVersions = append(layerVersions, output.LayerVersions...)
+		if output.NextMarker == nil {
+			break
+		}
+		input.Marker = output.NextMarker
+	}
+	return layerVersions, nil
+}
+
+// ResolveLayers replaces local layer names with the ARN of their latest published version.
+// Entries which are already ARNs are passed through as is.
+func (wrapper ServiceWrapper) ResolveLayers(ctx context.Context, layers []string) ([]string, error) {
+	var resolved []string
+	for _, layer := range layers {
+		if common.TrimAndCheckEmptyString(&layer) {
+			continue
+		}
+		if strings.HasPrefix(layer, "arn:") {
+			resolved = append(resolved, layer)
+			continue
+		}
+		layerVersions, err := wrapper.ListLayerVersions(ctx, layer)
+		if err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		if len(layerVersions) == 0 {
+			return nil, &common.InputError{
+				Message: fmt.Sprintf("No published version found for layer %s", layer),
+			}
+		}
+		log.Printf("Resolved layer %s to %s\n", layer, *layerVersions[0].LayerVersionArn)
+		resolved = append(resolved, *layerVersions[0].LayerVersionArn)
+	}
+	return resolved, nil
+}
+
+// PruneLayerVersions deletes all but the newest keep versions of the layer.
+func (wrapper ServiceWrapper) PruneLayerVersions(ctx context.Context, layerName string, keep int) ([]int64, error) {
+	layerVersions, err := wrapper.ListLayerVersions(ctx, layerName)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	var deleted []int64
+	if keep < 0 {
+		keep = 0
+	}
+	if len(layerVersions) <= keep {
+		return deleted, nil
+	}
+	for _, layerVersion := range layerVersions[keep:] {
+		_, err := wrapper.Client.DeleteLayerVersion(ctx, &lambda.DeleteLayerVersionInput{
+			LayerName:     &layerName,
+			VersionNumber: &layerVersion.Version,
+		})
+		if err != nil {
+			log.Printf("Not able to delete version %d of layer %s. The reason is %s", layerVersion.Version, layerName, err.Error())
+			return deleted, err
+		}
+		deleted = append(deleted, layerVersion.Version)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,83 @@
+package lambda
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func layerVersions() []types.LayerVersionsListItem {
+	return []types.LayerVersionsListItem{
+		{LayerVersionArn: aws.String("arn:aws:lambda:us-east-1:123456789012:layer:shared:3"), Version: 3},
+		{LayerVersionArn: aws.String("arn:aws:lambda:us-east-1:123456789012:layer:shared:2"), Version: 2},
+		{LayerVersionArn: aws.String("arn:aws:lambda:us-east-1:123456789012:layer:shared:1"), Version: 1},
+	}
+}
+
+func TestZipDirectory(t *testing.T) {
+	directory := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(directory, "python", "lib"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(directory, "python", "lib", "util.py"), []byte("x = 1"), 0644))
+
+	contents, err := ZipDirectory(directory)
+	assert.NoError(t, err)
+	zipReader, err := zip.NewReader(bytes.NewReader(contents), int64(len(contents)))
+	assert.NoError(t, err)
+	assert.Len(t, zipReader.File, 1)
+	assert.Equal(t, "python/lib/util.py", zipReader.File[0].Name)
+}
+
+func TestPublishLayer(t *testing.T) {
+	wrapper := ServiceWrapper{Client: &mockFunctionApi{}}
+	output, err := wrapper.PublishLayer(context.TODO(), LayerParams{LayerName: "shared", Directory: t.TempDir()})
+	assert.NoError(t, err)
+	assert.Equal(t, "arn:aws:lambda:us-east-1:123456789012:layer:shared:1", *output.LayerVersionArn)
+}
+
+func TestResolveLayers(t *testing.T) {
+	wrapper := ServiceWrapper{Client: &mockFunctionApi{layerVersions: layerVersions()}}
+	arn := "arn:aws:lambda:us-east-1:123456789012:layer:other:7"
+	resolved, err := wrapper.ResolveLayers(context.TODO(), []string{arn, "shared"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{arn, "arn:aws:lambda:us-east-1:123456789012:layer:shared:3"}, resolved)
+
+	wrapper = ServiceWrapper{Client: &mockFunctionApi{}}
+	_, err = wrapper.ResolveLayers(context.TODO(), []string{"missing"})
+	assert.Error(t, err)
+}
+
+func TestPruneLayerVersions(t *testing.T) {
+	mock := &mockFunctionApi{layerVersions: layerVersions()}
+	wrapper := ServiceWrapper{Client: mock}
+	deleted, err := wrapper.PruneLayerVersions(context.TODO(), "shared", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{2, 1}, deleted)
+	assert.Equal(t, []int64{2, 1}, mock.deletedLayerVersions)
+}
+
+func TestUpdateFunctionConfigurationLayers(t *testing.T) {
+	mock := &mockFunctionApi{}
+	wrapper := ServiceWrapper{Client: mock}
+	lambdaParams := common.DeployParams{FunctionName: "test", Layers: []string{"arn:aws:lambda:us-east-1:123456789012:layer:shared:3"}}
+	assert.NoError(t, wrapper.UpdateFunctionConfiguration(context.TODO(), lambdaParams))
+	assert.Equal(t, lambdaParams.Layers, mock.configInput.Layers)
+
+	// Removing the last layer detaches it
+	lambdaParams.Layers = nil
+	assert.NoError(t, wrapper.UpdateFunctionConfiguration(context.TODO(), lambdaParams))
+	assert.NotNil(t, mock.configInput.Layers)
+	assert.Empty(t, mock.configInput.Layers)
+
+	// Image functions cannot have layers
+	lambdaParams.ImageUri = "123456789012.dkr.ecr.us-east-1.amazonaws.com/orders:latest"
+	assert.NoError(t, wrapper.UpdateFunctionConfiguration(context.TODO(), lambdaParams))
+	assert.Nil(t, mock.configInput.Layers)
+}
@@ -0,0 +1,73 @@
+package lambda
+
+import (
+	"fmt"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+var logGroupPattern = regexp.MustCompile(`^[.\-_/#A-Za-z0-9]{1,512}$`)
+
+func validateObservability(lambdaParams common.DeployParams, errorMessage *strings.Builder) {
+	if lambdaParams.Tracing != "" && !slices.Contains(types.TracingMode("").Values(), types.TracingMode(lambdaParams.Tracing)) {
+		errorMessage.WriteString("Tracing must be either Active or PassThrough.\n")
+	}
+	logging := lambdaParams.Logging
+	if logging == nil {
+		return
+	}
+	if logging.Format != "" && !slices.Contains(types.LogFormat("").Values(), types.LogFormat(logging.Format)) {
+		errorMessage.WriteString("Logging format must be either JSON or Text.\n")
+	}
+	if logging.ApplicationLogLevel != "" && !slices.Contains(types.ApplicationLogLevel("").Values(), types.ApplicationLogLevel(logging.ApplicationLogLevel)) {
+		errorMessage.WriteString("Application log level must be one of TRACE, DEBUG, INFO, WARN, ERROR or FATAL.\n")
+	}
+	if logging.SystemLogLevel != "" && !slices.Contains(types.SystemLogLevel("").Values(), types.SystemLogLevel(logging.SystemLogLevel)) {
+		errorMessage.WriteString("System log level must be one of DEBUG, INFO or WARN.\n")
+	}
+	// Lambda can only filter the levels of structured logs
+	if (logging.ApplicationLogLevel != "" || logging.SystemLogLevel != "") && logging.Format != string(types.LogFormatJson) {
+		errorMessage.WriteString("Log levels can only be set with the JSON logging format.\n")
+	}
+	if logging.LogGroup != "" && !logGroupPattern.MatchString(logging.LogGroup) {
+		errorMessage.WriteString(fmt.Sprintf("Log group %q is not a valid log group name.\n", logging.LogGroup))
+	}
+}
+
+func tracingConfig(mode string) *types.TracingConfig {
+	if mode == "" {
+		return nil
+	}
+	return &types.TracingConfig{Mode: types.TracingMode(mode)}
+}
+
+func loggingConfig(lambdaParams common.DeployParams) *types.LoggingConfig {
+	if lambdaParams.Logging == nil {
+		return nil
+	}
+	result := &types.LoggingConfig{
+		LogFormat: types.LogFormat(lambdaParams.Logging.Format),
+		// Always sent, so removing log_group goes back to the default log group
+		LogGroup: aws.String(lambdaParams.LogGroupName()),
+	}
+	if result.LogFormat == "" {
+		result.LogFormat = types.LogFormatText
+	}
+	if result.LogFormat == types.LogFormatJson {
+		// Levels left out go back to INFO, the default of Lambda, instead of keeping an earlier level
+		result.ApplicationLogLevel = types.ApplicationLogLevelInfo
+		result.SystemLogLevel = types.SystemLogLevelInfo
+		if lambdaParams.Logging.ApplicationLogLevel != "" {
+			result.ApplicationLogLevel = types.ApplicationLogLevel(lambdaParams.Logging.ApplicationLogLevel)
+		}
+		if lambdaParams.Logging.SystemLogLevel != "" {
+			result.SystemLogLevel = types.SystemLogLevel(lambdaParams.Logging.SystemLogLevel)
+		}
+	}
+	return result
+}
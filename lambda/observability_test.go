@@ -0,0 +1,73 @@
+package lambda
+
+import (
+	"context"
+	"testing"
+
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateObservability(t *testing.T) {
+	lambdaParams := validZipParams()
+	lambdaParams.Tracing = "Active"
+	lambdaParams.Logging = &common.LoggingConfig{Format: "JSON", ApplicationLogLevel: "DEBUG", SystemLogLevel: "WARN", LogGroup: "/shared/orders"}
+	assert.NoError(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.Tracing = "active"
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.Tracing = "PassThrough"
+	lambdaParams.Logging = &common.LoggingConfig{Format: "Text", ApplicationLogLevel: "DEBUG"}
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.Logging = &common.LoggingConfig{Format: "JSON", SystemLogLevel: "ERROR"}
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.Logging = &common.LoggingConfig{Format: "JSON", LogGroup: "orders logs"}
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+}
+
+func TestUpdateFunctionConfigurationTracing(t *testing.T) {
+	mock := &mockFunctionApi{}
+	wrapper := ServiceWrapper{Client: mock}
+	lambdaParams := common.DeployParams{FunctionName: "orders", Tracing: "Active"}
+	assert.NoError(t, wrapper.UpdateFunctionConfiguration(context.TODO(), lambdaParams))
+	assert.Equal(t, types.TracingModeActive, mock.configInput.TracingConfig.Mode)
+
+	// Removing tracing turns off active tracing
+	lambdaParams.Tracing = ""
+	assert.NoError(t, wrapper.UpdateFunctionConfiguration(context.TODO(), lambdaParams))
+	assert.Equal(t, types.TracingModePassThrough, mock.configInput.TracingConfig.Mode)
+}
+
+func TestUpdateFunctionConfigurationLogging(t *testing.T) {
+	mock := &mockFunctionApi{}
+	wrapper := ServiceWrapper{Client: mock}
+	lambdaParams := common.DeployParams{FunctionName: "orders", Logging: &common.LoggingConfig{Format: "JSON", ApplicationLogLevel: "DEBUG", LogGroup: "/shared/orders"}}
+	assert.NoError(t, wrapper.UpdateFunctionConfiguration(context.TODO(), lambdaParams))
+	assert.Equal(t, "/shared/orders", *mock.configInput.LoggingConfig.LogGroup)
+
+	// Removing logging goes back to text logs in the default log group
+	lambdaParams.Logging = nil
+	assert.NoError(t, wrapper.UpdateFunctionConfiguration(context.TODO(), lambdaParams))
+	assert.Equal(t, &types.LoggingConfig{LogFormat: types.LogFormatText, LogGroup: aws.String("/aws/lambda/orders")}, mock.configInput.LoggingConfig)
+}
+
+func TestLoggingConfig(t *testing.T) {
+	lambdaParams := common.DeployParams{FunctionName: "orders"}
+	assert.Nil(t, loggingConfig(lambdaParams))
+
+	lambdaParams.Logging = &common.LoggingConfig{}
+	assert.Equal(t, &types.LoggingConfig{LogFormat: types.LogFormatText, LogGroup: aws.String("/aws/lambda/orders")}, loggingConfig(lambdaParams))
+
+	lambdaParams.Logging = &common.LoggingConfig{Format: "JSON", ApplicationLogLevel: "DEBUG", LogGroup: "/shared/orders"}
+	assert.Equal(t, &types.LoggingConfig{
+		LogFormat:           types.LogFormatJson,
+		ApplicationLogLevel: types.ApplicationLogLevelDebug,
+		SystemLogLevel:      types.SystemLogLevelInfo,
+		LogGroup:            aws.String("/shared/orders"),
+	}, loggingConfig(lambdaParams))
+}
@@ -0,0 +1,249 @@
+package lambda
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/smithy-go"
+)
+
+// Statements added by this tool carry this prefix, so statements added by others are left alone
+const statementIdPrefix = "lambda-deploy-"
+
+const (
+	invokeFunctionAction    = "lambda:InvokeFunction"
+	invokeFunctionUrlAction = "lambda:InvokeFunctionUrl"
+)
+
+type policyDocument struct {
+	Statement []struct {
+		Sid       string
+		Principal any
+		Condition map[string]map[string]any
+	}
+}
+
+func permissionAction(permission common.Permission) string {
+	if !common.TrimAndCheckEmptyString(&permission.Action) {
+		return permission.Action
+	}
+	if !common.TrimAndCheckEmptyString(&permission.FunctionUrlAuthType) {
+		return invokeFunctionUrlAction
+	}
+	return invokeFunctionAction
+}
+
+// StatementId derives the statement id from the content of the permission, so a changed
+// permission gets a new statement and the old one is removed as stale.
+func StatementId(permission common.Permission) string {
+	content := strings.Join([]string{
+		permissionAction(permission),
+		permission.Principal,
+		permission.SourceArn,
+		permission.SourceAccount,
+		permission.Qualifier,
+		permission.FunctionUrlAuthType,
+	}, "|")
+	hash := sha256.Sum256([]byte(content))
+	return statementIdPrefix + hex.EncodeToString(hash[:])[:16]
+}
+
+func validatePermissions(permissions []common.Permission, errorMessage *strings.Builder) {
+	for _, permission := range permissions {
+		if common.TrimAndCheckEmptyString(&permission.Principal) {
+			errorMessage.WriteString("Permission Principal cannot be null.\n")
+		}
+		authType := permission.FunctionUrlAuthType
+		if authType != "" && authType != string(types.FunctionUrlAuthTypeAwsIam) && authType != string(types.FunctionUrlAuthTypeNone) {
+			errorMessage.WriteString("Permission Function URL Auth Type must be either AWS_IAM or NONE.\n")
+		}
+		if authType != "" && permissionAction(permission) != invokeFunctionUrlAction {
+			errorMessage.WriteString("Permission Function URL Auth Type can only be used with lambda:InvokeFunctionUrl.\n")
+		}
+	}
+}
+
+// getPolicy returns nil when the function has no resource based policy yet
+func (wrapper ServiceWrapper) getPolicy(ctx context.Context, functionName string, qualifier string) (*policyDocument, error) {
+	policyInput := &lambda.GetPolicyInput{FunctionName: &functionName}
+	if qualifier != "" {
+		policyInput.Qualifier = &qualifier
+	}
+	output, err := wrapper.Client.GetPolicy(ctx, policyInput)
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			if _, ok := apiErr.(*types.ResourceNotFoundException); ok {
+				// No policy yet
+				return nil, nil
+			}
+		}
+		return nil, err
+	}
+	policy := policyDocument{}
+	if err := json.Unmarshal([]byte(*output.Policy), &policy); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// statementIds returns the ids of the statements in the function policy which were added by this tool
+func (wrapper ServiceWrapper) statementIds(ctx context.Context, functionName string, qualifier string) ([]string, error) {
+	policy, err := wrapper.getPolicy(ctx, functionName, qualifier)
+	if err != nil || policy == nil {
+		return nil, err
+	}
+	var ids []string
+	for _, statement := range policy.Statement {
+		if strings.HasPrefix(statement.Sid, statementIdPrefix) {
+			ids = append(ids, statement.Sid)
+		}
+	}
+	return ids, nil
+}
+
+// PermissionSourceArns returns the source ARNs of the statements this tool added to the unqualified
+// function policy for the service principal, e.g. the buckets allowed through s3.amazonaws.com
+func (wrapper ServiceWrapper) PermissionSourceArns(ctx context.Context, functionName string, principal string) ([]string, error) {
+	policy, err := wrapper.getPolicy(ctx, functionName, "")
+	if err != nil || policy == nil {
+		return nil, err
+	}
+	var sourceArns []string
+	for _, statement := range policy.Statement {
+		if !strings.HasPrefix(statement.Sid, statementIdPrefix) {
+			continue
+		}
+		servicePrincipal, ok := statement.Principal.(map[string]any)
+		if !ok || servicePrincipal["Service"] != principal {
+			continue
+		}
+		if sourceArn, ok := statement.Condition["ArnLike"]["AWS:SourceArn"].(string); ok {
+			sourceArns = append(sourceArns, sourceArn)
+		}
+	}
+	return sourceArns, nil
+}
+
+// qualifiers returns the aliases and published versions of the function, each of which has its own policy
+func (wrapper ServiceWrapper) qualifiers(ctx context.Context, functionName string) ([]string, error) {
+	var qualifiers []string
+	aliasInput := &lambda.ListAliasesInput{FunctionName: &functionName}
+	for {
+		output, err := wrapper.Client.ListAliases(ctx, aliasInput)
+		if err != nil {
+			return nil, err
+		}
+		for _, alias := range output.Aliases {
+			qualifiers = append(qualifiers, *alias.Name)
+		}
+		if output.NextMarker == nil {
+			break
+		}
+		aliasInput.Marker = output.NextMarker
+	}
+	versionInput := &lambda.ListVersionsByFunctionInput{FunctionName: &functionName}
+	for {
+		output, err := wrapper.Client.ListVersionsByFunction(ctx, versionInput)
+		if err != nil {
+			return nil, err
+		}
+		for _, version := range output.Versions {
+			// $LATEST shares the unqualified policy
+			if *version.Version != "$LATEST" {
+				qualifiers = append(qualifiers, *version.Version)
+			}
+		}
+		if output.NextMarker == nil {
+			break
+		}
+		versionInput.Marker = output.NextMarker
+	}
+	return qualifiers, nil
+}
+
+// ReconcilePermissions adds the declared permissions missing from the function policy and
+// removes the statements this tool added earlier which are no longer declared. The policies of
+// all aliases and versions are reconciled, so grants on a qualifier dropped from the config go away.
+func (wrapper ServiceWrapper) ReconcilePermissions(ctx context.Context, functionName string, permissions []common.Permission) error {
+	qualifiers, err := wrapper.qualifiers(ctx, functionName)
+	if err != nil {
+		log.Printf("Not able to list the aliases and versions of %s. The reason is %s", functionName, err.Error())
+		return err
+	}
+	declared := map[string]map[string]common.Permission{"": {}}
+	for _, qualifier := range qualifiers {
+		declared[qualifier] = map[string]common.Permission{}
+	}
+	for _, permission := range permissions {
+		if declared[permission.Qualifier] == nil {
+			declared[permission.Qualifier] = map[string]common.Permission{}
+		}
+		declared[permission.Qualifier][StatementId(permission)] = permission
+	}
+	for qualifier, statements := range declared {
+		existingIds, err := wrapper.statementIds(ctx, functionName, qualifier)
+		if err != nil {
+			log.Printf("Not able to read the policy of %s. The reason is %s", functionName, err.Error())
+			return err
+		}
+		existing := map[string]bool{}
+		for _, id := range existingIds {
+			existing[id] = true
+			if _, ok := statements[id]; ok {
+				continue
+			}
+			log.Println("Removing permission", id)
+			removeInput := &lambda.RemovePermissionInput{FunctionName: &functionName, StatementId: &id}
+			if qualifier != "" {
+				removeInput.Qualifier = &qualifier
+			}
+			if _, err := wrapper.Client.RemovePermission(ctx, removeInput); err != nil {
+				log.Printf("Not able to remove permission %s. The reason is %s", id, err.Error())
+				return err
+			}
+		}
+		for id, permission := range statements {
+			if existing[id] {
+				continue
+			}
+			log.Printf("Adding permission %s for %s\n", id, permission.Principal)
+			if _, err := wrapper.Client.AddPermission(ctx, addPermissionInput(functionName, id, permission)); err != nil {
+				log.Printf("Not able to add permission for %s. The reason is %s", permission.Principal, err.Error())
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func addPermissionInput(functionName string, statementId string, permission common.Permission) *lambda.AddPermissionInput {
+	action := permissionAction(permission)
+	permissionInput := &lambda.AddPermissionInput{
+		FunctionName: &functionName,
+		StatementId:  &statementId,
+		Action:       &action,
+		Principal:    &permission.Principal,
+	}
+	if permission.SourceArn != "" {
+		permissionInput.SourceArn = &permission.SourceArn
+	}
+	if permission.SourceAccount != "" {
+		permissionInput.SourceAccount = &permission.SourceAccount
+	}
+	if permission.Qualifier != "" {
+		permissionInput.Qualifier = &permission.Qualifier
+	}
+	if permission.FunctionUrlAuthType != "" {
+		permissionInput.FunctionUrlAuthType = types.FunctionUrlAuthType(permission.FunctionUrlAuthType)
+	}
+	return permissionInput
+}
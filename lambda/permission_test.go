@@ -0,0 +1,80 @@
+package lambda
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatementId(t *testing.T) {
+	permission := common.Permission{Principal: "s3.amazonaws.com", SourceArn: "arn:aws:s3:::uploads"}
+	id := StatementId(permission)
+	assert.True(t, strings.HasPrefix(id, statementIdPrefix))
+	assert.Equal(t, id, StatementId(permission))
+
+	permission.SourceAccount = "123456789012"
+	assert.NotEqual(t, id, StatementId(permission))
+}
+
+func TestValidatePermissions(t *testing.T) {
+	lambdaParams := validZipParams()
+	lambdaParams.Permissions = []common.Permission{
+		{Principal: "apigateway.amazonaws.com", SourceArn: "arn:aws:execute-api:us-east-1:123456789012:abc/*"},
+		{Principal: "*", FunctionUrlAuthType: "NONE"},
+	}
+	assert.NoError(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.Permissions = []common.Permission{{Principal: "*", Action: "lambda:InvokeFunction", FunctionUrlAuthType: "NONE"}}
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.Permissions = []common.Permission{{SourceArn: "arn:aws:sns:us-east-1:123456789012:topic"}}
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+}
+
+func TestReconcilePermissions(t *testing.T) {
+	mock := &mockFunctionApi{
+		policyStatements: map[string][]string{
+			"":  {"lambda-deploy-stale", "added-by-someone-else"},
+			"3": {"lambda-deploy-old-version"},
+		},
+		aliases:  []string{"live"},
+		versions: []string{"3"},
+	}
+	wrapper := ServiceWrapper{Client: mock}
+	apiGateway := common.Permission{Principal: "apigateway.amazonaws.com", SourceArn: "arn:aws:execute-api:us-east-1:123456789012:abc/*"}
+	live := common.Permission{Principal: "sns.amazonaws.com", Qualifier: "live"}
+
+	assert.NoError(t, wrapper.ReconcilePermissions(context.TODO(), "test", []common.Permission{apiGateway, live}))
+	assert.ElementsMatch(t, []string{"added-by-someone-else", StatementId(apiGateway)}, mock.policyStatements[""])
+	assert.Equal(t, []string{StatementId(live)}, mock.policyStatements["live"])
+	assert.Len(t, mock.addedPermissions, 2)
+	assert.Empty(t, mock.policyStatements["3"])
+
+	// Already in place
+	assert.NoError(t, wrapper.ReconcilePermissions(context.TODO(), "test", []common.Permission{apiGateway, live}))
+	assert.Len(t, mock.addedPermissions, 2)
+
+	// The alias is no longer declared, so its grant goes away as well
+	assert.NoError(t, wrapper.ReconcilePermissions(context.TODO(), "test", nil))
+	assert.Equal(t, []string{"added-by-someone-else"}, mock.policyStatements[""])
+	assert.Empty(t, mock.policyStatements["live"])
+}
+
+func TestPermissionSourceArns(t *testing.T) {
+	mock := &mockFunctionApi{}
+	wrapper := ServiceWrapper{Client: mock}
+	sourceArns, err := wrapper.PermissionSourceArns(context.TODO(), "test", "s3.amazonaws.com")
+	assert.NoError(t, err)
+	assert.Empty(t, sourceArns)
+
+	assert.NoError(t, wrapper.ReconcilePermissions(context.TODO(), "test", []common.Permission{
+		{Principal: "s3.amazonaws.com", SourceArn: "arn:aws:s3:::uploads"},
+		{Principal: "sns.amazonaws.com", SourceArn: "arn:aws:sns:us-east-1:123456789012:orders"},
+	}))
+	sourceArns, err = wrapper.PermissionSourceArns(context.TODO(), "test", "s3.amazonaws.com")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"arn:aws:s3:::uploads"}, sourceArns)
+}
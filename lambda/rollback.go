@@ -0,0 +1,141 @@
+package lambda
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+// FunctionSnapshot holds the code and configuration of a function before a deploy, so it can be restored.
+// ZipFile is set for Zip package functions, ImageUri for Image package ones.
+type FunctionSnapshot struct {
+	Configuration *types.FunctionConfiguration
+	ZipFile       []byte
+	ImageUri      string
+}
+
+// Snapshot captures the function described by GetFunction. The code of a zip function is downloaded
+// right away, as the presigned location expires after 10 minutes.
+func Snapshot(functionDetails *lambda.GetFunctionOutput, download func(location string) ([]byte, error)) (*FunctionSnapshot, error) {
+	snapshot := &FunctionSnapshot{Configuration: functionDetails.Configuration}
+	if functionDetails.Code == nil {
+		return nil, fmt.Errorf("code location of function %s is not available", aws.ToString(functionDetails.Configuration.FunctionName))
+	}
+	if functionDetails.Configuration.PackageType == types.PackageTypeImage {
+		snapshot.ImageUri = aws.ToString(functionDetails.Code.ImageUri)
+		return snapshot, nil
+	}
+	contents, err := download(aws.ToString(functionDetails.Code.Location))
+	if err != nil {
+		log.Printf("Not able to download the code of function %s. The reason is %s", aws.ToString(functionDetails.Configuration.FunctionName), err.Error())
+		return nil, err
+	}
+	snapshot.ZipFile = contents
+	return snapshot, nil
+}
+
+// DownloadCode fetches the code from the presigned location returned by GetFunction
+func DownloadCode(location string) ([]byte, error) {
+	response, err := http.Get(location)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download of the function code failed with status %s", response.Status)
+	}
+	return io.ReadAll(response.Body)
+}
+
+// RestoreCode puts back the code and architectures of the snapshot
+func (wrapper ServiceWrapper) RestoreCode(ctx context.Context, snapshot *FunctionSnapshot) error {
+	log.Println("Restoring code of function--", *snapshot.Configuration.FunctionName)
+	codeInput := &lambda.UpdateFunctionCodeInput{
+		FunctionName:  snapshot.Configuration.FunctionName,
+		Architectures: snapshot.Configuration.Architectures,
+	}
+	if snapshot.ImageUri != "" {
+		codeInput.ImageUri = aws.String(snapshot.ImageUri)
+	} else {
+		codeInput.ZipFile = snapshot.ZipFile
+	}
+	_, err := wrapper.Client.UpdateFunctionCode(ctx, codeInput)
+	return err
+}
+
+// RestoreConfiguration puts back the configuration of the snapshot. Settings the snapshot does not have
+// are cleared, as the deploy may have added them.
+func (wrapper ServiceWrapper) RestoreConfiguration(ctx context.Context, snapshot *FunctionSnapshot) error {
+	log.Println("Restoring configuration of function--", *snapshot.Configuration.FunctionName)
+	previous := snapshot.Configuration
+	configInput := &lambda.UpdateFunctionConfigurationInput{
+		FunctionName:      previous.FunctionName,
+		Description:       previous.Description,
+		MemorySize:        previous.MemorySize,
+		Timeout:           previous.Timeout,
+		Role:              previous.Role,
+		Environment:       &types.Environment{Variables: map[string]string{}},
+		VpcConfig:         &types.VpcConfig{SubnetIds: []string{}, SecurityGroupIds: []string{}},
+		DeadLetterConfig:  &types.DeadLetterConfig{TargetArn: aws.String("")},
+		EphemeralStorage:  previous.EphemeralStorage,
+		FileSystemConfigs: []types.FileSystemConfig{},
+		// An empty key goes back to the key Lambda manages
+		KMSKeyArn: aws.String(aws.ToString(previous.KMSKeyArn)),
+	}
+	// Image functions have neither layers nor SnapStart
+	if previous.PackageType != types.PackageTypeImage {
+		configInput.Handler = previous.Handler
+		configInput.Runtime = previous.Runtime
+		configInput.Layers = []string{}
+		configInput.SnapStart = &types.SnapStart{ApplyOn: types.SnapStartApplyOnNone}
+	}
+	if previous.Environment != nil && previous.Environment.Variables != nil {
+		configInput.Environment.Variables = previous.Environment.Variables
+	}
+	for _, layer := range previous.Layers {
+		configInput.Layers = append(configInput.Layers, *layer.Arn)
+	}
+	if previous.VpcConfig != nil && len(previous.VpcConfig.SubnetIds) > 0 {
+		configInput.VpcConfig = &types.VpcConfig{
+			SubnetIds:               previous.VpcConfig.SubnetIds,
+			SecurityGroupIds:        previous.VpcConfig.SecurityGroupIds,
+			Ipv6AllowedForDualStack: previous.VpcConfig.Ipv6AllowedForDualStack,
+		}
+	}
+	if previous.DeadLetterConfig != nil {
+		configInput.DeadLetterConfig = previous.DeadLetterConfig
+	}
+	if len(previous.FileSystemConfigs) > 0 {
+		configInput.FileSystemConfigs = previous.FileSystemConfigs
+	}
+	if configInput.SnapStart != nil && previous.SnapStart != nil && previous.SnapStart.ApplyOn != "" {
+		configInput.SnapStart.ApplyOn = previous.SnapStart.ApplyOn
+	}
+	if previous.TracingConfig != nil {
+		configInput.TracingConfig = &types.TracingConfig{Mode: previous.TracingConfig.Mode}
+	}
+	if previous.LoggingConfig != nil {
+		configInput.LoggingConfig = previous.LoggingConfig
+	}
+	if previous.ImageConfigResponse != nil && previous.ImageConfigResponse.ImageConfig != nil {
+		configInput.ImageConfig = previous.ImageConfigResponse.ImageConfig
+	}
+	_, err := wrapper.Client.UpdateFunctionConfiguration(ctx, configInput)
+	return err
+}
+
+// WaitUntilUpdated waits for the function to become active and for its last update to finish
+func (wrapper ServiceWrapper) WaitUntilUpdated(ctx context.Context, functionName string, maxWait time.Duration) error {
+	getInput := &lambda.GetFunctionInput{FunctionName: aws.String(functionName)}
+	if err := lambda.NewFunctionActiveV2Waiter(wrapper.Client).Wait(ctx, getInput, maxWait); err != nil {
+		return err
+	}
+	return lambda.NewFunctionUpdatedV2Waiter(wrapper.Client).Wait(ctx, getInput, maxWait)
+}
@@ -0,0 +1,67 @@
+package lambda
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotAndRestore(t *testing.T) {
+	functionDetails := &lambda.GetFunctionOutput{
+		Configuration: &types.FunctionConfiguration{
+			FunctionName:  aws.String("test"),
+			PackageType:   types.PackageTypeZip,
+			Handler:       aws.String("bootstrap"),
+			Runtime:       types.RuntimeProvidedal2023,
+			MemorySize:    aws.Int32(256),
+			Architectures: []types.Architecture{types.ArchitectureArm64},
+			Environment:   &types.EnvironmentResponse{Variables: map[string]string{"STAGE": "prod"}},
+			Layers:        []types.Layer{{Arn: aws.String("arn:aws:lambda:us-east-1:123456789012:layer:shared:3")}},
+		},
+		Code: &types.FunctionCodeLocation{Location: aws.String("https://example.com/code.zip")},
+	}
+	var downloaded string
+	snapshot, err := Snapshot(functionDetails, func(location string) ([]byte, error) {
+		downloaded = location
+		return []byte("zip"), nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/code.zip", downloaded)
+
+	mock := &mockFunctionApi{}
+	wrapper := ServiceWrapper{Client: mock}
+	assert.NoError(t, wrapper.RestoreCode(context.TODO(), snapshot))
+	assert.Equal(t, []byte("zip"), mock.codeInput.ZipFile)
+	assert.Equal(t, []types.Architecture{types.ArchitectureArm64}, mock.codeInput.Architectures)
+
+	assert.NoError(t, wrapper.RestoreConfiguration(context.TODO(), snapshot))
+	assert.Equal(t, "bootstrap", *mock.configInput.Handler)
+	assert.Equal(t, int32(256), *mock.configInput.MemorySize)
+	assert.Equal(t, map[string]string{"STAGE": "prod"}, mock.configInput.Environment.Variables)
+	assert.Equal(t, []string{"arn:aws:lambda:us-east-1:123456789012:layer:shared:3"}, mock.configInput.Layers)
+	// The deploy may have attached a VPC or a DLQ, so they are cleared
+	assert.Empty(t, mock.configInput.VpcConfig.SubnetIds)
+	assert.Equal(t, "", *mock.configInput.DeadLetterConfig.TargetArn)
+}
+
+func TestSnapshotImage(t *testing.T) {
+	functionDetails := &lambda.GetFunctionOutput{
+		Configuration: &types.FunctionConfiguration{FunctionName: aws.String("test"), PackageType: types.PackageTypeImage},
+		Code:          &types.FunctionCodeLocation{ImageUri: aws.String("123456789012.dkr.ecr.us-east-1.amazonaws.com/orders:1")},
+	}
+	snapshot, err := Snapshot(functionDetails, nil)
+	assert.NoError(t, err)
+	mock := &mockFunctionApi{}
+	wrapper := ServiceWrapper{Client: mock}
+	assert.NoError(t, wrapper.RestoreCode(context.TODO(), snapshot))
+	assert.Equal(t, "123456789012.dkr.ecr.us-east-1.amazonaws.com/orders:1", *mock.codeInput.ImageUri)
+	assert.NoError(t, wrapper.RestoreConfiguration(context.TODO(), snapshot))
+	assert.Nil(t, mock.configInput.Handler)
+	// Lambda rejects layers and SnapStart on image functions
+	assert.Nil(t, mock.configInput.Layers)
+	assert.Nil(t, mock.configInput.SnapStart)
+}
@@ -0,0 +1,83 @@
+package lambda
+
+import (
+	"context"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+func validateRuntimeSettings(lambdaParams common.DeployParams, errorMessage *strings.Builder) {
+	snapStart := lambdaParams.SnapStart
+	if snapStart != "" && !slices.Contains(types.SnapStartApplyOn("").Values(), types.SnapStartApplyOn(snapStart)) {
+		errorMessage.WriteString("SnapStart must be either PublishedVersions or None.\n")
+	}
+	if snapStart == string(types.SnapStartApplyOnPublishedVersions) {
+		if lambdaParams.IsImage() || (lambdaParams.Runtime != "" && !strings.HasPrefix(lambdaParams.Runtime, "java")) {
+			errorMessage.WriteString("SnapStart can only be used with Java runtimes.\n")
+		}
+		if lambdaParams.ProvisionedConcurrency != nil {
+			errorMessage.WriteString("SnapStart cannot be used with Provisioned Concurrency.\n")
+		}
+		if len(lambdaParams.FileSystemConfigs) > 0 {
+			errorMessage.WriteString("SnapStart cannot be used with file systems.\n")
+		}
+		if lambdaParams.EphemeralStorage > minEphemeralStorage {
+			errorMessage.WriteString("SnapStart cannot be used with more than 512 MB of ephemeral storage.\n")
+		}
+	}
+	runtimeManagement := lambdaParams.RuntimeManagement
+	if runtimeManagement == nil {
+		return
+	}
+	if lambdaParams.IsImage() {
+		errorMessage.WriteString("Runtime management can only be used with Zip package type.\n")
+	}
+	if !slices.Contains(types.UpdateRuntimeOn("").Values(), types.UpdateRuntimeOn(runtimeManagement.UpdateRuntimeOn)) {
+		errorMessage.WriteString("Runtime management update_runtime_on must be one of Auto, FunctionUpdate or Manual.\n")
+	}
+	manual := runtimeManagement.UpdateRuntimeOn == string(types.UpdateRuntimeOnManual)
+	if manual && common.TrimAndCheckEmptyString(&runtimeManagement.RuntimeVersionArn) {
+		errorMessage.WriteString("Runtime version ARN must be specified with the Manual update mode.\n")
+	}
+	if !manual && !common.TrimAndCheckEmptyString(&runtimeManagement.RuntimeVersionArn) {
+		errorMessage.WriteString("Runtime version ARN can only be used with the Manual update mode.\n")
+	}
+}
+
+func snapStart(applyOn string) *types.SnapStart {
+	if applyOn == "" {
+		return nil
+	}
+	return &types.SnapStart{ApplyOn: types.SnapStartApplyOn(applyOn)}
+}
+
+// PutRuntimeManagement sets when the runtime of the function is updated. Without runtime_management
+// the mode goes back to Auto, the default of Lambda. Image functions have no managed runtime.
+func (wrapper ServiceWrapper) PutRuntimeManagement(ctx context.Context, lambdaParams common.DeployParams) error {
+	if lambdaParams.IsImage() {
+		return nil
+	}
+	runtimeManagement := lambdaParams.RuntimeManagement
+	if runtimeManagement == nil {
+		runtimeManagement = &common.RuntimeManagementConfig{UpdateRuntimeOn: string(types.UpdateRuntimeOnAuto)}
+	}
+	log.Println("Updating runtime management configuration-----")
+	configInput := &lambda.PutRuntimeManagementConfigInput{
+		FunctionName:    aws.String(lambdaParams.FunctionName),
+		UpdateRuntimeOn: types.UpdateRuntimeOn(runtimeManagement.UpdateRuntimeOn),
+	}
+	if !common.TrimAndCheckEmptyString(&runtimeManagement.RuntimeVersionArn) {
+		configInput.RuntimeVersionArn = aws.String(runtimeManagement.RuntimeVersionArn)
+	}
+	_, err := wrapper.Client.PutRuntimeManagementConfig(ctx, configInput)
+	if err != nil {
+		log.Printf("Not able to update the runtime management configuration. The reason is %s", err.Error())
+	}
+	return err
+}
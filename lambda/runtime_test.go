@@ -0,0 +1,69 @@
+package lambda
+
+import (
+	"context"
+	"testing"
+
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const runtimeVersionArn = "arn:aws:lambda:us-east-1::runtime:0af1966588ced06e3143ae720245c9b7aeaae213c6921c12c742a166679cc505"
+
+func TestValidateSnapStart(t *testing.T) {
+	lambdaParams := validZipParams()
+	lambdaParams.Runtime = "java21"
+	lambdaParams.SnapStart = "PublishedVersions"
+	assert.NoError(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.SnapStart = "Always"
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.SnapStart = "PublishedVersions"
+	lambdaParams.Runtime = "provided.al2023"
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.Runtime = "java21"
+	lambdaParams.EphemeralStorage = 1024
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.EphemeralStorage = 0
+	lambdaParams.ProvisionedConcurrency = &common.ProvisionedConcurrencyConfig{Qualifier: "live", Count: 1}
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+}
+
+func TestValidateRuntimeManagement(t *testing.T) {
+	lambdaParams := validZipParams()
+	lambdaParams.RuntimeManagement = &common.RuntimeManagementConfig{UpdateRuntimeOn: "Manual", RuntimeVersionArn: runtimeVersionArn}
+	assert.NoError(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.RuntimeManagement = &common.RuntimeManagementConfig{UpdateRuntimeOn: "Manual"}
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.RuntimeManagement = &common.RuntimeManagementConfig{UpdateRuntimeOn: "Auto", RuntimeVersionArn: runtimeVersionArn}
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.RuntimeManagement = &common.RuntimeManagementConfig{UpdateRuntimeOn: "Never"}
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+}
+
+func TestPutRuntimeManagement(t *testing.T) {
+	mock := &mockFunctionApi{}
+	wrapper := ServiceWrapper{Client: mock}
+	lambdaParams := common.DeployParams{FunctionName: "test", ImageUri: "123456789012.dkr.ecr.us-east-1.amazonaws.com/orders:latest"}
+	assert.NoError(t, wrapper.PutRuntimeManagement(context.TODO(), lambdaParams))
+	assert.Nil(t, mock.runtimeManagement)
+
+	lambdaParams.ImageUri = ""
+	lambdaParams.RuntimeManagement = &common.RuntimeManagementConfig{UpdateRuntimeOn: "Manual", RuntimeVersionArn: runtimeVersionArn}
+	assert.NoError(t, wrapper.PutRuntimeManagement(context.TODO(), lambdaParams))
+	assert.Equal(t, types.UpdateRuntimeOnManual, mock.runtimeManagement.UpdateRuntimeOn)
+	assert.Equal(t, runtimeVersionArn, *mock.runtimeManagement.RuntimeVersionArn)
+
+	// Removing runtime_management goes back to Auto
+	lambdaParams.RuntimeManagement = nil
+	assert.NoError(t, wrapper.PutRuntimeManagement(context.TODO(), lambdaParams))
+	assert.Equal(t, types.UpdateRuntimeOnAuto, mock.runtimeManagement.UpdateRuntimeOn)
+	assert.Nil(t, mock.runtimeManagement.RuntimeVersionArn)
+}
@@ -0,0 +1,80 @@
+package lambda
+
+import (
+	"fmt"
+	"github.com/a-pavithraa/lambda-deploy/secret"
+	"math"
+	"regexp"
+)
+
+// SecretFinding is a variable whose value looks like a secret. It never holds the value itself.
+type SecretFinding struct {
+	Variable string
+	Kind     string
+}
+
+func (finding SecretFinding) String() string {
+	return fmt.Sprintf("Environment variable %s looks like it holds %s", finding.Variable, finding.Kind)
+}
+
+var secretPatterns = []struct {
+	kind    string
+	pattern *regexp.Regexp
+}{
+	{"an AWS access key id", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"a private key", regexp.MustCompile(`-----BEGIN ([A-Z0-9]+ )*PRIVATE KEY( BLOCK)?-----`)},
+	{"a GitHub token", regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"a Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+}
+
+// Runs of token characters long and random enough to be keys rather than words or identifiers
+var (
+	tokenPattern     = regexp.MustCompile(`[A-Za-z0-9+/=_\-]{32,}`)
+	minTokenEntropy  = 4.3
+	awsSecretPattern = regexp.MustCompile(`^[A-Za-z0-9/+]{40}$`)
+)
+
+// ScanSecrets looks for values which appear to hold secrets. Secret references are not scanned,
+// as their values are only resolved at deploy time.
+func ScanSecrets(variables map[string]string) []SecretFinding {
+	var findings []SecretFinding
+	for _, name := range sortedKeys(variables) {
+		value := secret.WithoutReferences(variables[name])
+		if kind := secretKind(value); kind != "" {
+			findings = append(findings, SecretFinding{Variable: name, Kind: kind})
+		}
+	}
+	return findings
+}
+
+func secretKind(value string) string {
+	for _, secretPattern := range secretPatterns {
+		if secretPattern.pattern.MatchString(value) {
+			return secretPattern.kind
+		}
+	}
+	if awsSecretPattern.MatchString(value) && entropy(value) >= minTokenEntropy {
+		return "an AWS secret access key"
+	}
+	for _, token := range tokenPattern.FindAllString(value, -1) {
+		if entropy(token) >= minTokenEntropy {
+			return "a high-entropy token"
+		}
+	}
+	return ""
+}
+
+// entropy is the Shannon entropy of the text in bits per character
+func entropy(text string) float64 {
+	counts := map[rune]int{}
+	for _, character := range text {
+		counts[character]++
+	}
+	length := float64(len([]rune(text)))
+	result := 0.0
+	for _, count := range counts {
+		probability := float64(count) / length
+		result -= probability * math.Log2(probability)
+	}
+	return result
+}
@@ -55,15 +55,70 @@ func ValidateInputParams(lambdaParams common.DeployParams, createFlag bool) erro
 		errorMessage.WriteString("Function Name cannot be null.\n")
 	}
 	checkS3 := !common.TrimAndCheckEmptyString(&lambdaParams.BucketName) && !common.TrimAndCheckEmptyString(&lambdaParams.KeyName)
-	if !checkS3 && common.TrimAndCheckEmptyString(&lambdaParams.ZipFile) {
-		errorMessage.WriteString("Either S3 Bucket and Key or Zip file has to be included.\n")
+	checkZip := !common.TrimAndCheckEmptyString(&lambdaParams.ZipFile)
+	checkImage := !common.TrimAndCheckEmptyString(&lambdaParams.ImageUri)
+	codeSources := 0
+	for _, present := range []bool{checkS3, checkZip, checkImage} {
+		if present {
+			codeSources++
+		}
+	}
+	if codeSources == 0 {
+		errorMessage.WriteString("Either S3 Bucket and Key, Zip file or Image URI has to be included.\n")
+	}
+	if codeSources > 1 {
+		errorMessage.WriteString("Only one of S3 Bucket and Key, Zip file or Image URI can be included.\n")
+	}
+	if !common.TrimAndCheckEmptyString(&lambdaParams.PackageType) &&
+		lambdaParams.PackageType != string(types.PackageTypeZip) && lambdaParams.PackageType != string(types.PackageTypeImage) {
+		errorMessage.WriteString("Package Type must be either Zip or Image.\n")
+	}
+	if lambdaParams.Vpc != nil {
+		if len(lambdaParams.Vpc.SubnetIds) > 0 && len(lambdaParams.Vpc.SecurityGroupIds) == 0 {
+			errorMessage.WriteString("Security Group Ids must be specified along with Subnet Ids.\n")
+		}
+		if len(lambdaParams.Vpc.SubnetIds) == 0 && len(lambdaParams.Vpc.SecurityGroupIds) > 0 {
+			errorMessage.WriteString("Subnet Ids must be specified along with Security Group Ids.\n")
+		}
 	}
-	if createFlag {
-		if common.TrimAndCheckEmptyString(&lambdaParams.Runtime) {
-			errorMessage.WriteString("Runtime must be specified.\n")
+	validateAsyncParams(lambdaParams, &errorMessage)
+	validatePermissions(lambdaParams.Permissions, &errorMessage)
+	validateFunctionUrl(lambdaParams.FunctionUrl, &errorMessage)
+	validateConcurrency(lambdaParams, &errorMessage)
+	validateTags(lambdaParams.Tags, &errorMessage)
+	validateEnvironment(lambdaParams, &errorMessage)
+	validateObservability(lambdaParams, &errorMessage)
+	validateStorage(lambdaParams, createFlag, &errorMessage)
+	validateRuntimeSettings(lambdaParams, &errorMessage)
+	if len(lambdaParams.Architectures) > 1 {
+		errorMessage.WriteString("Only one architecture can be specified.\n")
+	}
+	for _, architecture := range lambdaParams.Architectures {
+		if architecture != string(types.ArchitectureX8664) && architecture != string(types.ArchitectureArm64) {
+			errorMessage.WriteString("Architecture must be either x86_64 or arm64.\n")
+		}
+	}
+	if lambdaParams.IsImage() {
+		if !checkImage {
+			errorMessage.WriteString("Image URI must be specified for Image package type.\n")
+		}
+		if lambdaParams.PackageType == string(types.PackageTypeZip) {
+			errorMessage.WriteString("Image URI cannot be used with Zip package type.\n")
 		}
-		if common.TrimAndCheckEmptyString(&lambdaParams.HandlerName) {
-			errorMessage.WriteString("HandlerName must be specified.\n")
+		if len(lambdaParams.Layers) > 0 {
+			errorMessage.WriteString("Layers cannot be used with Image package type.\n")
+		}
+	} else {
+		if lambdaParams.ImageConfig != nil {
+			errorMessage.WriteString("Image Config can only be used with Image package type.\n")
+		}
+		if createFlag {
+			if common.TrimAndCheckEmptyString(&lambdaParams.Runtime) {
+				errorMessage.WriteString("Runtime must be specified.\n")
+			}
+			if common.TrimAndCheckEmptyString(&lambdaParams.HandlerName) {
+				errorMessage.WriteString("HandlerName must be specified.\n")
+			}
 		}
 	}
 
@@ -97,6 +152,48 @@ func (wrapper ServiceWrapper) UpdateFunctionConfiguration(ctx context.Context, l
 	if !common.TrimAndCheckEmptyString(&lambdaParams.RoleArn) {
 		configInput.Role = &lambdaParams.RoleArn
 	}
+	// An empty key goes back to the Lambda managed key once kms_key_arn is removed
+	common.TrimAndCheckEmptyString(&lambdaParams.KmsKeyArn)
+	configInput.KMSKeyArn = &lambdaParams.KmsKeyArn
+	if lambdaParams.ImageConfig != nil {
+		configInput.ImageConfig = imageConfig(lambdaParams.ImageConfig)
+	}
+	if !lambdaParams.IsImage() {
+		// An empty list detaches the layers removed from the config
+		configInput.Layers = []string{}
+		if len(lambdaParams.Layers) > 0 {
+			configInput.Layers = lambdaParams.Layers
+		}
+	}
+	if lambdaParams.Vpc != nil {
+		configInput.VpcConfig = vpcConfig(lambdaParams.Vpc)
+	}
+	// An empty target detaches the dead letter queue once dead_letter_target_arn is removed
+	common.TrimAndCheckEmptyString(&lambdaParams.DeadLetterTargetArn)
+	configInput.DeadLetterConfig = &types.DeadLetterConfig{TargetArn: &lambdaParams.DeadLetterTargetArn}
+	configInput.TracingConfig = tracingConfig(lambdaParams.Tracing)
+	if configInput.TracingConfig == nil {
+		// PassThrough is the default of Lambda, so removing tracing turns off active tracing
+		configInput.TracingConfig = &types.TracingConfig{Mode: types.TracingModePassThrough}
+	}
+	if lambdaParams.Logging == nil {
+		// Removing logging goes back to text logs in the default log group
+		lambdaParams.Logging = &common.LoggingConfig{}
+	}
+	configInput.LoggingConfig = loggingConfig(lambdaParams)
+	// Storage and SnapStart removed from the config go back to the defaults of Lambda
+	configInput.EphemeralStorage = ephemeralStorage(lambdaParams.EphemeralStorage)
+	if configInput.EphemeralStorage == nil {
+		configInput.EphemeralStorage = ephemeralStorage(minEphemeralStorage)
+	}
+	configInput.FileSystemConfigs = fileSystemConfigs(lambdaParams.FileSystemConfigs)
+	if configInput.FileSystemConfigs == nil {
+		configInput.FileSystemConfigs = []types.FileSystemConfig{}
+	}
+	configInput.SnapStart = snapStart(lambdaParams.SnapStart)
+	if configInput.SnapStart == nil && !lambdaParams.IsImage() {
+		configInput.SnapStart = snapStart(string(types.SnapStartApplyOnNone))
+	}
 	_, err := wrapper.Client.UpdateFunctionConfiguration(ctx, configInput)
 
 	return err
@@ -119,8 +216,16 @@ func (wrapper ServiceWrapper) UpdateFunction(ctx context.Context, lambdaParams c
 			log.Println(err)
 			return err
 		}
+		if err = CheckBootstrapArchitecture(contents, lambdaParams.Architectures); err != nil {
+			log.Println(err)
+			return err
+		}
 		functionInput.ZipFile = contents
 	}
+	if !common.TrimAndCheckEmptyString(&lambdaParams.ImageUri) {
+		functionInput.ImageUri = &lambdaParams.ImageUri
+	}
+	functionInput.Architectures = architectures(lambdaParams.Architectures)
 	_, err := wrapper.Client.UpdateFunctionCode(ctx, functionInput)
 	if err != nil {
 		log.Fatal(err)
@@ -129,6 +234,39 @@ func (wrapper ServiceWrapper) UpdateFunction(ctx context.Context, lambdaParams c
 	return err
 }
 
+func architectures(names []string) []types.Architecture {
+	var result []types.Architecture
+	for _, name := range names {
+		result = append(result, types.Architecture(name))
+	}
+	return result
+}
+
+func vpcConfig(config *common.VpcConfig) *types.VpcConfig {
+	// Empty lists detach the function from the VPC
+	result := &types.VpcConfig{
+		SubnetIds:        []string{},
+		SecurityGroupIds: []string{},
+	}
+	if config.Attached() {
+		result.SubnetIds = config.SubnetIds
+		result.SecurityGroupIds = config.SecurityGroupIds
+		result.Ipv6AllowedForDualStack = &config.Ipv6AllowedForDualStack
+	}
+	return result
+}
+
+func imageConfig(config *common.ImageConfig) *types.ImageConfig {
+	result := &types.ImageConfig{
+		EntryPoint: config.EntryPoint,
+		Command:    config.Command,
+	}
+	if !common.TrimAndCheckEmptyString(&config.WorkingDirectory) {
+		result.WorkingDirectory = &config.WorkingDirectory
+	}
+	return result
+}
+
 func GetFunctionCodeFromZip(fileName string) ([]byte, error) {
 
 	zipFile, err := os.Open(fileName)
@@ -148,21 +286,56 @@ func GetFunctionCodeFromZip(fileName string) ([]byte, error) {
 
 func (wrapper ServiceWrapper) New(ctx context.Context, lambdaParams common.DeployParams, iamWrapper iam.ServiceWrapper) (*lambda.CreateFunctionOutput, error) {
 
-	roleArn, _ := iamWrapper.CreateRole(ctx, lambdaParams)
+	roleArn, err := iamWrapper.CreateRole(ctx, lambdaParams)
+	if err != nil {
+		return nil, err
+	}
 	memory := int32(lambdaParams.Memory)
 	timeout := int32(lambdaParams.Timeout)
 	functionInput := &lambda.CreateFunctionInput{
 
-		FunctionName: &lambdaParams.FunctionName,
-		Role:         roleArn,
-		Runtime:      types.Runtime(lambdaParams.Runtime),
-		Handler:      &lambdaParams.HandlerName,
-		MemorySize:   &memory,
-		Timeout:      &timeout,
+		FunctionName:  &lambdaParams.FunctionName,
+		Role:          roleArn,
+		MemorySize:    &memory,
+		Timeout:       &timeout,
+		Architectures: architectures(lambdaParams.Architectures),
+		Tags:          lambdaParams.ResourceTags(),
+	}
+	if lambdaParams.IsImage() {
+		// Runtime and handler come from the image itself
+		functionInput.PackageType = types.PackageTypeImage
+		functionInput.Code = &types.FunctionCode{
+			ImageUri: &lambdaParams.ImageUri,
+		}
+		if lambdaParams.ImageConfig != nil {
+			functionInput.ImageConfig = imageConfig(lambdaParams.ImageConfig)
+		}
+	} else {
+		functionInput.Runtime = types.Runtime(lambdaParams.Runtime)
+		functionInput.Handler = &lambdaParams.HandlerName
 	}
 	if lambdaParams.EnvironmentVariables != nil {
 		functionInput.Environment = &types.Environment{Variables: lambdaParams.EnvironmentVariables}
 	}
+	if !common.TrimAndCheckEmptyString(&lambdaParams.KmsKeyArn) {
+		functionInput.KMSKeyArn = &lambdaParams.KmsKeyArn
+	}
+	if len(lambdaParams.Layers) > 0 {
+		functionInput.Layers = lambdaParams.Layers
+	}
+	if lambdaParams.Vpc.Attached() {
+		functionInput.VpcConfig = vpcConfig(lambdaParams.Vpc)
+	}
+	if !common.TrimAndCheckEmptyString(&lambdaParams.DeadLetterTargetArn) {
+		functionInput.DeadLetterConfig = &types.DeadLetterConfig{TargetArn: &lambdaParams.DeadLetterTargetArn}
+	}
+	functionInput.TracingConfig = tracingConfig(lambdaParams.Tracing)
+	functionInput.LoggingConfig = loggingConfig(lambdaParams)
+	functionInput.EphemeralStorage = ephemeralStorage(lambdaParams.EphemeralStorage)
+	functionInput.SnapStart = snapStart(lambdaParams.SnapStart)
+	if len(lambdaParams.FileSystemConfigs) > 0 {
+		functionInput.FileSystemConfigs = fileSystemConfigs(lambdaParams.FileSystemConfigs)
+	}
 	if !common.TrimAndCheckEmptyString(&lambdaParams.BucketName) && !common.TrimAndCheckEmptyString(&lambdaParams.KeyName) {
 		functionInput.Code = &types.FunctionCode{
 			S3Bucket: &lambdaParams.BucketName,
@@ -177,6 +350,10 @@ func (wrapper ServiceWrapper) New(ctx context.Context, lambdaParams common.Deplo
 			log.Println(err)
 			return nil, err
 		}
+		if err = CheckBootstrapArchitecture(contents, lambdaParams.Architectures); err != nil {
+			log.Println(err)
+			return nil, err
+		}
 		functionInput.Code = &types.FunctionCode{
 			ZipFile: contents,
 		}
@@ -187,7 +364,8 @@ func (wrapper ServiceWrapper) New(ctx context.Context, lambdaParams common.Deplo
 		fmt.Println(err)
 		return nil, err
 	}
-	fmt.Println(output)
+	// The output is not printed as a whole, as the environment may hold resolved secrets
+	log.Println("Created function--", *output.FunctionArn)
 	return output, nil
 }
 func (wrapper ServiceWrapper) Delete(ctx context.Context, name string) (*lambda.GetFunctionOutput, error) {
@@ -207,7 +385,7 @@ func (wrapper ServiceWrapper) Delete(ctx context.Context, name string) (*lambda.
 		log.Printf("Not able to delete the function. The reason is %s", err.Error())
 		return nil, err
 	}
-	log.Println(functionDetails)
+	log.Println("Deleted function--", *functionDetails.Configuration.FunctionArn)
 
 	return functionDetails, nil
 }
@@ -3,13 +3,36 @@ package lambda
 import (
 	"context"
 	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
 	"github.com/stretchr/testify/assert"
+	"strings"
 	"testing"
 )
 
 type mockFunctionApi struct {
-	Client FunctionApi
+	Client                FunctionApi
+	layerVersions         []types.LayerVersionsListItem
+	deletedLayerVersions  []int64
+	eventInvokeConfig     *lambda.PutFunctionEventInvokeConfigInput
+	policyStatements      map[string][]string
+	addedPermissions      []*lambda.AddPermissionInput
+	functionUrls          map[string]*types.FunctionUrlConfig
+	invokeInput           *lambda.InvokeInput
+	invokeOutput          *lambda.InvokeOutput
+	codeInput             *lambda.UpdateFunctionCodeInput
+	configInput           *lambda.UpdateFunctionConfigurationInput
+	unreservedConcurrency *int32
+	reservedConcurrency   *int32
+	provisionedConfigs    map[string]int32
+	provisionedStatuses   []types.ProvisionedConcurrencyStatusEnum
+	deletedProvisioned    []string
+	tags                  map[string]string
+	untaggedKeys          []string
+	runtimeManagement     *lambda.PutRuntimeManagementConfigInput
+	aliases               []string
+	versions              []string
 }
 
 func (m *mockFunctionApi) GetFunction(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(options *lambda.Options)) (*lambda.GetFunctionOutput, error) {
@@ -17,11 +40,13 @@ func (m *mockFunctionApi) GetFunction(ctx context.Context, params *lambda.GetFun
 }
 
 func (m *mockFunctionApi) UpdateFunctionCode(ctx context.Context, params *lambda.UpdateFunctionCodeInput, optFns ...func(*lambda.Options)) (*lambda.UpdateFunctionCodeOutput, error) {
+	m.codeInput = params
 	return &lambda.UpdateFunctionCodeOutput{
 		FunctionName: params.FunctionName,
 	}, nil
 }
 func (m *mockFunctionApi) UpdateFunctionConfiguration(ctx context.Context, params *lambda.UpdateFunctionConfigurationInput, optFns ...func(*lambda.Options)) (*lambda.UpdateFunctionConfigurationOutput, error) {
+	m.configInput = params
 	return &lambda.UpdateFunctionConfigurationOutput{
 		FunctionName: params.FunctionName,
 	}, nil
@@ -36,6 +61,231 @@ func (m *mockFunctionApi) DeleteFunction(ctx context.Context, params *lambda.Del
 	return nil, nil
 }
 
+func (m *mockFunctionApi) PublishLayerVersion(ctx context.Context, params *lambda.PublishLayerVersionInput, optFns ...func(*lambda.Options)) (*lambda.PublishLayerVersionOutput, error) {
+	return &lambda.PublishLayerVersionOutput{
+		LayerVersionArn: aws.String("arn:aws:lambda:us-east-1:123456789012:layer:" + *params.LayerName + ":1"),
+		Version:         1,
+	}, nil
+}
+
+func (m *mockFunctionApi) ListLayerVersions(ctx context.Context, params *lambda.ListLayerVersionsInput, optFns ...func(*lambda.Options)) (*lambda.ListLayerVersionsOutput, error) {
+	return &lambda.ListLayerVersionsOutput{
+		LayerVersions: m.layerVersions,
+	}, nil
+}
+
+func (m *mockFunctionApi) DeleteLayerVersion(ctx context.Context, params *lambda.DeleteLayerVersionInput, optFns ...func(*lambda.Options)) (*lambda.DeleteLayerVersionOutput, error) {
+	m.deletedLayerVersions = append(m.deletedLayerVersions, *params.VersionNumber)
+	return &lambda.DeleteLayerVersionOutput{}, nil
+}
+
+func (m *mockFunctionApi) Invoke(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	m.invokeInput = params
+	return m.invokeOutput, nil
+}
+
+func (m *mockFunctionApi) GetAccountSettings(ctx context.Context, params *lambda.GetAccountSettingsInput, optFns ...func(*lambda.Options)) (*lambda.GetAccountSettingsOutput, error) {
+	return &lambda.GetAccountSettingsOutput{
+		AccountLimit: &types.AccountLimit{ConcurrentExecutions: 1000, UnreservedConcurrentExecutions: m.unreservedConcurrency},
+	}, nil
+}
+
+func (m *mockFunctionApi) GetFunctionConcurrency(ctx context.Context, params *lambda.GetFunctionConcurrencyInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionConcurrencyOutput, error) {
+	return &lambda.GetFunctionConcurrencyOutput{ReservedConcurrentExecutions: m.reservedConcurrency}, nil
+}
+
+func (m *mockFunctionApi) PutFunctionConcurrency(ctx context.Context, params *lambda.PutFunctionConcurrencyInput, optFns ...func(*lambda.Options)) (*lambda.PutFunctionConcurrencyOutput, error) {
+	m.reservedConcurrency = params.ReservedConcurrentExecutions
+	return &lambda.PutFunctionConcurrencyOutput{ReservedConcurrentExecutions: params.ReservedConcurrentExecutions}, nil
+}
+
+func (m *mockFunctionApi) DeleteFunctionConcurrency(ctx context.Context, params *lambda.DeleteFunctionConcurrencyInput, optFns ...func(*lambda.Options)) (*lambda.DeleteFunctionConcurrencyOutput, error) {
+	m.reservedConcurrency = nil
+	return &lambda.DeleteFunctionConcurrencyOutput{}, nil
+}
+
+func (m *mockFunctionApi) ListProvisionedConcurrencyConfigs(ctx context.Context, params *lambda.ListProvisionedConcurrencyConfigsInput, optFns ...func(*lambda.Options)) (*lambda.ListProvisionedConcurrencyConfigsOutput, error) {
+	output := &lambda.ListProvisionedConcurrencyConfigsOutput{}
+	for qualifier, count := range m.provisionedConfigs {
+		output.ProvisionedConcurrencyConfigs = append(output.ProvisionedConcurrencyConfigs, types.ProvisionedConcurrencyConfigListItem{
+			FunctionArn:                              aws.String("arn:aws:lambda:us-east-1:123456789012:function:" + *params.FunctionName + ":" + qualifier),
+			RequestedProvisionedConcurrentExecutions: aws.Int32(count),
+		})
+	}
+	return output, nil
+}
+
+func (m *mockFunctionApi) GetProvisionedConcurrencyConfig(ctx context.Context, params *lambda.GetProvisionedConcurrencyConfigInput, optFns ...func(*lambda.Options)) (*lambda.GetProvisionedConcurrencyConfigOutput, error) {
+	status := m.provisionedStatuses[0]
+	if len(m.provisionedStatuses) > 1 {
+		m.provisionedStatuses = m.provisionedStatuses[1:]
+	}
+	output := &lambda.GetProvisionedConcurrencyConfigOutput{
+		Status:                                   status,
+		RequestedProvisionedConcurrentExecutions: aws.Int32(m.provisionedConfigs[*params.Qualifier]),
+	}
+	if status == types.ProvisionedConcurrencyStatusEnumFailed {
+		output.StatusReason = aws.String("FUNCTION_ERROR_INIT_FAILURE")
+	}
+	return output, nil
+}
+
+func (m *mockFunctionApi) PutProvisionedConcurrencyConfig(ctx context.Context, params *lambda.PutProvisionedConcurrencyConfigInput, optFns ...func(*lambda.Options)) (*lambda.PutProvisionedConcurrencyConfigOutput, error) {
+	if m.provisionedConfigs == nil {
+		m.provisionedConfigs = map[string]int32{}
+	}
+	m.provisionedConfigs[*params.Qualifier] = *params.ProvisionedConcurrentExecutions
+	return &lambda.PutProvisionedConcurrencyConfigOutput{Status: types.ProvisionedConcurrencyStatusEnumInProgress}, nil
+}
+
+func (m *mockFunctionApi) DeleteProvisionedConcurrencyConfig(ctx context.Context, params *lambda.DeleteProvisionedConcurrencyConfigInput, optFns ...func(*lambda.Options)) (*lambda.DeleteProvisionedConcurrencyConfigOutput, error) {
+	delete(m.provisionedConfigs, *params.Qualifier)
+	m.deletedProvisioned = append(m.deletedProvisioned, *params.Qualifier)
+	return &lambda.DeleteProvisionedConcurrencyConfigOutput{}, nil
+}
+
+func (m *mockFunctionApi) ListTags(ctx context.Context, params *lambda.ListTagsInput, optFns ...func(*lambda.Options)) (*lambda.ListTagsOutput, error) {
+	return &lambda.ListTagsOutput{Tags: m.tags}, nil
+}
+
+func (m *mockFunctionApi) TagResource(ctx context.Context, params *lambda.TagResourceInput, optFns ...func(*lambda.Options)) (*lambda.TagResourceOutput, error) {
+	if m.tags == nil {
+		m.tags = map[string]string{}
+	}
+	for key, value := range params.Tags {
+		m.tags[key] = value
+	}
+	return &lambda.TagResourceOutput{}, nil
+}
+
+func (m *mockFunctionApi) UntagResource(ctx context.Context, params *lambda.UntagResourceInput, optFns ...func(*lambda.Options)) (*lambda.UntagResourceOutput, error) {
+	for _, key := range params.TagKeys {
+		delete(m.tags, key)
+	}
+	m.untaggedKeys = append(m.untaggedKeys, params.TagKeys...)
+	return &lambda.UntagResourceOutput{}, nil
+}
+
+func (m *mockFunctionApi) PutRuntimeManagementConfig(ctx context.Context, params *lambda.PutRuntimeManagementConfigInput, optFns ...func(*lambda.Options)) (*lambda.PutRuntimeManagementConfigOutput, error) {
+	m.runtimeManagement = params
+	return &lambda.PutRuntimeManagementConfigOutput{}, nil
+}
+
+func (m *mockFunctionApi) InvokeWithResponseStream(ctx context.Context, params *lambda.InvokeWithResponseStreamInput, optFns ...func(*lambda.Options)) (*lambda.InvokeWithResponseStreamOutput, error) {
+	return &lambda.InvokeWithResponseStreamOutput{}, nil
+}
+
+func (m *mockFunctionApi) PutFunctionEventInvokeConfig(ctx context.Context, params *lambda.PutFunctionEventInvokeConfigInput, optFns ...func(*lambda.Options)) (*lambda.PutFunctionEventInvokeConfigOutput, error) {
+	m.eventInvokeConfig = params
+	return &lambda.PutFunctionEventInvokeConfigOutput{}, nil
+}
+
+func (m *mockFunctionApi) DeleteFunctionEventInvokeConfig(ctx context.Context, params *lambda.DeleteFunctionEventInvokeConfigInput, optFns ...func(*lambda.Options)) (*lambda.DeleteFunctionEventInvokeConfigOutput, error) {
+	if m.eventInvokeConfig == nil {
+		return nil, &types.ResourceNotFoundException{}
+	}
+	m.eventInvokeConfig = nil
+	return &lambda.DeleteFunctionEventInvokeConfigOutput{}, nil
+}
+
+func (m *mockFunctionApi) GetPolicy(ctx context.Context, params *lambda.GetPolicyInput, optFns ...func(*lambda.Options)) (*lambda.GetPolicyOutput, error) {
+	qualifier := aws.ToString(params.Qualifier)
+	if len(m.policyStatements[qualifier]) == 0 {
+		return nil, &types.ResourceNotFoundException{}
+	}
+	var statements []string
+	for _, sid := range m.policyStatements[qualifier] {
+		statement := `{"Sid":"` + sid + `","Effect":"Allow"`
+		for _, added := range m.addedPermissions {
+			if *added.StatementId == sid && added.SourceArn != nil {
+				statement += `,"Principal":{"Service":"` + *added.Principal + `"},"Condition":{"ArnLike":{"AWS:SourceArn":"` + *added.SourceArn + `"}}`
+			}
+		}
+		statements = append(statements, statement+"}")
+	}
+	return &lambda.GetPolicyOutput{
+		Policy: aws.String(`{"Version":"2012-10-17","Statement":[` + strings.Join(statements, ",") + `]}`),
+	}, nil
+}
+
+func (m *mockFunctionApi) ListAliases(ctx context.Context, params *lambda.ListAliasesInput, optFns ...func(*lambda.Options)) (*lambda.ListAliasesOutput, error) {
+	output := &lambda.ListAliasesOutput{}
+	for _, alias := range m.aliases {
+		output.Aliases = append(output.Aliases, types.AliasConfiguration{Name: aws.String(alias)})
+	}
+	return output, nil
+}
+
+func (m *mockFunctionApi) ListVersionsByFunction(ctx context.Context, params *lambda.ListVersionsByFunctionInput, optFns ...func(*lambda.Options)) (*lambda.ListVersionsByFunctionOutput, error) {
+	output := &lambda.ListVersionsByFunctionOutput{
+		Versions: []types.FunctionConfiguration{{Version: aws.String("$LATEST")}},
+	}
+	for _, version := range m.versions {
+		output.Versions = append(output.Versions, types.FunctionConfiguration{Version: aws.String(version)})
+	}
+	return output, nil
+}
+
+func (m *mockFunctionApi) AddPermission(ctx context.Context, params *lambda.AddPermissionInput, optFns ...func(*lambda.Options)) (*lambda.AddPermissionOutput, error) {
+	if m.policyStatements == nil {
+		m.policyStatements = map[string][]string{}
+	}
+	qualifier := aws.ToString(params.Qualifier)
+	m.policyStatements[qualifier] = append(m.policyStatements[qualifier], *params.StatementId)
+	m.addedPermissions = append(m.addedPermissions, params)
+	return &lambda.AddPermissionOutput{}, nil
+}
+
+func (m *mockFunctionApi) RemovePermission(ctx context.Context, params *lambda.RemovePermissionInput, optFns ...func(*lambda.Options)) (*lambda.RemovePermissionOutput, error) {
+	qualifier := aws.ToString(params.Qualifier)
+	var remaining []string
+	for _, sid := range m.policyStatements[qualifier] {
+		if sid != *params.StatementId {
+			remaining = append(remaining, sid)
+		}
+	}
+	m.policyStatements[qualifier] = remaining
+	return &lambda.RemovePermissionOutput{}, nil
+}
+
+func (m *mockFunctionApi) ListFunctionUrlConfigs(ctx context.Context, params *lambda.ListFunctionUrlConfigsInput, optFns ...func(*lambda.Options)) (*lambda.ListFunctionUrlConfigsOutput, error) {
+	output := &lambda.ListFunctionUrlConfigsOutput{}
+	for _, config := range m.functionUrls {
+		output.FunctionUrlConfigs = append(output.FunctionUrlConfigs, *config)
+	}
+	return output, nil
+}
+
+func (m *mockFunctionApi) CreateFunctionUrlConfig(ctx context.Context, params *lambda.CreateFunctionUrlConfigInput, optFns ...func(*lambda.Options)) (*lambda.CreateFunctionUrlConfigOutput, error) {
+	if m.functionUrls == nil {
+		m.functionUrls = map[string]*types.FunctionUrlConfig{}
+	}
+	functionArn := "arn:aws:lambda:us-east-1:123456789012:function:" + *params.FunctionName
+	if params.Qualifier != nil {
+		functionArn += ":" + *params.Qualifier
+	}
+	config := &types.FunctionUrlConfig{
+		FunctionArn: aws.String(functionArn),
+		FunctionUrl: aws.String("https://abc.lambda-url.us-east-1.on.aws/"),
+		AuthType:    params.AuthType,
+		Cors:        params.Cors,
+	}
+	m.functionUrls[aws.ToString(params.Qualifier)] = config
+	return &lambda.CreateFunctionUrlConfigOutput{FunctionUrl: config.FunctionUrl, AuthType: params.AuthType}, nil
+}
+
+func (m *mockFunctionApi) UpdateFunctionUrlConfig(ctx context.Context, params *lambda.UpdateFunctionUrlConfigInput, optFns ...func(*lambda.Options)) (*lambda.UpdateFunctionUrlConfigOutput, error) {
+	config := m.functionUrls[aws.ToString(params.Qualifier)]
+	config.AuthType = params.AuthType
+	config.Cors = params.Cors
+	return &lambda.UpdateFunctionUrlConfigOutput{FunctionUrl: config.FunctionUrl, AuthType: params.AuthType}, nil
+}
+
+func (m *mockFunctionApi) DeleteFunctionUrlConfig(ctx context.Context, params *lambda.DeleteFunctionUrlConfigInput, optFns ...func(*lambda.Options)) (*lambda.DeleteFunctionUrlConfigOutput, error) {
+	delete(m.functionUrls, aws.ToString(params.Qualifier))
+	return &lambda.DeleteFunctionUrlConfigOutput{}, nil
+}
+
 func TestGetFunctionDetails(t *testing.T) {
 	service := ServiceWrapper{Client: &mockFunctionApi{}}
 	ctx := context.TODO()
@@ -85,3 +335,41 @@ func TestValidateInputParamsInvalidValidInput(t *testing.T) {
 
 	assert.Error(t, ValidateInputParams(lambdaParams, true))
 }
+
+func TestValidateInputParamsImage(t *testing.T) {
+	lambdaParams := common.DeployParams{
+		FunctionName: "test-function",
+		ImageUri:     "123456789012.dkr.ecr.us-east-1.amazonaws.com/test:latest",
+		PackageType:  "Image",
+	}
+	assert.NoError(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.ZipFile = "test.zip"
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.ZipFile = ""
+	lambdaParams.ImageUri = ""
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+}
+
+func TestValidateInputParamsImageConfigWithZip(t *testing.T) {
+	lambdaParams := common.DeployParams{
+		FunctionName: "test-function",
+		ZipFile:      "test.zip",
+		HandlerName:  "handler",
+		Runtime:      "go",
+		ImageConfig:  &common.ImageConfig{Command: []string{"app.handler"}},
+	}
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+}
+
+func TestVpcConfig(t *testing.T) {
+	attached := vpcConfig(&common.VpcConfig{SubnetIds: []string{"subnet-1"}, SecurityGroupIds: []string{"sg-1"}})
+	assert.Equal(t, []string{"subnet-1"}, attached.SubnetIds)
+	assert.False(t, *attached.Ipv6AllowedForDualStack)
+
+	detached := vpcConfig(&common.VpcConfig{})
+	assert.NotNil(t, detached.SubnetIds)
+	assert.Empty(t, detached.SubnetIds)
+	assert.Empty(t, detached.SecurityGroupIds)
+}
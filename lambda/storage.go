@@ -0,0 +1,63 @@
+package lambda
+
+import (
+	"fmt"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+const (
+	minEphemeralStorage = 512
+	maxEphemeralStorage = 10240
+)
+
+var mountPathPattern = regexp.MustCompile(`^/mnt/[a-zA-Z0-9\-_.]+$`)
+
+func validateStorage(lambdaParams common.DeployParams, createFlag bool, errorMessage *strings.Builder) {
+	storage := lambdaParams.EphemeralStorage
+	if storage != 0 && (storage < minEphemeralStorage || storage > maxEphemeralStorage) {
+		errorMessage.WriteString(fmt.Sprintf("Ephemeral storage must be between %d and %d MB.\n", minEphemeralStorage, maxEphemeralStorage))
+	}
+	if len(lambdaParams.FileSystemConfigs) > 1 {
+		errorMessage.WriteString("Only one file system can be mounted.\n")
+	}
+	for _, fileSystem := range lambdaParams.FileSystemConfigs {
+		if common.ArnService(fileSystem.Arn) != "elasticfilesystem" || !strings.Contains(fileSystem.Arn, ":access-point/") {
+			errorMessage.WriteString(fmt.Sprintf("File system %q must be the ARN of an EFS access point.\n", fileSystem.Arn))
+		}
+		if !mountPathPattern.MatchString(fileSystem.LocalMountPath) {
+			errorMessage.WriteString(fmt.Sprintf("Local mount path %q must be a directory directly below /mnt.\n", fileSystem.LocalMountPath))
+		}
+	}
+	// On update a function already in a VPC stays there when vpc is left out
+	vpcKnown := createFlag || lambdaParams.Vpc != nil
+	if len(lambdaParams.FileSystemConfigs) > 0 && vpcKnown && !lambdaParams.Vpc.Attached() {
+		errorMessage.WriteString("File systems can only be mounted by functions in a VPC.\n")
+	}
+}
+
+func ephemeralStorage(size int) *types.EphemeralStorage {
+	if size == 0 {
+		return nil
+	}
+	return &types.EphemeralStorage{Size: aws.Int32(int32(size))}
+}
+
+func fileSystemConfigs(configs []common.FileSystemConfig) []types.FileSystemConfig {
+	if configs == nil {
+		return nil
+	}
+	// An empty list unmounts the file systems
+	result := []types.FileSystemConfig{}
+	for _, config := range configs {
+		result = append(result, types.FileSystemConfig{
+			Arn:            aws.String(config.Arn),
+			LocalMountPath: aws.String(config.LocalMountPath),
+		})
+	}
+	return result
+}
@@ -0,0 +1,61 @@
+package lambda
+
+import (
+	"context"
+	"testing"
+
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const accessPointArn = "arn:aws:elasticfilesystem:us-east-1:123456789012:access-point/fsap-0123456789abcdef0"
+
+func TestValidateStorage(t *testing.T) {
+	lambdaParams := validZipParams()
+	lambdaParams.EphemeralStorage = 2048
+	lambdaParams.Vpc = &common.VpcConfig{SubnetIds: []string{"subnet-1"}, SecurityGroupIds: []string{"sg-1"}}
+	lambdaParams.FileSystemConfigs = []common.FileSystemConfig{{Arn: accessPointArn, LocalMountPath: "/mnt/data"}}
+	assert.NoError(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.EphemeralStorage = 256
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.EphemeralStorage = 0
+	lambdaParams.FileSystemConfigs[0].LocalMountPath = "/data"
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.FileSystemConfigs[0] = common.FileSystemConfig{Arn: "arn:aws:elasticfilesystem:us-east-1:123456789012:file-system/fs-01234567", LocalMountPath: "/mnt/data"}
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+
+	// Mounting needs a VPC on create, on update the function may already be in one
+	lambdaParams.FileSystemConfigs[0].Arn = accessPointArn
+	lambdaParams.Vpc = nil
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+	assert.NoError(t, ValidateInputParams(lambdaParams, false))
+	lambdaParams.Vpc = &common.VpcConfig{}
+	assert.Error(t, ValidateInputParams(lambdaParams, false))
+}
+
+func TestUpdateFunctionConfigurationStorage(t *testing.T) {
+	mock := &mockFunctionApi{}
+	wrapper := ServiceWrapper{Client: mock}
+	lambdaParams := common.DeployParams{FunctionName: "test"}
+	lambdaParams.EphemeralStorage = 1024
+	lambdaParams.SnapStart = "PublishedVersions"
+	lambdaParams.FileSystemConfigs = []common.FileSystemConfig{{Arn: accessPointArn, LocalMountPath: "/mnt/data"}}
+	assert.NoError(t, wrapper.UpdateFunctionConfiguration(context.TODO(), lambdaParams))
+	assert.Equal(t, int32(1024), *mock.configInput.EphemeralStorage.Size)
+	assert.Equal(t, types.SnapStartApplyOnPublishedVersions, mock.configInput.SnapStart.ApplyOn)
+	assert.Len(t, mock.configInput.FileSystemConfigs, 1)
+
+	// Settings left out go back to the defaults and the file system is unmounted
+	lambdaParams.EphemeralStorage = 0
+	lambdaParams.SnapStart = ""
+	lambdaParams.FileSystemConfigs = nil
+	assert.NoError(t, wrapper.UpdateFunctionConfiguration(context.TODO(), lambdaParams))
+	assert.Equal(t, int32(512), *mock.configInput.EphemeralStorage.Size)
+	assert.Equal(t, types.SnapStartApplyOnNone, mock.configInput.SnapStart.ApplyOn)
+	assert.NotNil(t, mock.configInput.FileSystemConfigs)
+	assert.Empty(t, mock.configInput.FileSystemConfigs)
+}
@@ -0,0 +1,64 @@
+package lambda
+
+import (
+	"context"
+	"fmt"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+)
+
+const (
+	maxTags           = 50
+	maxTagKeyLength   = 128
+	maxTagValueLength = 256
+)
+
+func validateTags(tags map[string]string, errorMessage *strings.Builder) {
+	// The tool tags count towards the limit as well
+	if len(tags)+len(common.ToolTagKeys) > maxTags {
+		errorMessage.WriteString(fmt.Sprintf("At most %d tags can be declared.\n", maxTags-len(common.ToolTagKeys)))
+	}
+	for key, value := range tags {
+		if slices.Contains(common.ToolTagKeys, key) {
+			errorMessage.WriteString(fmt.Sprintf("Tag %s is set by the tool and cannot be declared.\n", key))
+		}
+		if strings.HasPrefix(strings.ToLower(key), "aws:") {
+			errorMessage.WriteString(fmt.Sprintf("Tag %s uses the reserved aws: prefix.\n", key))
+		}
+		if key == "" || len(key) > maxTagKeyLength {
+			errorMessage.WriteString(fmt.Sprintf("Tag key %q must be 1 to %d characters long.\n", key, maxTagKeyLength))
+		}
+		if len(value) > maxTagValueLength {
+			errorMessage.WriteString(fmt.Sprintf("Value of tag %s is longer than %d characters.\n", key, maxTagValueLength))
+		}
+	}
+}
+
+// ReconcileTags brings the tags of the function in line with the config
+func (wrapper ServiceWrapper) ReconcileTags(ctx context.Context, functionArn string, lambdaParams common.DeployParams) error {
+	output, err := wrapper.Client.ListTags(ctx, &lambda.ListTagsInput{Resource: &functionArn})
+	if err != nil {
+		log.Printf("Not able to list the tags of the function. The reason is %s", err.Error())
+		return err
+	}
+	added, removed := lambdaParams.TagChanges(output.Tags)
+	if len(removed) > 0 {
+		log.Println("Removing tags", removed)
+		_, err = wrapper.Client.UntagResource(ctx, &lambda.UntagResourceInput{Resource: &functionArn, TagKeys: removed})
+		if err != nil {
+			log.Printf("Not able to remove the tags of the function. The reason is %s", err.Error())
+			return err
+		}
+	}
+	if len(added) > 0 {
+		_, err = wrapper.Client.TagResource(ctx, &lambda.TagResourceInput{Resource: &functionArn, Tags: added})
+		if err != nil {
+			log.Printf("Not able to tag the function. The reason is %s", err.Error())
+		}
+	}
+	return err
+}
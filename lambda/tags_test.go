@@ -0,0 +1,56 @@
+package lambda
+
+import (
+	"context"
+	"testing"
+
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTags(t *testing.T) {
+	lambdaParams := validZipParams()
+	lambdaParams.Tags = map[string]string{"team": "payments", "cost-center": "42"}
+	assert.NoError(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.Tags = map[string]string{common.TagManagedBy: "someone"}
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.Tags = map[string]string{"aws:cloudformation:stack-name": "orders"}
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+}
+
+func TestReconcileTags(t *testing.T) {
+	mock := &mockFunctionApi{tags: map[string]string{
+		"team":                          "orders",
+		"legacy":                        "true",
+		"aws:cloudformation:stack-name": "orders",
+		common.TagDeployedAt:            "2026-01-01T00:00:00Z",
+	}}
+	wrapper := ServiceWrapper{Client: mock}
+	lambdaParams := validZipParams()
+	lambdaParams.ToolTags = map[string]string{common.TagManagedBy: common.ManagedByValue, common.TagDeployedAt: "2026-10-19T10:00:00Z"}
+
+	// Without declared tags only the tool tags are put
+	assert.NoError(t, wrapper.ReconcileTags(context.Background(), "arn", lambdaParams))
+	assert.Empty(t, mock.untaggedKeys)
+	assert.Equal(t, "2026-10-19T10:00:00Z", mock.tags[common.TagDeployedAt])
+	assert.Equal(t, "true", mock.tags["legacy"])
+
+	lambdaParams.Tags = map[string]string{"team": "payments", "cost-center": "42"}
+	assert.NoError(t, wrapper.ReconcileTags(context.Background(), "arn", lambdaParams))
+	assert.Equal(t, []string{"legacy"}, mock.untaggedKeys)
+	assert.Equal(t, map[string]string{
+		"team":                          "payments",
+		"cost-center":                   "42",
+		"aws:cloudformation:stack-name": "orders",
+		common.TagManagedBy:             common.ManagedByValue,
+		common.TagDeployedAt:            "2026-10-19T10:00:00Z",
+	}, mock.tags)
+
+	// Once the function carries the tool tags, leaving out the tags block removes the declared tags
+	mock.untaggedKeys = nil
+	lambdaParams.Tags = nil
+	assert.NoError(t, wrapper.ReconcileTags(context.Background(), "arn", lambdaParams))
+	assert.Equal(t, []string{"cost-center", "team"}, mock.untaggedKeys)
+}
@@ -0,0 +1,181 @@
+package lambda
+
+import (
+	"context"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+func validateFunctionUrl(config *common.FunctionUrlConfig, errorMessage *strings.Builder) {
+	if config == nil || config.Deleted() {
+		return
+	}
+	if config.AuthType != string(types.FunctionUrlAuthTypeAwsIam) && config.AuthType != string(types.FunctionUrlAuthTypeNone) {
+		errorMessage.WriteString("Function URL Auth Type must be either AWS_IAM or NONE.\n")
+	}
+	if config.AuthType == string(types.FunctionUrlAuthTypeNone) && !config.AllowPublic {
+		errorMessage.WriteString("Function URL with Auth Type NONE is public and needs allow_public: true.\n")
+	}
+	if config.InvokeMode != "" && config.InvokeMode != string(types.InvokeModeBuffered) && config.InvokeMode != string(types.InvokeModeResponseStream) {
+		errorMessage.WriteString("Function URL Invoke Mode must be either BUFFERED or RESPONSE_STREAM.\n")
+	}
+}
+
+// FunctionUrl is a function URL in place after PutFunctionUrl
+type FunctionUrl struct {
+	Url       string
+	AuthType  types.FunctionUrlAuthType
+	Qualifier string
+}
+
+// FunctionUrlPermissions returns the public invoke permissions the function URLs without authentication need
+func FunctionUrlPermissions(functionUrls []FunctionUrl) []common.Permission {
+	var permissions []common.Permission
+	for _, functionUrl := range functionUrls {
+		if functionUrl.AuthType != types.FunctionUrlAuthTypeNone {
+			continue
+		}
+		permissions = append(permissions, common.Permission{
+			Principal:           "*",
+			Qualifier:           functionUrl.Qualifier,
+			FunctionUrlAuthType: string(functionUrl.AuthType),
+		})
+	}
+	return permissions
+}
+
+func cors(config *common.CorsConfig) *types.Cors {
+	if config == nil {
+		return nil
+	}
+	result := &types.Cors{
+		AllowCredentials: &config.AllowCredentials,
+		AllowHeaders:     config.AllowHeaders,
+		AllowMethods:     config.AllowMethods,
+		AllowOrigins:     config.AllowOrigins,
+		ExposeHeaders:    config.ExposeHeaders,
+	}
+	if config.MaxAge != nil {
+		maxAge := int32(*config.MaxAge)
+		result.MaxAge = &maxAge
+	}
+	return result
+}
+
+// functionUrls returns the URLs of the function on every qualifier
+func (wrapper ServiceWrapper) functionUrls(ctx context.Context, functionName string) ([]FunctionUrl, error) {
+	var functionUrls []FunctionUrl
+	listInput := &lambda.ListFunctionUrlConfigsInput{FunctionName: &functionName}
+	for {
+		output, err := wrapper.Client.ListFunctionUrlConfigs(ctx, listInput)
+		if err != nil {
+			return nil, err
+		}
+		for _, config := range output.FunctionUrlConfigs {
+			functionUrls = append(functionUrls, FunctionUrl{
+				Url:       *config.FunctionUrl,
+				AuthType:  config.AuthType,
+				Qualifier: arnQualifier(*config.FunctionArn),
+			})
+		}
+		if output.NextMarker == nil {
+			break
+		}
+		listInput.Marker = output.NextMarker
+	}
+	return functionUrls, nil
+}
+
+// arnQualifier returns the alias or version of a qualified function ARN
+func arnQualifier(functionArn string) string {
+	parts := strings.Split(functionArn, ":")
+	if len(parts) < 8 {
+		return ""
+	}
+	return parts[7]
+}
+
+func qualifierInput(qualifier string) *string {
+	if qualifier == "" {
+		return nil
+	}
+	return &qualifier
+}
+
+// PutFunctionUrl creates, updates or deletes the function URL and returns the URLs in place.
+// Without a function_url block the URLs of the function are left as they are. URLs on other
+// qualifiers than the declared one are deleted, so changing the qualifier moves the URL.
+func (wrapper ServiceWrapper) PutFunctionUrl(ctx context.Context, lambdaParams common.DeployParams) ([]FunctionUrl, error) {
+	existing, err := wrapper.functionUrls(ctx, lambdaParams.FunctionName)
+	if err != nil {
+		log.Printf("Not able to read the function URLs. The reason is %s", err.Error())
+		return nil, err
+	}
+	config := lambdaParams.FunctionUrl
+	if config == nil {
+		return existing, nil
+	}
+	common.TrimAndCheckEmptyString(&config.Qualifier)
+	found := false
+	for _, functionUrl := range existing {
+		if !config.Deleted() && functionUrl.Qualifier == config.Qualifier {
+			found = true
+			continue
+		}
+		log.Println("Deleting function URL", functionUrl.Url)
+		_, err = wrapper.Client.DeleteFunctionUrlConfig(ctx, &lambda.DeleteFunctionUrlConfigInput{
+			FunctionName: &lambdaParams.FunctionName,
+			Qualifier:    qualifierInput(functionUrl.Qualifier),
+		})
+		if err != nil {
+			log.Printf("Not able to delete the function URL. The reason is %s", err.Error())
+			return nil, err
+		}
+	}
+	if config.Deleted() {
+		return nil, nil
+	}
+	functionUrl := FunctionUrl{
+		AuthType:  types.FunctionUrlAuthType(config.AuthType),
+		Qualifier: config.Qualifier,
+	}
+	if !found {
+		log.Println("Creating function URL-----")
+		output, err := wrapper.Client.CreateFunctionUrlConfig(ctx, &lambda.CreateFunctionUrlConfigInput{
+			FunctionName: &lambdaParams.FunctionName,
+			Qualifier:    qualifierInput(config.Qualifier),
+			AuthType:     functionUrl.AuthType,
+			Cors:         cors(config.Cors),
+			InvokeMode:   types.InvokeMode(config.InvokeMode),
+		})
+		if err != nil {
+			log.Printf("Not able to create the function URL. The reason is %s", err.Error())
+			return nil, err
+		}
+		functionUrl.Url = *output.FunctionUrl
+		return []FunctionUrl{functionUrl}, nil
+	}
+	log.Println("Updating function URL-----")
+	urlInput := &lambda.UpdateFunctionUrlConfigInput{
+		FunctionName: &lambdaParams.FunctionName,
+		Qualifier:    qualifierInput(config.Qualifier),
+		AuthType:     functionUrl.AuthType,
+		InvokeMode:   types.InvokeMode(config.InvokeMode),
+		Cors:         cors(config.Cors),
+	}
+	if urlInput.Cors == nil {
+		// Removes CORS settings configured earlier
+		urlInput.Cors = &types.Cors{}
+	}
+	output, err := wrapper.Client.UpdateFunctionUrlConfig(ctx, urlInput)
+	if err != nil {
+		log.Printf("Not able to update the function URL. The reason is %s", err.Error())
+		return nil, err
+	}
+	functionUrl.Url = *output.FunctionUrl
+	return []FunctionUrl{functionUrl}, nil
+}
@@ -0,0 +1,89 @@
+package lambda
+
+import (
+	"context"
+	"testing"
+
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateFunctionUrl(t *testing.T) {
+	lambdaParams := validZipParams()
+	lambdaParams.FunctionUrl = &common.FunctionUrlConfig{AuthType: "NONE"}
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.FunctionUrl.AllowPublic = true
+	assert.NoError(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.FunctionUrl = &common.FunctionUrlConfig{AuthType: "AWS_IAM", InvokeMode: "STREAMING"}
+	assert.Error(t, ValidateInputParams(lambdaParams, true))
+
+	lambdaParams.FunctionUrl = &common.FunctionUrlConfig{}
+	assert.NoError(t, ValidateInputParams(lambdaParams, true))
+}
+
+func TestPutFunctionUrl(t *testing.T) {
+	mock := &mockFunctionApi{}
+	wrapper := ServiceWrapper{Client: mock}
+	lambdaParams := validZipParams()
+
+	functionUrls, err := wrapper.PutFunctionUrl(context.TODO(), lambdaParams)
+	assert.NoError(t, err)
+	assert.Empty(t, functionUrls)
+
+	maxAge := 300
+	lambdaParams.FunctionUrl = &common.FunctionUrlConfig{
+		AuthType:    "NONE",
+		AllowPublic: true,
+		Cors:        &common.CorsConfig{AllowOrigins: []string{"https://example.com"}, MaxAge: &maxAge},
+	}
+	functionUrls, err = wrapper.PutFunctionUrl(context.TODO(), lambdaParams)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://abc.lambda-url.us-east-1.on.aws/", functionUrls[0].Url)
+	assert.Equal(t, int32(300), *mock.functionUrls[""].Cors.MaxAge)
+	assert.Len(t, FunctionUrlPermissions(functionUrls), 1)
+
+	lambdaParams.FunctionUrl = &common.FunctionUrlConfig{AuthType: "AWS_IAM"}
+	functionUrls, err = wrapper.PutFunctionUrl(context.TODO(), lambdaParams)
+	assert.NoError(t, err)
+	assert.Equal(t, types.FunctionUrlAuthTypeAwsIam, mock.functionUrls[""].AuthType)
+	assert.Empty(t, mock.functionUrls[""].Cors.AllowOrigins)
+	assert.Empty(t, FunctionUrlPermissions(functionUrls))
+
+	// Without a function_url block the URL is kept
+	lambdaParams.FunctionUrl = nil
+	functionUrls, err = wrapper.PutFunctionUrl(context.TODO(), lambdaParams)
+	assert.NoError(t, err)
+	assert.Len(t, functionUrls, 1)
+
+	lambdaParams.FunctionUrl = &common.FunctionUrlConfig{}
+	functionUrls, err = wrapper.PutFunctionUrl(context.TODO(), lambdaParams)
+	assert.NoError(t, err)
+	assert.Empty(t, functionUrls)
+	assert.Empty(t, mock.functionUrls)
+}
+
+func TestPutFunctionUrlQualifier(t *testing.T) {
+	mock := &mockFunctionApi{}
+	wrapper := ServiceWrapper{Client: mock}
+	lambdaParams := validZipParams()
+	lambdaParams.FunctionUrl = &common.FunctionUrlConfig{AuthType: "NONE", AllowPublic: true, Qualifier: "live"}
+	_, err := wrapper.PutFunctionUrl(context.TODO(), lambdaParams)
+	assert.NoError(t, err)
+
+	// Without a function_url block the public URL of the alias keeps its permission
+	lambdaParams.FunctionUrl = nil
+	functionUrls, err := wrapper.PutFunctionUrl(context.TODO(), lambdaParams)
+	assert.NoError(t, err)
+	assert.Equal(t, []common.Permission{{Principal: "*", Qualifier: "live", FunctionUrlAuthType: "NONE"}}, FunctionUrlPermissions(functionUrls))
+
+	// Changing the qualifier moves the URL
+	lambdaParams.FunctionUrl = &common.FunctionUrlConfig{AuthType: "NONE", AllowPublic: true, Qualifier: "canary"}
+	functionUrls, err = wrapper.PutFunctionUrl(context.TODO(), lambdaParams)
+	assert.NoError(t, err)
+	assert.Equal(t, "canary", functionUrls[0].Qualifier)
+	assert.NotContains(t, mock.functionUrls, "live")
+	assert.Contains(t, mock.functionUrls, "canary")
+}
@@ -0,0 +1,306 @@
+package local
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/a-pavithraa/lambda-deploy/lambda"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Runtimes which run a bootstrap executable against the Runtime API. go1.x binaries do as well,
+// as aws-lambda-go falls back to the Runtime API when _LAMBDA_SERVER_PORT is not set.
+var runtimes = []string{"provided", "provided.al2", "provided.al2023", "go1.x"}
+
+// Host variables passed on to the function, so that it can reach AWS with the local credentials
+var passedVariables = []string{"PATH", "HOME", "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_PROFILE", "AWS_CONFIG_FILE", "AWS_SHARED_CREDENTIALS_FILE"}
+
+func ValidateLocalParams(lambdaParams common.DeployParams) error {
+	var errorMessage strings.Builder
+	if common.TrimAndCheckEmptyString(&lambdaParams.FunctionName) {
+		errorMessage.WriteString("Function Name cannot be null.\n")
+	}
+	if common.TrimAndCheckEmptyString(&lambdaParams.ZipFile) {
+		errorMessage.WriteString("Zip file has to be included to run the function locally.\n")
+	}
+	if !slices.Contains(runtimes, lambdaParams.Runtime) {
+		errorMessage.WriteString(fmt.Sprintf("Runtime %q cannot run locally. Possible values %s.\n", lambdaParams.Runtime, strings.Join(runtimes, ", ")))
+	}
+	if lambdaParams.Runtime == "go1.x" && common.TrimAndCheckEmptyString(&lambdaParams.HandlerName) {
+		errorMessage.WriteString("Handler Name is the executable of a go1.x function and cannot be null.\n")
+	}
+	if len(errorMessage.String()) > 0 {
+		return &common.InputError{
+			Message: errorMessage.String(),
+		}
+	}
+	return nil
+}
+
+// Container runs the bootstrap of a function extracted from its zip against an in-process Runtime API.
+// It stays warm between invocations until it is stopped, times out or exits.
+type Container struct {
+	lambdaParams common.DeployParams
+	api          *RuntimeApi
+	server       *http.Server
+	directory    string
+	command      *exec.Cmd
+	exited       chan error
+	started      time.Time
+	cold         bool
+	stopped      bool
+}
+
+// Start extracts the zip of the function and starts its bootstrap. The output of the function goes to logs.
+func Start(lambdaParams common.DeployParams, logs io.Writer) (*Container, error) {
+	if err := ValidateLocalParams(lambdaParams); err != nil {
+		return nil, err
+	}
+	directory, err := os.MkdirTemp("", "lambda-deploy-"+lambdaParams.FunctionName+"-")
+	if err != nil {
+		return nil, err
+	}
+	executable := "bootstrap"
+	if lambdaParams.Runtime == "go1.x" {
+		executable = lambdaParams.HandlerName
+	}
+	if err = extractZip(lambdaParams.ZipFile, directory, executable); err != nil {
+		os.RemoveAll(directory)
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		os.RemoveAll(directory)
+		return nil, err
+	}
+	region := lambdaParams.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	container := &Container{
+		lambdaParams: lambdaParams,
+		api:          NewRuntimeApi(fmt.Sprintf("arn:aws:lambda:%s:000000000000:function:%s", region, lambdaParams.FunctionName)),
+		directory:    directory,
+		exited:       make(chan error, 1),
+		cold:         true,
+	}
+	container.server = &http.Server{Handler: container.api}
+	go container.server.Serve(listener)
+
+	container.command = exec.Command(filepath.Join(directory, executable))
+	container.command.Dir = directory
+	container.command.Stdout = logs
+	container.command.Stderr = logs
+	container.command.Env = environment(lambdaParams, region, listener.Addr().String(), directory)
+	container.started = time.Now()
+	if err = container.command.Start(); err != nil {
+		container.Stop()
+		return nil, err
+	}
+	go func() { container.exited <- container.command.Wait() }()
+	return container, nil
+}
+
+func environment(lambdaParams common.DeployParams, region string, runtimeApi string, directory string) []string {
+	var variables []string
+	for _, name := range passedVariables {
+		if value, found := os.LookupEnv(name); found {
+			variables = append(variables, name+"="+value)
+		}
+	}
+	for name, value := range lambdaParams.EnvironmentVariables {
+		variables = append(variables, name+"="+value)
+	}
+	// The variables Lambda sets come last, so they cannot be overridden
+	return append(variables,
+		"AWS_LAMBDA_RUNTIME_API="+runtimeApi,
+		"AWS_LAMBDA_FUNCTION_NAME="+lambdaParams.FunctionName,
+		"AWS_LAMBDA_FUNCTION_VERSION=$LATEST",
+		"AWS_LAMBDA_FUNCTION_MEMORY_SIZE="+strconv.Itoa(lambdaParams.Memory),
+		"AWS_LAMBDA_LOG_GROUP_NAME="+lambdaParams.LogGroupName(),
+		"AWS_LAMBDA_LOG_STREAM_NAME=local",
+		"AWS_REGION="+region,
+		"AWS_DEFAULT_REGION="+region,
+		"AWS_EXECUTION_ENV=AWS_Lambda_"+lambdaParams.Runtime,
+		"LAMBDA_TASK_ROOT="+directory,
+		"_HANDLER="+lambdaParams.HandlerName,
+		"TZ=:UTC",
+	)
+}
+
+// extractZip extracts the archive into the directory and makes sure the executable can be run
+func extractZip(zipFile string, directory string, executable string) error {
+	archive, err := zip.OpenReader(zipFile)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+	foundExecutable := false
+	for _, file := range archive.File {
+		target := filepath.Join(directory, file.Name)
+		if !strings.HasPrefix(target, filepath.Clean(directory)+string(os.PathSeparator)) {
+			return fmt.Errorf("zip entry %s points outside of the function directory", file.Name)
+		}
+		if file.FileInfo().IsDir() {
+			if err = os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		mode := file.Mode().Perm()
+		if file.Name == executable {
+			foundExecutable = true
+			// Archives built on Windows lose the executable bit
+			mode |= 0755
+		}
+		if err = extractFile(file, target, mode); err != nil {
+			return err
+		}
+	}
+	if !foundExecutable {
+		return &common.InputError{
+			Message: fmt.Sprintf("Zip file %s has no %s at its root", zipFile, executable),
+		}
+	}
+	return nil
+}
+
+func extractFile(file *zip.File, target string, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	writer, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode|0200)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(writer, reader); err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+// Alive reports whether the container can take further invocations
+func (container *Container) Alive() bool {
+	return !container.stopped
+}
+
+// Invoke hands the payload to the function and waits for its response. When the function does not
+// respond within its timeout or exits, the container is stopped and the result carries the error.
+func (container *Container) Invoke(ctx context.Context, payload []byte) (*lambda.InvokeResult, error) {
+	if container.stopped {
+		return nil, errors.New("container of function " + container.lambdaParams.FunctionName + " is stopped")
+	}
+	timeout := time.Duration(container.lambdaParams.Timeout) * time.Second
+	submitted := time.Now()
+	current := container.api.submit(payload, submitted.Add(timeout))
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	result := &lambda.InvokeResult{StatusCode: 200, ExecutedVersion: "$LATEST"}
+	var outcome response
+	select {
+	case outcome = <-current.done:
+		result.Payload = outcome.payload
+		result.FunctionError = outcome.functionError
+	case outcome = <-container.api.initError:
+		container.Stop()
+		result.Payload = outcome.payload
+		result.FunctionError = outcome.functionError
+		return result, nil
+	case err := <-container.exited:
+		container.exited <- err
+		container.Stop()
+		result.FunctionError = "Runtime.ExitError"
+		result.Payload = []byte(fmt.Sprintf(`{"errorType":"Runtime.ExitError","errorMessage":"Runtime exited: %v"}`, err))
+		return result, nil
+	case <-timer.C:
+		result.Report = &lambda.Report{MaxMemoryUsed: container.maxMemoryUsed()}
+		container.Stop()
+		outcome.finished = time.Now()
+		result.FunctionError = "Sandbox.Timedout"
+		result.Payload = []byte(fmt.Sprintf(`{"errorType":"Sandbox.Timedout","errorMessage":"Task timed out after %d.00 seconds"}`, container.lambdaParams.Timeout))
+	case <-ctx.Done():
+		container.Stop()
+		return nil, ctx.Err()
+	}
+
+	report := &lambda.Report{
+		RequestId:  current.requestId,
+		MemorySize: container.lambdaParams.Memory,
+	}
+	if result.Report != nil {
+		// Timed out, the memory was read before the bootstrap was killed
+		report.MaxMemoryUsed = result.Report.MaxMemoryUsed
+	} else {
+		report.MaxMemoryUsed = container.maxMemoryUsed()
+	}
+	handedOut := current.started
+	if handedOut.IsZero() {
+		handedOut = submitted
+	}
+	if container.cold {
+		container.cold = false
+		report.InitDuration = milliseconds(handedOut.Sub(container.started))
+	}
+	report.Duration = milliseconds(outcome.finished.Sub(handedOut))
+	report.BilledDuration = math.Ceil(report.Duration)
+	result.Report = report
+	return result, nil
+}
+
+// maxMemoryUsed reads the peak resident memory of the bootstrap in MB. It is 0 where /proc is not available.
+func (container *Container) maxMemoryUsed() int {
+	if container.command.Process == nil {
+		return 0
+	}
+	status, err := os.ReadFile(fmt.Sprintf("/proc/%d/status", container.command.Process.Pid))
+	if err != nil {
+		return 0
+	}
+	for _, line := range strings.Split(string(status), "\n") {
+		if value, found := strings.CutPrefix(line, "VmHWM:"); found {
+			kilobytes, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(value), " kB"))
+			return int(math.Ceil(float64(kilobytes) / 1024))
+		}
+	}
+	return 0
+}
+
+func milliseconds(duration time.Duration) float64 {
+	return float64(duration.Microseconds()) / 1000
+}
+
+// Stop kills the bootstrap, shuts down the Runtime API and removes the extracted code
+func (container *Container) Stop() error {
+	if container.stopped {
+		return nil
+	}
+	container.stopped = true
+	if container.command != nil && container.command.Process != nil {
+		container.command.Process.Kill()
+		<-container.exited
+	}
+	container.api.Close()
+	if container.server != nil {
+		container.server.Close()
+	}
+	return os.RemoveAll(container.directory)
+}
@@ -0,0 +1,139 @@
+package local
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMain turns the test binary into a fake bootstrap when the container starts it
+func TestMain(m *testing.M) {
+	if os.Getenv("FAKE_BOOTSTRAP") == "1" {
+		fakeBootstrap()
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// fakeBootstrap echoes the events with the STAGE variable. Events with sleep take that many
+// milliseconds, fail posts an error and exit ends the process. Requests to /created get a structured response.
+func fakeBootstrap() {
+	runtimeApi := "http://" + os.Getenv("AWS_LAMBDA_RUNTIME_API") + "/2018-06-01/runtime/invocation/"
+	for {
+		next, err := http.Get(runtimeApi + "next")
+		if err != nil {
+			os.Exit(1)
+		}
+		var event map[string]any
+		json.NewDecoder(next.Body).Decode(&event)
+		next.Body.Close()
+		requestId := next.Header.Get("Lambda-Runtime-Aws-Request-Id")
+		if sleep, ok := event["sleep"].(float64); ok {
+			time.Sleep(time.Duration(sleep) * time.Millisecond)
+		}
+		if event["exit"] == true {
+			os.Exit(3)
+		}
+		if event["fail"] == true {
+			http.Post(runtimeApi+requestId+"/error", "application/json", bytes.NewBufferString(`{"errorType":"OrderError","errorMessage":"no order"}`))
+			continue
+		}
+		if event["rawPath"] == "/created" {
+			http.Post(runtimeApi+requestId+"/response", "application/json", bytes.NewBufferString(
+				`{"statusCode":201,"headers":{"location":"/orders/42"},"cookies":["session=abc"],"body":"created"}`))
+			continue
+		}
+		event["stage"] = os.Getenv("STAGE")
+		event["function"] = os.Getenv("AWS_LAMBDA_FUNCTION_NAME")
+		response, _ := json.Marshal(event)
+		http.Post(runtimeApi+requestId+"/response", "application/json", bytes.NewBuffer(response))
+	}
+}
+
+func testParams(t *testing.T) common.DeployParams {
+	executable, err := os.Executable()
+	assert.NoError(t, err)
+	contents, err := os.ReadFile(executable)
+	assert.NoError(t, err)
+	zipFile := filepath.Join(t.TempDir(), "function.zip")
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	// Written without the executable bit, as archives built on Windows are
+	entry, _ := writer.Create("bootstrap")
+	entry.Write(contents)
+	writer.Close()
+	assert.NoError(t, os.WriteFile(zipFile, buffer.Bytes(), 0644))
+	return common.DeployParams{
+		FunctionName:         "orders",
+		Runtime:              "provided.al2023",
+		ZipFile:              zipFile,
+		Memory:               128,
+		Timeout:              1,
+		EnvironmentVariables: map[string]string{"FAKE_BOOTSTRAP": "1", "STAGE": "local"},
+	}
+}
+
+func TestValidateLocalParams(t *testing.T) {
+	err := ValidateLocalParams(common.DeployParams{Runtime: "python3.12"})
+	assert.ErrorContains(t, err, "Function Name cannot be null")
+	assert.ErrorContains(t, err, "Zip file has to be included")
+	assert.ErrorContains(t, err, `Runtime "python3.12" cannot run locally`)
+	assert.ErrorContains(t, ValidateLocalParams(common.DeployParams{FunctionName: "orders", ZipFile: "f.zip", Runtime: "go1.x"}), "Handler Name is the executable")
+}
+
+func TestContainer(t *testing.T) {
+	container, err := Start(testParams(t), io.Discard)
+	assert.NoError(t, err)
+	defer container.Stop()
+
+	result, err := container.Invoke(context.TODO(), []byte(`{"orderId":"42"}`))
+	assert.NoError(t, err)
+	assert.Empty(t, result.FunctionError)
+	assert.JSONEq(t, `{"orderId":"42","stage":"local","function":"orders"}`, string(result.Payload))
+	assert.Greater(t, result.Report.InitDuration, 0.0)
+	assert.Equal(t, 128, result.Report.MemorySize)
+
+	// Warm invocations have no init duration
+	result, err = container.Invoke(context.TODO(), []byte(`{"fail":true}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "OrderError", result.FunctionError)
+	assert.Zero(t, result.Report.InitDuration)
+	assert.True(t, container.Alive())
+
+	result, err = container.Invoke(context.TODO(), []byte(`{"sleep":3000}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "Sandbox.Timedout", result.FunctionError)
+	assert.False(t, container.Alive())
+	_, err = container.Invoke(context.TODO(), []byte(`{}`))
+	assert.ErrorContains(t, err, "is stopped")
+}
+
+func TestContainerExit(t *testing.T) {
+	container, err := Start(testParams(t), io.Discard)
+	assert.NoError(t, err)
+	defer container.Stop()
+	result, err := container.Invoke(context.TODO(), []byte(`{"exit":true}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "Runtime.ExitError", result.FunctionError)
+	assert.False(t, container.Alive())
+}
+
+func TestExtractZipWithoutBootstrap(t *testing.T) {
+	zipFile := filepath.Join(t.TempDir(), "function.zip")
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	writer.Create("main")
+	writer.Close()
+	assert.NoError(t, os.WriteFile(zipFile, buffer.Bytes(), 0644))
+	assert.ErrorContains(t, extractZip(zipFile, t.TempDir(), "bootstrap"), "has no bootstrap at its root")
+}
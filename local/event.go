@@ -0,0 +1,167 @@
+package local
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"github.com/a-pavithraa/lambda-deploy/lambda"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// httpEvent is the payload format 2.0 shared by Function URLs and API Gateway HTTP APIs
+type httpEvent struct {
+	Version               string            `json:"version"`
+	RouteKey              string            `json:"routeKey"`
+	RawPath               string            `json:"rawPath"`
+	RawQueryString        string            `json:"rawQueryString"`
+	Cookies               []string          `json:"cookies,omitempty"`
+	Headers               map[string]string `json:"headers"`
+	QueryStringParameters map[string]string `json:"queryStringParameters,omitempty"`
+	RequestContext        requestContext    `json:"requestContext"`
+	Body                  string            `json:"body,omitempty"`
+	IsBase64Encoded       bool              `json:"isBase64Encoded"`
+}
+
+type requestContext struct {
+	AccountId    string      `json:"accountId"`
+	ApiId        string      `json:"apiId"`
+	DomainName   string      `json:"domainName"`
+	DomainPrefix string      `json:"domainPrefix"`
+	Http         httpContext `json:"http"`
+	RequestId    string      `json:"requestId"`
+	RouteKey     string      `json:"routeKey"`
+	Stage        string      `json:"stage"`
+	Time         string      `json:"time"`
+	TimeEpoch    int64       `json:"timeEpoch"`
+}
+
+type httpContext struct {
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Protocol  string `json:"protocol"`
+	SourceIp  string `json:"sourceIp"`
+	UserAgent string `json:"userAgent"`
+}
+
+// httpResponse is the structured response a function returns to a Function URL or HTTP API
+type httpResponse struct {
+	StatusCode      int               `json:"statusCode"`
+	Headers         map[string]string `json:"headers"`
+	Cookies         []string          `json:"cookies"`
+	Body            string            `json:"body"`
+	IsBase64Encoded bool              `json:"isBase64Encoded"`
+}
+
+// requestEvent translates the request into the event the function receives. rawPath is the path
+// below the mount point of the function.
+func requestEvent(request *http.Request, rawPath string, format string) ([]byte, error) {
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	event := httpEvent{
+		Version:        "2.0",
+		RouteKey:       "$default",
+		RawPath:        rawPath,
+		RawQueryString: request.URL.RawQuery,
+		Headers:        map[string]string{},
+		RequestContext: requestContext{
+			AccountId:    "anonymous",
+			ApiId:        "local",
+			DomainName:   request.Host,
+			DomainPrefix: strings.Split(request.Host, ".")[0],
+			Http: httpContext{
+				Method:    request.Method,
+				Path:      rawPath,
+				Protocol:  request.Proto,
+				SourceIp:  sourceIp(request),
+				UserAgent: request.UserAgent(),
+			},
+			RequestId: requestId(),
+			RouteKey:  "$default",
+			Stage:     "$default",
+			Time:      now.Format("02/Jan/2006:15:04:05 -0700"),
+			TimeEpoch: now.UnixMilli(),
+		},
+	}
+	if format == FormatApiGatewayV2 {
+		event.RequestContext.AccountId = "123456789012"
+		event.RouteKey = request.Method + " " + rawPath
+		event.RequestContext.RouteKey = event.RouteKey
+	}
+	for name, values := range request.Header {
+		if strings.EqualFold(name, "Cookie") {
+			for _, cookie := range request.Cookies() {
+				event.Cookies = append(event.Cookies, cookie.String())
+			}
+			continue
+		}
+		event.Headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+	event.Headers["host"] = request.Host
+	if query := request.URL.Query(); len(query) > 0 {
+		event.QueryStringParameters = map[string]string{}
+		for name, values := range query {
+			event.QueryStringParameters[name] = strings.Join(values, ",")
+		}
+	}
+	if len(body) > 0 {
+		if utf8.Valid(body) {
+			event.Body = string(body)
+		} else {
+			event.Body = base64.StdEncoding.EncodeToString(body)
+			event.IsBase64Encoded = true
+		}
+	}
+	return json.Marshal(event)
+}
+
+func sourceIp(request *http.Request) string {
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		return request.RemoteAddr
+	}
+	return host
+}
+
+// writeResponse translates the result of the function into the HTTP response. A JSON object with a
+// statusCode is a structured response, anything else is returned as a JSON body with status 200.
+func writeResponse(writer http.ResponseWriter, result *lambda.InvokeResult) {
+	if result.FunctionError != "" {
+		status := http.StatusBadGateway
+		if result.FunctionError == "Sandbox.Timedout" {
+			status = http.StatusGatewayTimeout
+		}
+		http.Error(writer, `{"message":"Internal Server Error"}`, status)
+		return
+	}
+	var structured httpResponse
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(result.Payload, &fields) != nil || fields["statusCode"] == nil || json.Unmarshal(result.Payload, &structured) != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.Write(result.Payload)
+		return
+	}
+	for name, value := range structured.Headers {
+		writer.Header().Set(name, value)
+	}
+	for _, cookie := range structured.Cookies {
+		writer.Header().Add("Set-Cookie", cookie)
+	}
+	body := []byte(structured.Body)
+	if structured.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(structured.Body)
+		if err != nil {
+			http.Error(writer, `{"message":"Internal Server Error"}`, http.StatusBadGateway)
+			return
+		}
+		body = decoded
+	}
+	writer.WriteHeader(structured.StatusCode)
+	writer.Write(body)
+}
@@ -0,0 +1,129 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/a-pavithraa/lambda-deploy/secret"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	FormatFunctionUrl  = "function_url"
+	FormatApiGatewayV2 = "apigw-v2"
+)
+
+// Manifest lists the functions served by local serve. A config file without functions is served on its own at /.
+type Manifest struct {
+	Functions []ManifestFunction `yaml:"functions"`
+	// Concurrency is the maximum number of containers per function
+	Concurrency int `yaml:"concurrency"`
+}
+
+// ManifestFunction mounts the function of a config file at a path prefix. Format is function_url or apigw-v2.
+type ManifestFunction struct {
+	Config string `yaml:"config"`
+	Path   string `yaml:"path"`
+	Format string `yaml:"format"`
+	params common.DeployParams
+}
+
+// functionConfig holds the keys of a config file local serve needs, with the defaults of the upsert flags
+type functionConfig struct {
+	Name                 string                `yaml:"name"`
+	ZipFile              string                `yaml:"zip_file"`
+	Runtime              string                `yaml:"runtime"`
+	HandlerName          string                `yaml:"handler_name"`
+	Region               string                `yaml:"region"`
+	Memory               int                   `yaml:"memory"`
+	Timeout              int                   `yaml:"time_out"`
+	EnvironmentVariables common.EnvironmentMap `yaml:"environment_variables"`
+	EnvFile              string                `yaml:"env_file"`
+}
+
+// ReadManifest reads the manifest and the config files of its functions. References in the environment
+// variables are resolved with the resolvers, as local invoke does.
+func ReadManifest(ctx context.Context, fileName string, resolvers map[string]secret.Resolver) (*Manifest, error) {
+	contents, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{}
+	if err = yaml.Unmarshal(contents, manifest); err != nil {
+		return nil, err
+	}
+	if len(manifest.Functions) == 0 {
+		manifest.Functions = []ManifestFunction{{Config: filepath.Base(fileName)}}
+	}
+	if manifest.Concurrency <= 0 {
+		manifest.Concurrency = 10
+	}
+	var errorMessage strings.Builder
+	for index := range manifest.Functions {
+		function := &manifest.Functions[index]
+		if function.Path == "" {
+			function.Path = "/"
+		}
+		if !strings.HasPrefix(function.Path, "/") {
+			function.Path = "/" + function.Path
+		}
+		if function.Format == "" {
+			function.Format = FormatFunctionUrl
+		}
+		if function.Format != FormatFunctionUrl && function.Format != FormatApiGatewayV2 {
+			errorMessage.WriteString(fmt.Sprintf("Format of %s must be either %s or %s.\n", function.Path, FormatFunctionUrl, FormatApiGatewayV2))
+		}
+		// Config files and their zips are relative to the manifest
+		configFile := filepath.Join(filepath.Dir(fileName), function.Config)
+		function.params, err = readFunctionConfig(ctx, configFile, resolvers)
+		if err != nil {
+			return nil, err
+		}
+		if err = ValidateLocalParams(function.params); err != nil {
+			errorMessage.WriteString(fmt.Sprintf("%s: %s\n", configFile, err.Error()))
+		}
+	}
+	if len(errorMessage.String()) > 0 {
+		return nil, &common.InputError{
+			Message: errorMessage.String(),
+		}
+	}
+	return manifest, nil
+}
+
+func readFunctionConfig(ctx context.Context, fileName string, resolvers map[string]secret.Resolver) (common.DeployParams, error) {
+	config := functionConfig{Memory: 128, Timeout: 60}
+	contents, err := os.ReadFile(fileName)
+	if err != nil {
+		return common.DeployParams{}, err
+	}
+	if err = yaml.Unmarshal(contents, &config); err != nil {
+		return common.DeployParams{}, err
+	}
+	params := common.DeployParams{
+		FunctionName: config.Name,
+		Runtime:      config.Runtime,
+		HandlerName:  config.HandlerName,
+		Region:       config.Region,
+		Memory:       config.Memory,
+		Timeout:      config.Timeout,
+	}
+	if config.ZipFile != "" {
+		params.ZipFile = filepath.Join(filepath.Dir(fileName), config.ZipFile)
+	}
+	envFile := config.EnvFile
+	if envFile != "" {
+		envFile = filepath.Join(filepath.Dir(fileName), envFile)
+	}
+	params.EnvironmentVariables, err = common.Environment(envFile, config.EnvironmentVariables, "")
+	if err == nil {
+		params.EnvironmentVariables, err = secret.Resolve(ctx, params.EnvironmentVariables, resolvers)
+	}
+	if err != nil {
+		return common.DeployParams{}, fmt.Errorf("environment variables of %s: %w", fileName, err)
+	}
+	return params, nil
+}
@@ -0,0 +1,162 @@
+package local
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const runtimeApiPrefix = "/2018-06-01/runtime/"
+
+// invocation is an event waiting for, or being handled by, the function
+type invocation struct {
+	requestId string
+	payload   []byte
+	deadline  time.Time
+	started   time.Time
+	done      chan response
+}
+
+// response is what the function posted for an invocation. FunctionError holds the errorType of an error response.
+type response struct {
+	payload       []byte
+	functionError string
+	finished      time.Time
+}
+
+// RuntimeApi implements the Lambda Runtime API the bootstrap of a custom runtime polls:
+// /runtime/invocation/next, /runtime/invocation/{id}/response, /runtime/invocation/{id}/error and /runtime/init/error.
+type RuntimeApi struct {
+	FunctionArn string
+	queue       chan *invocation
+	mutex       sync.Mutex
+	inFlight    map[string]*invocation
+	firstPoll   chan struct{}
+	polled      sync.Once
+	initError   chan response
+	closed      chan struct{}
+	closeOnce   sync.Once
+}
+
+func NewRuntimeApi(functionArn string) *RuntimeApi {
+	return &RuntimeApi{
+		FunctionArn: functionArn,
+		queue:       make(chan *invocation),
+		inFlight:    map[string]*invocation{},
+		firstPoll:   make(chan struct{}),
+		initError:   make(chan response, 1),
+		closed:      make(chan struct{}),
+	}
+}
+
+// submit hands the payload to the next poll of the function and returns the invocation to wait on
+func (api *RuntimeApi) submit(payload []byte, deadline time.Time) *invocation {
+	current := &invocation{
+		requestId: requestId(),
+		payload:   payload,
+		deadline:  deadline,
+		done:      make(chan response, 1),
+	}
+	go func() {
+		select {
+		case api.queue <- current:
+		case <-api.closed:
+		}
+	}()
+	return current
+}
+
+// Close releases the invocations nobody polled anymore
+func (api *RuntimeApi) Close() {
+	api.closeOnce.Do(func() { close(api.closed) })
+}
+
+func (api *RuntimeApi) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	path := strings.TrimPrefix(request.URL.Path, runtimeApiPrefix)
+	switch {
+	case request.Method == http.MethodGet && path == "invocation/next":
+		api.next(writer, request)
+	case request.Method == http.MethodPost && path == "init/error":
+		body, _ := io.ReadAll(request.Body)
+		select {
+		case api.initError <- response{payload: body, functionError: errorType(request, body)}:
+		default:
+		}
+		writer.WriteHeader(http.StatusAccepted)
+	case request.Method == http.MethodPost && strings.HasPrefix(path, "invocation/"):
+		requestId, kind, _ := strings.Cut(strings.TrimPrefix(path, "invocation/"), "/")
+		api.mutex.Lock()
+		current, found := api.inFlight[requestId]
+		delete(api.inFlight, requestId)
+		api.mutex.Unlock()
+		if !found {
+			http.Error(writer, `{"errorType":"InvalidRequestID","errorMessage":"unknown request id"}`, http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(request.Body)
+		switch kind {
+		case "response":
+			current.done <- response{payload: body, finished: time.Now()}
+		case "error":
+			current.done <- response{payload: body, functionError: errorType(request, body), finished: time.Now()}
+		default:
+			http.NotFound(writer, request)
+			return
+		}
+		writer.WriteHeader(http.StatusAccepted)
+	default:
+		http.NotFound(writer, request)
+	}
+}
+
+func (api *RuntimeApi) next(writer http.ResponseWriter, request *http.Request) {
+	api.polled.Do(func() { close(api.firstPoll) })
+	var current *invocation
+	select {
+	case current = <-api.queue:
+	case <-request.Context().Done():
+		return
+	case <-api.closed:
+		http.Error(writer, `{"errorType":"Runtime.Closed","errorMessage":"runtime is shutting down"}`, http.StatusGone)
+		return
+	}
+	api.mutex.Lock()
+	current.started = time.Now()
+	api.inFlight[current.requestId] = current
+	api.mutex.Unlock()
+	writer.Header().Set("Lambda-Runtime-Aws-Request-Id", current.requestId)
+	writer.Header().Set("Lambda-Runtime-Deadline-Ms", strconv.FormatInt(current.deadline.UnixMilli(), 10))
+	writer.Header().Set("Lambda-Runtime-Invoked-Function-Arn", api.FunctionArn)
+	writer.Header().Set("Lambda-Runtime-Trace-Id", "Root=1-00000000-000000000000000000000000;Sampled=0")
+	writer.Header().Set("Content-Type", "application/json")
+	writer.Write(current.payload)
+}
+
+// requestId returns a random UUID like the ones Lambda uses
+func requestId() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:])
+}
+
+// errorType reads the error type from the Lambda-Runtime-Function-Error-Type header or the errorType of the body
+func errorType(request *http.Request, body []byte) string {
+	if header := request.Header.Get("Lambda-Runtime-Function-Error-Type"); header != "" {
+		return header
+	}
+	var functionError struct {
+		ErrorType string `json:"errorType"`
+	}
+	if json.Unmarshal(body, &functionError) == nil && functionError.ErrorType != "" {
+		return functionError.ErrorType
+	}
+	return "Unhandled"
+}
@@ -0,0 +1,151 @@
+package local
+
+import (
+	"context"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/a-pavithraa/lambda-deploy/lambda"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// pool keeps warm containers of a function and starts new ones up to the concurrency limit.
+// Requests beyond the limit wait for a container to become free.
+type pool struct {
+	lambdaParams common.DeployParams
+	logs         io.Writer
+	slots        chan struct{}
+	mutex        sync.Mutex
+	idle         []*Container
+}
+
+func newPool(lambdaParams common.DeployParams, concurrency int, logs io.Writer) *pool {
+	return &pool{
+		lambdaParams: lambdaParams,
+		logs:         logs,
+		slots:        make(chan struct{}, concurrency),
+	}
+}
+
+func (functionPool *pool) invoke(ctx context.Context, payload []byte) (*lambda.InvokeResult, error) {
+	select {
+	case functionPool.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-functionPool.slots }()
+
+	functionPool.mutex.Lock()
+	var container *Container
+	if last := len(functionPool.idle) - 1; last >= 0 {
+		container = functionPool.idle[last]
+		functionPool.idle = functionPool.idle[:last]
+	}
+	functionPool.mutex.Unlock()
+	if container == nil {
+		log.Println("Starting container of function", functionPool.lambdaParams.FunctionName)
+		var err error
+		container, err = Start(functionPool.lambdaParams, functionPool.logs)
+		if err != nil {
+			return nil, err
+		}
+	}
+	result, err := container.Invoke(ctx, payload)
+	// Containers which timed out or exited are not reused
+	if container.Alive() {
+		functionPool.mutex.Lock()
+		functionPool.idle = append(functionPool.idle, container)
+		functionPool.mutex.Unlock()
+	}
+	return result, err
+}
+
+func (functionPool *pool) close() {
+	functionPool.mutex.Lock()
+	defer functionPool.mutex.Unlock()
+	for _, container := range functionPool.idle {
+		container.Stop()
+	}
+	functionPool.idle = nil
+}
+
+type route struct {
+	function ManifestFunction
+	pool     *pool
+}
+
+// Server exposes the functions of a manifest over HTTP like Function URLs or HTTP APIs
+type Server struct {
+	routes []route
+}
+
+func NewServer(manifest *Manifest, logs io.Writer) *Server {
+	server := &Server{}
+	for _, function := range manifest.Functions {
+		server.routes = append(server.routes, route{
+			function: function,
+			pool:     newPool(function.params, manifest.Concurrency, logs),
+		})
+	}
+	// The longest path wins, so /orders/items can be served apart from /orders
+	sort.Slice(server.routes, func(i, j int) bool {
+		return len(server.routes[i].function.Path) > len(server.routes[j].function.Path)
+	})
+	return server
+}
+
+// Routes returns the path and function name of each route
+func (server *Server) Routes() map[string]string {
+	routes := map[string]string{}
+	for _, serverRoute := range server.routes {
+		routes[serverRoute.function.Path] = serverRoute.function.params.FunctionName
+	}
+	return routes
+}
+
+func (server *Server) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	for _, serverRoute := range server.routes {
+		rawPath, found := mountedPath(request.URL.Path, serverRoute.function.Path)
+		if !found {
+			continue
+		}
+		payload, err := requestEvent(request, rawPath, serverRoute.function.Format)
+		if err != nil {
+			http.Error(writer, `{"message":"Bad Request"}`, http.StatusBadRequest)
+			return
+		}
+		result, err := serverRoute.pool.invoke(request.Context(), payload)
+		if err != nil {
+			log.Printf("Not able to invoke function %s. The reason is %s", serverRoute.function.params.FunctionName, err.Error())
+			http.Error(writer, `{"message":"Internal Server Error"}`, http.StatusInternalServerError)
+			return
+		}
+		log.Printf("%s %s -> %s %s\n", request.Method, request.URL.Path, serverRoute.function.params.FunctionName, result.Report)
+		writeResponse(writer, result)
+		return
+	}
+	http.Error(writer, `{"message":"Not Found"}`, http.StatusNotFound)
+}
+
+// mountedPath returns the path below the mount point, e.g. /42 for /orders/42 mounted at /orders
+func mountedPath(path string, mount string) (string, bool) {
+	mount = strings.TrimSuffix(mount, "/")
+	if path != mount && !strings.HasPrefix(path, mount+"/") {
+		return "", false
+	}
+	rawPath := strings.TrimPrefix(path, mount)
+	if rawPath == "" {
+		rawPath = "/"
+	}
+	return rawPath, true
+}
+
+// Close stops the warm containers
+func (server *Server) Close() {
+	for _, serverRoute := range server.routes {
+		serverRoute.pool.close()
+	}
+}
@@ -0,0 +1,168 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/a-pavithraa/lambda-deploy/lambda"
+	"github.com/a-pavithraa/lambda-deploy/secret"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestEvent(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "/orders/42?expand=items&expand=customer", strings.NewReader(`{"quantity":2}`))
+	request.Header.Set("X-Tenant", "acme")
+	request.Header.Set("Cookie", "session=abc")
+	payload, err := requestEvent(request, "/42", FormatFunctionUrl)
+	assert.NoError(t, err)
+	var event httpEvent
+	assert.NoError(t, json.Unmarshal(payload, &event))
+	assert.Equal(t, "2.0", event.Version)
+	assert.Equal(t, "/42", event.RawPath)
+	assert.Equal(t, "$default", event.RouteKey)
+	assert.Equal(t, "expand=items&expand=customer", event.RawQueryString)
+	assert.Equal(t, "items,customer", event.QueryStringParameters["expand"])
+	assert.Equal(t, "acme", event.Headers["x-tenant"])
+	assert.Equal(t, []string{"session=abc"}, event.Cookies)
+	assert.Equal(t, "POST", event.RequestContext.Http.Method)
+	assert.Equal(t, `{"quantity":2}`, event.Body)
+	assert.False(t, event.IsBase64Encoded)
+
+	request = httptest.NewRequest(http.MethodPut, "/upload", strings.NewReader("\xff\xfe"))
+	payload, err = requestEvent(request, "/upload", FormatApiGatewayV2)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(payload, &event))
+	assert.Equal(t, "PUT /upload", event.RouteKey)
+	assert.True(t, event.IsBase64Encoded)
+	assert.Equal(t, "//4=", event.Body)
+}
+
+func TestWriteResponse(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	writeResponse(recorder, &lambda.InvokeResult{Payload: []byte(`{"statusCode":404,"headers":{"content-type":"text/plain"},"body":"bm90IGZvdW5k","isBase64Encoded":true}`)})
+	assert.Equal(t, 404, recorder.Code)
+	assert.Equal(t, "text/plain", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "not found", recorder.Body.String())
+
+	// Without a statusCode the payload is the JSON body
+	recorder = httptest.NewRecorder()
+	writeResponse(recorder, &lambda.InvokeResult{Payload: []byte(`{"orderId":"42"}`)})
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, `{"orderId":"42"}`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	writeResponse(recorder, &lambda.InvokeResult{FunctionError: "Sandbox.Timedout"})
+	assert.Equal(t, http.StatusGatewayTimeout, recorder.Code)
+}
+
+func TestMountedPath(t *testing.T) {
+	rawPath, found := mountedPath("/orders/42", "/orders")
+	assert.True(t, found)
+	assert.Equal(t, "/42", rawPath)
+	rawPath, found = mountedPath("/orders", "/orders/")
+	assert.True(t, found)
+	assert.Equal(t, "/", rawPath)
+	_, found = mountedPath("/ordersx", "/orders")
+	assert.False(t, found)
+	rawPath, _ = mountedPath("/health", "/")
+	assert.Equal(t, "/health", rawPath)
+}
+
+func TestServer(t *testing.T) {
+	params := testParams(t)
+	directory := filepath.Dir(params.ZipFile)
+	config := `name: orders
+zip_file: function.zip
+runtime: provided.al2023
+time_out: 5
+env_file: local.env
+environment_variables:
+  STAGE: local
+`
+	assert.NoError(t, os.WriteFile(filepath.Join(directory, "orders.yaml"), []byte(config), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(directory, "local.env"), []byte("FAKE_BOOTSTRAP=1\nSTAGE=dev\n"), 0644))
+	manifestFile := filepath.Join(directory, "manifest.yaml")
+	assert.NoError(t, os.WriteFile(manifestFile, []byte("concurrency: 2\nfunctions:\n  - config: orders.yaml\n    path: /orders\n"), 0644))
+	manifest, err := ReadManifest(context.TODO(), manifestFile, nil)
+	assert.NoError(t, err)
+
+	server := NewServer(manifest, io.Discard)
+	defer server.Close()
+	assert.Equal(t, map[string]string{"/orders": "orders"}, server.Routes())
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	response, err := http.Post(httpServer.URL+"/orders/created", "application/json", strings.NewReader("{}"))
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(response.Body)
+	response.Body.Close()
+	assert.Equal(t, 201, response.StatusCode)
+	assert.Equal(t, "/orders/42", response.Header.Get("Location"))
+	assert.Equal(t, "created", string(body))
+
+	var wait sync.WaitGroup
+	for range 5 {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			response, err := http.Get(httpServer.URL + "/orders/42")
+			assert.NoError(t, err)
+			var event map[string]any
+			json.NewDecoder(response.Body).Decode(&event)
+			response.Body.Close()
+			assert.Equal(t, "/42", event["rawPath"])
+			assert.Equal(t, "local", event["stage"])
+		}()
+	}
+	wait.Wait()
+	// Containers are reused, never more than the concurrency limit
+	assert.LessOrEqual(t, len(server.routes[0].pool.idle), 2)
+
+	response, err = http.Get(httpServer.URL + "/payments")
+	assert.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, 404, response.StatusCode)
+}
+
+func TestReadManifestSingleFunction(t *testing.T) {
+	directory := t.TempDir()
+	configFile := filepath.Join(directory, "orders.yaml")
+	assert.NoError(t, os.WriteFile(configFile, []byte("name: orders\nzip_file: function.zip\nruntime: provided.al2\n"), 0644))
+	manifest, err := ReadManifest(context.TODO(), configFile, nil)
+	assert.NoError(t, err)
+	assert.Len(t, manifest.Functions, 1)
+	assert.Equal(t, "/", manifest.Functions[0].Path)
+	assert.Equal(t, FormatFunctionUrl, manifest.Functions[0].Format)
+	assert.Equal(t, filepath.Join(directory, "function.zip"), manifest.Functions[0].params.ZipFile)
+	assert.Equal(t, 60, manifest.Functions[0].params.Timeout)
+
+	assert.NoError(t, os.WriteFile(configFile, []byte("functions:\n  - config: orders.yaml\n    format: rest\n"), 0644))
+	_, err = ReadManifest(context.TODO(), configFile, nil)
+	assert.ErrorContains(t, err, "Format of / must be either function_url or apigw-v2")
+}
+
+func TestReadManifestResolvesReferences(t *testing.T) {
+	directory := t.TempDir()
+	configFile := filepath.Join(directory, "orders.yaml")
+	config := "name: orders\nzip_file: function.zip\nruntime: provided.al2\nenvironment_variables:\n  TOKEN: ${env:ORDERS_TOKEN}\n"
+	assert.NoError(t, os.WriteFile(configFile, []byte(config), 0644))
+	resolvers := map[string]secret.Resolver{"env": secret.EnvResolver{Lookup: func(name string) (string, bool) {
+		return "token-" + name, true
+	}}}
+	manifest, err := ReadManifest(context.TODO(), configFile, resolvers)
+	assert.NoError(t, err)
+	assert.Equal(t, "token-ORDERS_TOKEN", manifest.Functions[0].params.EnvironmentVariables["TOKEN"])
+
+	// Without a resolver for the scheme the reference is rejected instead of passed on literally
+	_, err = ReadManifest(context.TODO(), configFile, nil)
+	assert.ErrorContains(t, err, "unknown scheme env")
+}
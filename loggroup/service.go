@@ -0,0 +1,107 @@
+package loggroup
+
+import (
+	"context"
+	"fmt"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// Retention periods CloudWatch Logs accepts, in days
+var retentionDays = []int{1, 3, 5, 7, 14, 30, 60, 90, 120, 150, 180, 365, 400, 545, 731, 1096, 1827, 2192, 2557, 2922, 3288, 3653}
+
+func Client(ctx context.Context) *cloudwatchlogs.Client {
+	cfg, _ := config.LoadDefaultConfig(ctx)
+	return cloudwatchlogs.NewFromConfig(cfg)
+}
+
+func ValidateLogGroup(lambdaParams common.DeployParams) error {
+	var errorMessage strings.Builder
+	if lambdaParams.LogRetentionDays != 0 && !slices.Contains(retentionDays, lambdaParams.LogRetentionDays) {
+		errorMessage.WriteString(fmt.Sprintf("Log retention of %d days is not supported. Possible values %v.\n", lambdaParams.LogRetentionDays, retentionDays))
+	}
+	if !common.TrimAndCheckEmptyString(&lambdaParams.LogKmsKeyArn) && common.ArnService(lambdaParams.LogKmsKeyArn) != "kms" {
+		errorMessage.WriteString("Log KMS Key ARN must be the ARN of a KMS key.\n")
+	}
+	if len(errorMessage.String()) > 0 {
+		return &common.InputError{
+			Message: errorMessage.String(),
+		}
+	}
+	return nil
+}
+
+func (wrapper ServiceWrapper) describe(ctx context.Context, name string) (*types.LogGroup, error) {
+	describeInput := &cloudwatchlogs.DescribeLogGroupsInput{LogGroupNamePrefix: aws.String(name)}
+	for {
+		output, err := wrapper.Client.DescribeLogGroups(ctx, describeInput)
+		if err != nil {
+			return nil, err
+		}
+		for _, logGroup := range output.LogGroups {
+			if aws.ToString(logGroup.LogGroupName) == name {
+				return &logGroup, nil
+			}
+		}
+		if output.NextToken == nil {
+			return nil, nil
+		}
+		describeInput.NextToken = output.NextToken
+	}
+}
+
+// Ensure creates the log group of the function before Lambda creates it implicitly, without retention
+// or encryption. Retention and key are only changed when they are configured, 0 and "" leave them as they are.
+func (wrapper ServiceWrapper) Ensure(ctx context.Context, lambdaParams common.DeployParams) error {
+	name := lambdaParams.LogGroupName()
+	logGroup, err := wrapper.describe(ctx, name)
+	if err != nil {
+		log.Printf("Not able to describe log group %s. The reason is %s", name, err.Error())
+		return err
+	}
+	if logGroup == nil {
+		log.Println("Creating log group--", name)
+		createInput := &cloudwatchlogs.CreateLogGroupInput{
+			LogGroupName: aws.String(name),
+			Tags:         lambdaParams.ResourceTags(),
+		}
+		if !common.TrimAndCheckEmptyString(&lambdaParams.LogKmsKeyArn) {
+			createInput.KmsKeyId = aws.String(lambdaParams.LogKmsKeyArn)
+		}
+		if _, err = wrapper.Client.CreateLogGroup(ctx, createInput); err != nil {
+			log.Printf("Not able to create log group %s. The reason is %s", name, err.Error())
+			return err
+		}
+		logGroup = &types.LogGroup{LogGroupName: aws.String(name), KmsKeyId: createInput.KmsKeyId}
+	}
+	if !common.TrimAndCheckEmptyString(&lambdaParams.LogKmsKeyArn) && aws.ToString(logGroup.KmsKeyId) != lambdaParams.LogKmsKeyArn {
+		log.Printf("Encrypting log group %s with %s\n", name, lambdaParams.LogKmsKeyArn)
+		_, err = wrapper.Client.AssociateKmsKey(ctx, &cloudwatchlogs.AssociateKmsKeyInput{
+			LogGroupName: aws.String(name),
+			KmsKeyId:     aws.String(lambdaParams.LogKmsKeyArn),
+		})
+		if err != nil {
+			log.Printf("Not able to encrypt log group %s. The reason is %s", name, err.Error())
+			return err
+		}
+	}
+	if lambdaParams.LogRetentionDays != 0 && int(aws.ToInt32(logGroup.RetentionInDays)) != lambdaParams.LogRetentionDays {
+		log.Printf("Setting retention of log group %s to %d days\n", name, lambdaParams.LogRetentionDays)
+		_, err = wrapper.Client.PutRetentionPolicy(ctx, &cloudwatchlogs.PutRetentionPolicyInput{
+			LogGroupName:    aws.String(name),
+			RetentionInDays: aws.Int32(int32(lambdaParams.LogRetentionDays)),
+		})
+		if err != nil {
+			log.Printf("Not able to set the retention of log group %s. The reason is %s", name, err.Error())
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package loggroup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockLogsApi struct {
+	logGroups map[string]*types.LogGroup
+	tags      map[string]map[string]string
+	calls     []string
+}
+
+func newMock() *mockLogsApi {
+	return &mockLogsApi{
+		logGroups: map[string]*types.LogGroup{},
+		tags:      map[string]map[string]string{},
+	}
+}
+
+func (m *mockLogsApi) DescribeLogGroups(ctx context.Context, params *cloudwatchlogs.DescribeLogGroupsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeLogGroupsOutput, error) {
+	output := &cloudwatchlogs.DescribeLogGroupsOutput{}
+	for _, logGroup := range m.logGroups {
+		output.LogGroups = append(output.LogGroups, *logGroup)
+	}
+	return output, nil
+}
+
+func (m *mockLogsApi) CreateLogGroup(ctx context.Context, params *cloudwatchlogs.CreateLogGroupInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogGroupOutput, error) {
+	m.calls = append(m.calls, "CreateLogGroup")
+	m.logGroups[*params.LogGroupName] = &types.LogGroup{LogGroupName: params.LogGroupName, KmsKeyId: params.KmsKeyId}
+	m.tags[*params.LogGroupName] = params.Tags
+	return &cloudwatchlogs.CreateLogGroupOutput{}, nil
+}
+
+func (m *mockLogsApi) PutRetentionPolicy(ctx context.Context, params *cloudwatchlogs.PutRetentionPolicyInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutRetentionPolicyOutput, error) {
+	m.calls = append(m.calls, "PutRetentionPolicy")
+	m.logGroups[*params.LogGroupName].RetentionInDays = params.RetentionInDays
+	return &cloudwatchlogs.PutRetentionPolicyOutput{}, nil
+}
+
+func (m *mockLogsApi) AssociateKmsKey(ctx context.Context, params *cloudwatchlogs.AssociateKmsKeyInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.AssociateKmsKeyOutput, error) {
+	m.calls = append(m.calls, "AssociateKmsKey")
+	m.logGroups[*params.LogGroupName].KmsKeyId = params.KmsKeyId
+	return &cloudwatchlogs.AssociateKmsKeyOutput{}, nil
+}
+
+const keyArn = "arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+
+func TestValidateLogGroup(t *testing.T) {
+	assert.NoError(t, ValidateLogGroup(common.DeployParams{}))
+	assert.NoError(t, ValidateLogGroup(common.DeployParams{LogRetentionDays: 30, LogKmsKeyArn: keyArn}))
+	assert.Error(t, ValidateLogGroup(common.DeployParams{LogRetentionDays: 10}))
+	assert.Error(t, ValidateLogGroup(common.DeployParams{LogKmsKeyArn: "arn:aws:sqs:us-east-1:123456789012:queue"}))
+}
+
+func TestEnsureCreatesLogGroup(t *testing.T) {
+	mock := newMock()
+	wrapper := ServiceWrapper{Client: mock}
+	lambdaParams := common.DeployParams{
+		FunctionName:     "orders",
+		LogRetentionDays: 14,
+		LogKmsKeyArn:     keyArn,
+		Tags:             map[string]string{"team": "payments"},
+	}
+	assert.NoError(t, wrapper.Ensure(context.TODO(), lambdaParams))
+	logGroup := mock.logGroups["/aws/lambda/orders"]
+	assert.Equal(t, int32(14), aws.ToInt32(logGroup.RetentionInDays))
+	assert.Equal(t, keyArn, aws.ToString(logGroup.KmsKeyId))
+	assert.Equal(t, []string{"CreateLogGroup", "PutRetentionPolicy"}, mock.calls)
+	assert.Equal(t, "payments", mock.tags["/aws/lambda/orders"]["team"])
+
+	// Nothing changes on the next deploy
+	mock.calls = nil
+	assert.NoError(t, wrapper.Ensure(context.TODO(), lambdaParams))
+	assert.Empty(t, mock.calls)
+}
+
+func TestEnsureCustomLogGroup(t *testing.T) {
+	mock := newMock()
+	mock.logGroups["/shared/orders"] = &types.LogGroup{LogGroupName: aws.String("/shared/orders"), RetentionInDays: aws.Int32(7)}
+	wrapper := ServiceWrapper{Client: mock}
+	lambdaParams := common.DeployParams{
+		FunctionName: "orders",
+		Logging:      &common.LoggingConfig{Format: "JSON", LogGroup: "/shared/orders"},
+		LogKmsKeyArn: keyArn,
+	}
+	assert.NoError(t, wrapper.Ensure(context.TODO(), lambdaParams))
+	assert.Equal(t, []string{"AssociateKmsKey"}, mock.calls)
+	// Without log_retention_days the retention is left alone
+	assert.Equal(t, int32(7), aws.ToInt32(mock.logGroups["/shared/orders"].RetentionInDays))
+	assert.NotContains(t, mock.logGroups, "/aws/lambda/orders")
+}
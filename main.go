@@ -2,17 +2,33 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
+	"fmt"
+	"github.com/a-pavithraa/lambda-deploy/autoscaling"
 	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/a-pavithraa/lambda-deploy/events"
+	"github.com/a-pavithraa/lambda-deploy/eventsource"
+	"github.com/a-pavithraa/lambda-deploy/httpapi"
 	"github.com/a-pavithraa/lambda-deploy/iam"
+	"github.com/a-pavithraa/lambda-deploy/kms"
 	"github.com/a-pavithraa/lambda-deploy/lambda"
+	"github.com/a-pavithraa/lambda-deploy/local"
+	"github.com/a-pavithraa/lambda-deploy/loggroup"
+	"github.com/a-pavithraa/lambda-deploy/schedule"
+	"github.com/a-pavithraa/lambda-deploy/secret"
+	"github.com/a-pavithraa/lambda-deploy/smoketest"
+	"github.com/a-pavithraa/lambda-deploy/trigger"
 	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
 	"github.com/aws/smithy-go"
 	"github.com/urfave/cli/v2"
 	"github.com/urfave/cli/v2/altsrc"
+	"io"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 )
 
@@ -38,6 +54,11 @@ func main() {
 				Usage:   "Execution policy of Lambda",
 			},
 		),
+		// Not read from the config file, as it confirms a single deploy
+		&cli.BoolFlag{
+			Name:  "architecture_rebuilt",
+			Usage: "Confirms the code was rebuilt when the architecture of an existing function changes",
+		},
 		altsrc.NewBoolFlag(
 			&cli.BoolFlag{
 				Name:    "autogenerate_execution_policy",
@@ -87,6 +108,36 @@ func main() {
 				Usage:   "Name of the Zip File",
 			},
 		),
+		altsrc.NewStringFlag(
+			&cli.StringFlag{
+				Name:    "image_uri",
+				Aliases: []string{"img"},
+				Value:   "",
+				Usage:   "URI of the container image in ECR",
+			},
+		),
+		altsrc.NewStringFlag(
+			&cli.StringFlag{
+				Name:    "package_type",
+				Aliases: []string{"pt"},
+				Value:   "",
+				Usage:   "Deployment package type - Possible values Zip and Image",
+			},
+		),
+		altsrc.NewStringSliceFlag(
+			&cli.StringSliceFlag{
+				Name:    "architectures",
+				Aliases: []string{"arch"},
+				Usage:   "Instruction set architecture of the Lambda function - Possible values x86_64 and arm64",
+			},
+		),
+		altsrc.NewStringSliceFlag(
+			&cli.StringSliceFlag{
+				Name:    "layers",
+				Aliases: []string{"l"},
+				Usage:   "Layers of the Lambda function - ARN of a layer version or name of a published layer",
+			},
+		),
 		altsrc.NewIntFlag(
 			&cli.IntFlag{
 				Name:    "memory",
@@ -103,12 +154,74 @@ func main() {
 				Usage:   "Timeout of the Lambda function",
 			},
 		),
+		// Not read through altsrc, as the config file may hold a map. The config file value is read by ReadConfigFile.
+		&cli.StringFlag{
+			Name:    "environment_variables",
+			Aliases: []string{"ev"},
+			Value:   "",
+			Usage:   "Environment variables of the Lambda function as JSON, overriding the ones of the config file",
+		},
 		altsrc.NewStringFlag(
 			&cli.StringFlag{
-				Name:    "environment_variables",
-				Aliases: []string{"ev"},
-				Value:   "",
-				Usage:   "Environment variables of the Lambda function",
+				Name:  "env_file",
+				Value: "",
+				Usage: "dotenv file with environment variables of the Lambda function",
+			},
+		),
+		altsrc.NewStringFlag(
+			&cli.StringFlag{
+				Name:  "kms_key_arn",
+				Value: "",
+				Usage: "ARN of the KMS key encrypting the environment variables at rest",
+			},
+		),
+		altsrc.NewStringFlag(
+			&cli.StringFlag{
+				Name:  "secret_scan",
+				Value: common.SecretScanWarn,
+				Usage: "warn, fail or off - what to do when environment variables look like secrets",
+			},
+		),
+		altsrc.NewIntFlag(
+			&cli.IntFlag{
+				Name:  "ephemeral_storage_mb",
+				Value: 0,
+				Usage: "Size of /tmp of the Lambda function in MB, between 512 and 10240",
+			},
+		),
+		altsrc.NewStringFlag(
+			&cli.StringFlag{
+				Name:  "snap_start",
+				Value: "",
+				Usage: "PublishedVersions or None - SnapStart of Java functions",
+			},
+		),
+		altsrc.NewStringFlag(
+			&cli.StringFlag{
+				Name:  "tracing",
+				Value: "",
+				Usage: "Active or PassThrough - X-Ray tracing mode of the Lambda function",
+			},
+		),
+		altsrc.NewIntFlag(
+			&cli.IntFlag{
+				Name:  "log_retention_days",
+				Value: 0,
+				Usage: "Days the logs of the Lambda function are kept, 0 leaves the retention as it is",
+			},
+		),
+		altsrc.NewStringFlag(
+			&cli.StringFlag{
+				Name:  "log_kms_key_arn",
+				Value: "",
+				Usage: "ARN of the KMS key encrypting the log group of the Lambda function",
+			},
+		),
+		altsrc.NewStringFlag(
+			&cli.StringFlag{
+				Name:  "env_merge",
+				Value: common.EnvMergeReplace,
+				Usage: "replace or merge - merge keeps the variables set on the function that are not declared",
 			},
 		),
 		altsrc.NewStringFlag(
@@ -127,6 +240,28 @@ func main() {
 				Usage:   "Role ARN",
 			},
 		),
+		altsrc.NewStringFlag(
+			&cli.StringFlag{
+				Name:  "smoke_test_report",
+				Value: "",
+				Usage: "File the JUnit XML report of the smoke tests is written to",
+			},
+		),
+		altsrc.NewStringFlag(
+			&cli.StringFlag{
+				Name:  "events_dir",
+				Value: events.DefaultDirectory,
+				Usage: "Directory of the event library used by the smoke tests",
+			},
+		),
+		altsrc.NewStringFlag(
+			&cli.StringFlag{
+				Name:    "dead_letter_target_arn",
+				Aliases: []string{"dlq"},
+				Value:   "",
+				Usage:   "ARN of the SQS queue or SNS topic receiving failed asynchronous events",
+			},
+		),
 	}
 	commands := []*cli.Command{
 		{
@@ -157,6 +292,202 @@ func main() {
 
 			Action: DeleteLambda,
 		},
+		{
+			Name:    "publish_layer",
+			Aliases: []string{"pl"},
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "name",
+					Usage: "Name of the layer",
+				},
+				&cli.StringFlag{
+					Name:  "directory",
+					Usage: "Directory whose contents are zipped into the layer",
+				},
+				&cli.StringFlag{
+					Name:  "description",
+					Usage: "Description of the layer version",
+				},
+				&cli.StringSliceFlag{
+					Name:  "compatible_runtimes",
+					Usage: "Runtimes the layer is compatible with",
+				},
+				&cli.StringSliceFlag{
+					Name:  "compatible_architectures",
+					Usage: "Architectures the layer is compatible with - Possible values x86_64 and arm64",
+				},
+			},
+			Usage: "Publishes a new version of a Lambda layer from a local directory",
+
+			Action: PublishLayer,
+		},
+		{
+			Name:    "prune_layer",
+			Aliases: []string{"prl"},
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "name",
+					Usage: "Name of the layer",
+				},
+				&cli.IntFlag{
+					Name:  "keep",
+					Value: 3,
+					Usage: "Number of latest versions to keep",
+				},
+			},
+			Usage: "Deletes old versions of a Lambda layer",
+
+			Action: PruneLayer,
+		},
+		{
+			Name:    "preview_schedule",
+			Aliases: []string{"ps"},
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "expression",
+					Usage: "cron or rate expression of the schedule",
+				},
+				&cli.IntFlag{
+					Name:  "count",
+					Value: 5,
+					Usage: "Number of fire times to show",
+				},
+			},
+			Usage: "Validates a schedule expression and shows its next fire times in UTC",
+
+			Action: PreviewSchedule,
+		},
+		{
+			Name:    "invoke",
+			Aliases: []string{"inv"},
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "name",
+					Usage: "Name of the Lambda function",
+				},
+				&cli.StringFlag{
+					Name:  "qualifier",
+					Usage: "Version or alias to invoke",
+				},
+				&cli.StringFlag{
+					Name:  "payload",
+					Usage: "JSON file holding the event, - reads it from stdin",
+				},
+				&cli.StringFlag{
+					Name:  "event",
+					Usage: "Name of an event in the event library",
+				},
+				&cli.StringFlag{
+					Name:  "events_dir",
+					Value: events.DefaultDirectory,
+					Usage: "Directory of the event library",
+				},
+				&cli.BoolFlag{
+					Name:  "async",
+					Usage: "Invoke asynchronously, without waiting for the response",
+				},
+				&cli.BoolFlag{
+					Name:  "stream",
+					Usage: "Invoke with response streaming, printing the response as it arrives",
+				},
+			},
+			Usage: "Invokes a Lambda and prints its response and log tail",
+
+			Action: Invoke,
+		},
+		{
+			Name:    "encrypt-env",
+			Aliases: []string{"ee"},
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "kms_key_arn",
+					Usage: "ARN of the KMS key to encrypt with",
+				},
+				&cli.StringFlag{
+					Name:  "name",
+					Usage: "Name of the Lambda function which decrypts the value",
+				},
+				&cli.StringFlag{
+					Name:  "value",
+					Usage: "Value to encrypt, - reads it from stdin",
+				},
+			},
+			Usage: "Encrypts a value for encrypted_environment_variables and prints the ciphertext",
+
+			Action: EncryptEnv,
+		},
+		{
+			Name:  "local",
+			Usage: "Runs Lambdas locally from their zip files",
+			Subcommands: []*cli.Command{
+				{
+					Name:   "invoke",
+					Before: altsrc.InitInputSourceWithContext(flags, altsrc.NewYamlSourceFromFlagFunc("config")),
+					Flags: append([]cli.Flag{
+						&cli.StringFlag{
+							Name:  "event",
+							Usage: "JSON file holding the event, - reads it from stdin, other names are looked up in the event library",
+						},
+					}, flags...),
+					Usage: "Invokes a provided.al2, provided.al2023 or Go Lambda locally against a built-in Runtime API",
+
+					Action: LocalInvoke,
+				},
+				{
+					Name: "serve",
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "config",
+							Usage: "yaml manifest listing the functions, or the config file of a single function",
+						},
+						&cli.IntFlag{
+							Name:  "port",
+							Value: 3000,
+							Usage: "Port to listen on",
+						},
+					},
+					Usage: "Serves Lambdas locally over HTTP like Function URLs or HTTP APIs",
+
+					Action: LocalServe,
+				},
+			},
+		},
+		{
+			Name:  "events",
+			Usage: "Manages the library of test events",
+			Subcommands: []*cli.Command{
+				{
+					Name:    "generate",
+					Aliases: []string{"gen"},
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "type",
+							Usage: "Event type - Possible values " + strings.Join(events.Types(), ", "),
+						},
+						&cli.StringSliceFlag{
+							Name:  "set",
+							Usage: "Overrides a field as path=value, e.g. Records[0].body=hello",
+						},
+						&cli.StringFlag{
+							Name:  "save",
+							Usage: "Name the event is saved under in the event library instead of printing it",
+						},
+						&cli.StringFlag{
+							Name:  "name",
+							Usage: "Name of the Lambda function the saved event belongs to, shared events are saved without it",
+						},
+						&cli.StringFlag{
+							Name:  "events_dir",
+							Value: events.DefaultDirectory,
+							Usage: "Directory of the event library",
+						},
+					},
+					Usage: "Generates a sample event of a common event source",
+
+					Action: GenerateEvent,
+				},
+			},
+		},
 	}
 
 	app := &cli.App{
@@ -191,34 +522,267 @@ func UpsertLambda(cCtx *cli.Context) error {
 		return err
 
 	}
+	err = eventsource.ValidateEventSources(lambdaParams.EventSources)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	err = httpapi.ValidateHttpApi(lambdaParams.HttpApi)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	err = schedule.ValidateSchedules(lambdaParams.FunctionName, lambdaParams.Schedules)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	err = trigger.ValidateS3Triggers(lambdaParams.S3Triggers)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	err = trigger.ValidateSnsTriggers(lambdaParams.SnsTriggers)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	err = smoketest.ValidateSmokeTests(lambdaParams.SmokeTests)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	err = autoscaling.ValidateAutoscaling(lambdaParams.ProvisionedConcurrency)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	err = loggroup.ValidateLogGroup(*lambdaParams)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	// Validated before the references are resolved, so the secret scan only sees values written in the config
+	err = lambda.ValidateInputParams(*lambdaParams, functionDetails == nil)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	lambdaParams.EnvironmentVariables, err = secret.Resolve(context.Background(), lambdaParams.EnvironmentVariables, secret.DefaultResolvers(context.Background()))
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	lambdaParams.EnvironmentVariables = lambda.WithEncryptedVariables(lambdaParams.EnvironmentVariables, lambdaParams.EncryptedVariables)
+	// Resolved values can be much longer than their references
+	err = lambda.ValidateEnvironmentSize(lambdaParams.EnvironmentVariables)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	for _, declared := range lambdaParams.Schedules {
+		fireTimes, _ := schedule.NextFireTimes(declared.Expression, time.Now(), 3)
+		log.Printf("Schedule %s next fires at %v\n", declared.Name, fireTimes)
+	}
+	lambdaParams.Layers, err = lambdaWrapper.ResolveLayers(context.Background(), lambdaParams.Layers)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	// Created ahead of the function, otherwise Lambda creates it on the first invocation without retention
+	logGroupWrapper := loggroup.ServiceWrapper{
+		Client: loggroup.Client(context.Background()),
+	}
+	err = logGroupWrapper.Ensure(context.Background(), *lambdaParams)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
 
+	var functionArn string
+	// The state before the deploy, restored when the smoke tests fail
+	var snapshot *lambda.FunctionSnapshot
 	if functionDetails == nil {
-		err = lambda.ValidateInputParams(*lambdaParams, true)
-		if err != nil {
-			log.Println(err)
-			return err
-		}
-		_, err := lambdaWrapper.New(context.Background(), *lambdaParams, iamWrapper)
+		output, err := lambdaWrapper.New(context.Background(), *lambdaParams, iamWrapper)
 		if err != nil {
 			log.Println(err)
 			return err
 		}
+		functionArn = *output.FunctionArn
 
 	} else {
-		err = lambda.ValidateInputParams(*lambdaParams, false)
+		functionArn = *functionDetails.Configuration.FunctionArn
+		if len(lambdaParams.SmokeTests) > 0 {
+			snapshot, err = lambda.Snapshot(functionDetails, lambda.DownloadCode)
+			if err != nil {
+				return err
+			}
+		}
+		currentArchitectures := functionDetails.Configuration.Architectures
+		err = lambda.CheckArchitectureChange(currentArchitectures, *lambdaParams)
 		if err != nil {
 			log.Println(err)
 			return err
 		}
+		if len(lambdaParams.Architectures) == 0 {
+			for _, architecture := range currentArchitectures {
+				lambdaParams.Architectures = append(lambdaParams.Architectures, string(architecture))
+			}
+		}
+		if lambdaParams.AutogenerateExecutionPolicy {
+			err = iamWrapper.PutManagedPermissions(context.Background(), *lambdaParams)
+			if err != nil {
+				log.Println(err)
+				return err
+			}
+		}
 		err := lambdaWrapper.UpdateFunction(context.Background(), *lambdaParams)
 		if err != nil {
 			log.Println(err)
 			return err
 		}
+		if lambdaParams.EnvMerge == common.EnvMergeMerge {
+			lambdaParams.EnvironmentVariables = lambda.MergeEnvironment(functionDetails.Configuration, lambdaParams.EnvironmentVariables)
+		}
 		err = UpdateFunctionConfiguration(lambdaWrapper, lambdaParams)
+		if err != nil {
+			return err
+		}
+		err = lambdaWrapper.ReconcileTags(context.Background(), functionArn, *lambdaParams)
+		if err != nil {
+			log.Println(err)
+			return err
+		}
 
+	}
+	err = lambdaWrapper.PutEventInvokeConfig(context.Background(), *lambdaParams)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	// The function may still be busy with its creation or the configuration update
+	err = retryOnConflict(func(ctx context.Context) error {
+		return lambdaWrapper.PutRuntimeManagement(ctx, *lambdaParams)
+	})
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	// The tool does not publish versions or aliases, so the provisioned qualifier has to exist already
+	err = lambdaWrapper.PutConcurrency(context.Background(), *lambdaParams)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	autoscalingWrapper := autoscaling.ServiceWrapper{
+		Client: autoscaling.Client(context.Background()),
+	}
+	err = autoscalingWrapper.Reconcile(context.Background(), lambdaParams.FunctionName, lambdaParams.ProvisionedConcurrency)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	functionUrls, err := lambdaWrapper.PutFunctionUrl(context.Background(), *lambdaParams)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	scheduleWrapper := schedule.ServiceWrapper{
+		Client: schedule.Client(context.Background()),
+	}
+	ruleArns, err := scheduleWrapper.Reconcile(context.Background(), lambdaParams.FunctionName, functionArn, lambdaParams.Schedules)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	httpApiWrapper := httpapi.ServiceWrapper{
+		Client: httpapi.Client(context.Background()),
+	}
+	httpApi, err := httpApiWrapper.Put(context.Background(), lambdaParams.FunctionName, functionArn, lambdaParams.HttpApi)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	permissions := append(lambdaParams.Permissions, lambda.FunctionUrlPermissions(functionUrls)...)
+	permissions = append(permissions, schedule.Permissions(ruleArns)...)
+	permissions = append(permissions, httpapi.Permissions(httpApi, functionArn)...)
+	// The previous buckets are read from the policy, as S3 does not allow searching notifications by function
+	previousBucketArns, err := lambdaWrapper.PermissionSourceArns(context.Background(), lambdaParams.FunctionName, trigger.S3Principal)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	previousBuckets := trigger.BucketNames(previousBucketArns)
+	s3Buckets := previousBuckets
+	if lambdaParams.S3Triggers != nil {
+		s3Buckets = trigger.Buckets(lambdaParams.S3Triggers)
+	}
+	permissions = append(permissions, trigger.S3Permissions(s3Buckets, common.ArnAccount(functionArn))...)
+	snsWrapper := trigger.SnsServiceWrapper{
+		Client: trigger.SnsClient(context.Background()),
+	}
+	// The previous topics are read from the policy as well, so only their subscriptions are listed
+	previousTopicArns, err := lambdaWrapper.PermissionSourceArns(context.Background(), lambdaParams.FunctionName, trigger.SnsPrincipal)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	topicArns := previousTopicArns
+	if lambdaParams.SnsTriggers != nil {
+		topicArns = nil
+		for _, snsTrigger := range lambdaParams.SnsTriggers {
+			topicArns = append(topicArns, snsTrigger.TopicArn)
+		}
+	}
+	permissions = append(permissions, trigger.SnsPermissions(topicArns)...)
+	// Permissions are reconciled before the triggers, as S3 validates that it can invoke the destination
+	err = lambdaWrapper.ReconcilePermissions(context.Background(), lambdaParams.FunctionName, permissions)
+	if err != nil {
+		log.Println(err)
 		return err
-
+	}
+	if lambdaParams.S3Triggers != nil {
+		s3Wrapper := trigger.S3ServiceWrapper{
+			Client: trigger.S3Client(context.Background()),
+		}
+		err = s3Wrapper.Reconcile(context.Background(), lambdaParams.FunctionName, functionArn, previousBuckets, lambdaParams.S3Triggers)
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+	}
+	if lambdaParams.SnsTriggers != nil {
+		err = snsWrapper.Reconcile(context.Background(), functionArn, previousTopicArns, lambdaParams.SnsTriggers)
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+	}
+	if lambdaParams.EventSources != nil {
+		eventSourceWrapper := eventsource.ServiceWrapper{
+			Client: eventsource.Client(context.Background()),
+		}
+		err = eventSourceWrapper.Reconcile(context.Background(), lambdaParams.FunctionName, lambdaParams.EventSources)
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+	}
+	if len(lambdaParams.SmokeTests) > 0 {
+		err = RunSmokeTests(lambdaWrapper, lambdaParams, snapshot)
+		if err != nil {
+			return err
+		}
+	}
+	for _, functionUrl := range functionUrls {
+		fmt.Println("Function URL:", functionUrl.Url)
+	}
+	if httpApi != nil {
+		fmt.Println("HTTP API endpoint:", httpApi.Endpoint)
+		if httpApi.DomainEndpoint != "" {
+			fmt.Println("Custom domain endpoint:", httpApi.DomainEndpoint)
+		}
 	}
 
 	return nil
@@ -226,26 +790,34 @@ func UpsertLambda(cCtx *cli.Context) error {
 }
 
 func UpdateFunctionConfiguration(lambdaWrapper lambda.ServiceWrapper, lambdaParams *common.DeployParams) error {
+	return retryOnConflict(func(ctx context.Context) error {
+		return lambdaWrapper.UpdateFunctionConfiguration(ctx, *lambdaParams)
+	})
+}
+
+// retryOnConflict retries the update while the function is still busy with the previous one
+func retryOnConflict(update func(ctx context.Context) error) error {
 	// Not able to perform 2 updates in succession immediately . So retrying till it is successful
 	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 	defer cancel()
 	var err error
 	for {
-		err = lambdaWrapper.UpdateFunctionConfiguration(ctx, *lambdaParams)
+		err = update(ctx)
 
 		if err != nil {
 
 			var apiErr smithy.APIError
-			if errors.As(err, &apiErr) {
-				switch apiErr.(type) {
-				case *types.ResourceConflictException:
-					log.Println("Resource Conflict Exception. Not able to update")
-					time.Sleep(2 * time.Second)
+			if !errors.As(err, &apiErr) {
+				return err
+			}
+			switch apiErr.(type) {
+			case *types.ResourceConflictException:
+				log.Println("Resource Conflict Exception. Not able to update")
+				time.Sleep(2 * time.Second)
 
-				default:
-					break
+			default:
+				return err
 
-				}
 			}
 		} else {
 			log.Println("Resource Updated successfully")
@@ -255,6 +827,55 @@ func UpdateFunctionConfiguration(lambdaWrapper lambda.ServiceWrapper, lambdaPara
 	return err
 }
 
+// RunSmokeTests invokes the deployed function with the smoke tests. When one fails, the code and
+// configuration are restored from the snapshot. A new function has no snapshot and is left in place.
+func RunSmokeTests(lambdaWrapper lambda.ServiceWrapper, lambdaParams *common.DeployParams, snapshot *lambda.FunctionSnapshot) error {
+	err := lambdaWrapper.WaitUntilUpdated(context.Background(), lambdaParams.FunctionName, 5*time.Minute)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	results := smoketest.Run(context.Background(), lambdaWrapper, lambdaParams.FunctionName, lambdaParams.EventsDirectory, lambdaParams.SmokeTests)
+	for _, result := range results {
+		status := "PASSED"
+		if !result.Passed() {
+			status = "FAILED " + strings.Join(result.Failures, "; ")
+		}
+		fmt.Printf("Smoke test %s (%v): %s\n", result.Name, result.Latency.Round(time.Millisecond), status)
+	}
+	if !common.TrimAndCheckEmptyString(&lambdaParams.SmokeTestReport) {
+		reportFile, err := os.Create(lambdaParams.SmokeTestReport)
+		if err != nil {
+			return err
+		}
+		defer reportFile.Close()
+		if err = smoketest.WriteJUnitReport(reportFile, lambdaParams.FunctionName, results); err != nil {
+			return err
+		}
+	}
+	if !smoketest.Failed(results) {
+		return nil
+	}
+	if snapshot == nil {
+		log.Println("Smoke tests failed on a new function. There is no earlier state to roll back to")
+		return fmt.Errorf("smoke tests of function %s failed", lambdaParams.FunctionName)
+	}
+	log.Println("Smoke tests failed. Rolling back function", lambdaParams.FunctionName)
+	err = retryOnConflict(func(ctx context.Context) error {
+		return lambdaWrapper.RestoreCode(ctx, snapshot)
+	})
+	if err != nil {
+		return err
+	}
+	err = retryOnConflict(func(ctx context.Context) error {
+		return lambdaWrapper.RestoreConfiguration(ctx, snapshot)
+	})
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("smoke tests of function %s failed, code and configuration were rolled back", lambdaParams.FunctionName)
+}
+
 func SetLambdaParams(cCtx *cli.Context) (*common.DeployParams, error) {
 	lambdaParams := common.DeployParams{
 		FunctionName:                cCtx.String("name"),
@@ -264,24 +885,58 @@ func SetLambdaParams(cCtx *cli.Context) (*common.DeployParams, error) {
 		KeyName:                     cCtx.String("s3_key"),
 		Region:                      cCtx.String("region"),
 		ZipFile:                     cCtx.String("zip_file"),
+		ImageUri:                    cCtx.String("image_uri"),
+		PackageType:                 cCtx.String("package_type"),
+		Architectures:               cCtx.StringSlice("architectures"),
+		Layers:                      cCtx.StringSlice("layers"),
+		ArchitectureRebuilt:         cCtx.Bool("architecture_rebuilt"),
 		Memory:                      cCtx.Int("memory"),
 		HandlerName:                 cCtx.String("handler_name"),
 		AutogenerateExecutionPolicy: cCtx.Bool("autogenerate_execution_policy"),
 		Action:                      cCtx.String("action_type"),
 		Timeout:                     cCtx.Int("time_out"),
 		RoleArn:                     cCtx.String("role_arn"),
+		DeadLetterTargetArn:         cCtx.String("dead_letter_target_arn"),
+		SmokeTestReport:             cCtx.String("smoke_test_report"),
+		EventsDirectory:             cCtx.String("events_dir"),
+		EnvMerge:                    cCtx.String("env_merge"),
+		SecretScan:                  cCtx.String("secret_scan"),
+		Tracing:                     cCtx.String("tracing"),
+		LogRetentionDays:            cCtx.Int("log_retention_days"),
+		LogKmsKeyArn:                cCtx.String("log_kms_key_arn"),
+		EphemeralStorage:            cCtx.Int("ephemeral_storage_mb"),
+		SnapStart:                   cCtx.String("snap_start"),
+		KmsKeyArn:                   cCtx.String("kms_key_arn"),
 	}
-	envVariables := cCtx.String("environment_variables")
-
-	if common.TrimAndCheckEmptyString(&envVariables) {
-		result := make(map[string]string)
-		if err := json.Unmarshal([]byte(envVariables), &result); err != nil {
-			log.Println(err)
-			return nil, err
-		}
-		lambdaParams.EnvironmentVariables = result
-
+	configFile, err := common.ReadConfigFile(cCtx.String("config"))
+	if err != nil {
+		log.Println(err)
+		return nil, err
 	}
+	lambdaParams.EnvironmentVariables, err = common.Environment(cCtx.String("env_file"), configFile.EnvironmentVariables, cCtx.String("environment_variables"))
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	lambdaParams.EncryptedVariables = configFile.EncryptedEnvironmentVariables
+	lambdaParams.ImageConfig = configFile.ImageConfig
+	lambdaParams.Vpc = configFile.Vpc
+	lambdaParams.Async = configFile.Async
+	lambdaParams.Logging = configFile.Logging
+	lambdaParams.FileSystemConfigs = configFile.FileSystemConfigs
+	lambdaParams.RuntimeManagement = configFile.RuntimeManagement
+	lambdaParams.EventSources = configFile.EventSources
+	lambdaParams.Permissions = configFile.Permissions
+	lambdaParams.FunctionUrl = configFile.FunctionUrl
+	lambdaParams.Schedules = configFile.Schedules
+	lambdaParams.HttpApi = configFile.HttpApi
+	lambdaParams.S3Triggers = configFile.S3Triggers
+	lambdaParams.SnsTriggers = configFile.SnsTriggers
+	lambdaParams.SmokeTests = configFile.SmokeTests
+	lambdaParams.ReservedConcurrency = configFile.ReservedConcurrency
+	lambdaParams.ProvisionedConcurrency = configFile.ProvisionedConcurrency
+	lambdaParams.Tags = configFile.Tags
+	lambdaParams.ToolTags = common.ToolTags(cCtx.String("config"), time.Now())
 	return &lambdaParams, nil
 }
 
@@ -299,11 +954,51 @@ func DeleteLambda(cCtx *cli.Context) error {
 		Client: lambda.Client(context.Background()),
 	}
 
+	// The buckets and topics are read from the policy, which is gone once the function is deleted
+	bucketArns, err := lambdaWrapper.PermissionSourceArns(context.Background(), name, trigger.S3Principal)
+	if err != nil {
+		return err
+	}
+	buckets := trigger.BucketNames(bucketArns)
+	topicArns, err := lambdaWrapper.PermissionSourceArns(context.Background(), name, trigger.SnsPrincipal)
+	if err != nil {
+		return err
+	}
+
 	functionDetails, err := lambdaWrapper.Delete(context.Background(), name)
 	if err != nil {
 
 		return err
 	}
+	functionArn := *functionDetails.Configuration.FunctionArn
+	s3Wrapper := trigger.S3ServiceWrapper{
+		Client: trigger.S3Client(context.Background()),
+	}
+	err = s3Wrapper.Reconcile(context.Background(), name, functionArn, buckets, []common.S3Trigger{})
+	if err != nil {
+		return err
+	}
+	snsWrapper := trigger.SnsServiceWrapper{
+		Client: trigger.SnsClient(context.Background()),
+	}
+	err = snsWrapper.Reconcile(context.Background(), functionArn, topicArns, []common.SnsTrigger{})
+	if err != nil {
+		return err
+	}
+	scheduleWrapper := schedule.ServiceWrapper{
+		Client: schedule.Client(context.Background()),
+	}
+	_, err = scheduleWrapper.Reconcile(context.Background(), name, functionArn, []common.Schedule{})
+	if err != nil {
+		return err
+	}
+	autoscalingWrapper := autoscaling.ServiceWrapper{
+		Client: autoscaling.Client(context.Background()),
+	}
+	err = autoscalingWrapper.Delete(context.Background(), name)
+	if err != nil {
+		return err
+	}
 	if common.TrimAndCheckEmptyString(&deleteRole) {
 		if deleteRole == "Y" {
 
@@ -319,3 +1014,243 @@ func DeleteLambda(cCtx *cli.Context) error {
 	return nil
 
 }
+
+func PublishLayer(cCtx *cli.Context) error {
+	layerParams := lambda.LayerParams{
+		LayerName:               cCtx.String("name"),
+		Directory:               cCtx.String("directory"),
+		Description:             cCtx.String("description"),
+		CompatibleRuntimes:      cCtx.StringSlice("compatible_runtimes"),
+		CompatibleArchitectures: cCtx.StringSlice("compatible_architectures"),
+	}
+	if err := lambda.ValidateLayerParams(layerParams); err != nil {
+		log.Println(err)
+		return err
+	}
+	lambdaWrapper := lambda.ServiceWrapper{
+		Client: lambda.Client(context.Background()),
+	}
+	output, err := lambdaWrapper.PublishLayer(context.Background(), layerParams)
+	if err != nil {
+		return err
+	}
+	fmt.Println(*output.LayerVersionArn)
+	return nil
+}
+
+func PruneLayer(cCtx *cli.Context) error {
+	name := cCtx.String("name")
+	if common.TrimAndCheckEmptyString(&name) {
+		return &common.InputError{
+			Message: "Layer Name cannot be null",
+		}
+	}
+	lambdaWrapper := lambda.ServiceWrapper{
+		Client: lambda.Client(context.Background()),
+	}
+	deleted, err := lambdaWrapper.PruneLayerVersions(context.Background(), name, cCtx.Int("keep"))
+	if err != nil {
+		return err
+	}
+	log.Printf("Deleted versions %v of layer %s\n", deleted, name)
+	return nil
+}
+
+func PreviewSchedule(cCtx *cli.Context) error {
+	fireTimes, err := schedule.NextFireTimes(cCtx.String("expression"), time.Now(), cCtx.Int("count"))
+	if err != nil {
+		return &common.InputError{
+			Message: err.Error(),
+		}
+	}
+	for _, fireTime := range fireTimes {
+		fmt.Println(fireTime.Format(time.RFC1123))
+	}
+	return nil
+}
+
+func Invoke(cCtx *cli.Context) error {
+	invokeParams := lambda.InvokeParams{
+		FunctionName: cCtx.String("name"),
+		Qualifier:    cCtx.String("qualifier"),
+		Async:        cCtx.Bool("async"),
+	}
+	if common.TrimAndCheckEmptyString(&invokeParams.FunctionName) {
+		return &common.InputError{
+			Message: "Function Name cannot be null",
+		}
+	}
+	if invokeParams.Async && cCtx.Bool("stream") {
+		return &common.InputError{
+			Message: "async and stream cannot be used together",
+		}
+	}
+	payloadFile := cCtx.String("payload")
+	if event := cCtx.String("event"); event != "" {
+		if payloadFile != "" {
+			return &common.InputError{
+				Message: "payload and event cannot be used together",
+			}
+		}
+		eventFile, err := events.Find(cCtx.String("events_dir"), invokeParams.FunctionName, event)
+		if err != nil {
+			return err
+		}
+		payloadFile = eventFile
+	}
+	var err error
+	invokeParams.Payload, err = lambda.ReadPayload(payloadFile, os.Stdin)
+	if err != nil {
+		return err
+	}
+	lambdaWrapper := lambda.ServiceWrapper{
+		Client: lambda.Client(context.Background()),
+	}
+	var result *lambda.InvokeResult
+	if cCtx.Bool("stream") {
+		result, err = lambdaWrapper.InvokeStream(context.Background(), invokeParams, os.Stdout)
+		fmt.Println()
+	} else {
+		result, err = lambdaWrapper.Invoke(context.Background(), invokeParams)
+	}
+	if err != nil {
+		return err
+	}
+	if invokeParams.Async {
+		fmt.Println("Status code:", result.StatusCode)
+		return nil
+	}
+	printInvokeResult(result)
+	return nil
+}
+
+func printInvokeResult(result *lambda.InvokeResult) {
+	if len(result.Payload) > 0 {
+		fmt.Println(string(result.Payload))
+	}
+	if result.FunctionError != "" {
+		fmt.Println("Function error:", result.FunctionError)
+	}
+	if len(result.Logs) > 0 {
+		fmt.Println("Log tail:")
+		for _, line := range result.Logs {
+			fmt.Println(line)
+		}
+	}
+	if result.Report != nil {
+		fmt.Println("Report:", result.Report)
+	}
+}
+
+// eventPayload reads the event from the file, or from the event library of the function when no such file exists
+func eventPayload(event string, eventsDirectory string, functionName string) ([]byte, error) {
+	if _, err := os.Stat(event); err != nil && event != "-" {
+		event, err = events.Find(eventsDirectory, functionName, event)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return lambda.ReadPayload(event, os.Stdin)
+}
+
+func LocalInvoke(cCtx *cli.Context) error {
+	lambdaParams, err := SetLambdaParams(cCtx)
+	if err != nil {
+		return err
+	}
+	lambdaParams.EnvironmentVariables, err = secret.Resolve(context.Background(), lambdaParams.EnvironmentVariables, secret.DefaultResolvers(context.Background()))
+	if err != nil {
+		return err
+	}
+	var payload []byte
+	if event := cCtx.String("event"); event != "" {
+		payload, err = eventPayload(event, lambdaParams.EventsDirectory, lambdaParams.FunctionName)
+		if err != nil {
+			return err
+		}
+	}
+	container, err := local.Start(*lambdaParams, os.Stderr)
+	if err != nil {
+		return err
+	}
+	defer container.Stop()
+	result, err := container.Invoke(context.Background(), payload)
+	if err != nil {
+		return err
+	}
+	printInvokeResult(result)
+	return nil
+}
+
+func EncryptEnv(cCtx *cli.Context) error {
+	keyArn := cCtx.String("kms_key_arn")
+	name := cCtx.String("name")
+	value := cCtx.String("value")
+	if common.TrimAndCheckEmptyString(&keyArn) || common.TrimAndCheckEmptyString(&name) {
+		return &common.InputError{
+			Message: "KMS Key ARN and Function Name cannot be null",
+		}
+	}
+	plaintext := []byte(value)
+	if value == "-" {
+		var err error
+		if plaintext, err = io.ReadAll(os.Stdin); err != nil {
+			return err
+		}
+		// The newline echo adds is not part of the value
+		plaintext = []byte(strings.TrimSuffix(strings.TrimSuffix(string(plaintext), "\n"), "\r"))
+	}
+	kmsWrapper := kms.ServiceWrapper{
+		Client: kms.Client(context.Background()),
+	}
+	ciphertext, err := kmsWrapper.Encrypt(context.Background(), keyArn, name, plaintext)
+	if err != nil {
+		return err
+	}
+	fmt.Println(ciphertext)
+	return nil
+}
+
+func GenerateEvent(cCtx *cli.Context) error {
+	event, err := events.Generate(cCtx.String("type"), time.Now(), cCtx.StringSlice("set"))
+	if err != nil {
+		return err
+	}
+	save := cCtx.String("save")
+	if common.TrimAndCheckEmptyString(&save) {
+		fmt.Println(string(event))
+		return nil
+	}
+	fileName, err := events.Save(cCtx.String("events_dir"), cCtx.String("name"), save, event)
+	if err != nil {
+		return err
+	}
+	log.Println("Saved event to", fileName)
+	return nil
+}
+
+func LocalServe(cCtx *cli.Context) error {
+	manifest, err := local.ReadManifest(context.Background(), cCtx.String("config"), secret.DefaultResolvers(context.Background()))
+	if err != nil {
+		return err
+	}
+	server := local.NewServer(manifest, os.Stderr)
+	defer server.Close()
+	httpServer := &http.Server{
+		Addr:    fmt.Sprintf("127.0.0.1:%d", cCtx.Int("port")),
+		Handler: server,
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		httpServer.Shutdown(context.Background())
+	}()
+	for path, functionName := range server.Routes() {
+		log.Printf("Serving %s at http://%s%s\n", functionName, httpServer.Addr, path)
+	}
+	if err = httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
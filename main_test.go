@@ -0,0 +1,28 @@
+package main
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+)
+
+func deployContext(arguments ...string) *cli.Context {
+	flags := flag.NewFlagSet("deploy", flag.ContinueOnError)
+	flags.String("name", "", "")
+	flags.String("environment_variables", "", "")
+	flags.Parse(arguments)
+	return cli.NewContext(cli.NewApp(), flags, nil)
+}
+
+func TestSetLambdaParamsEnvironmentVariables(t *testing.T) {
+	lambdaParams, err := SetLambdaParams(deployContext("-name=orders", `-environment_variables={"STAGE":"prod"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"STAGE": "prod"}, lambdaParams.EnvironmentVariables)
+
+	// Without variables the function keeps the ones it has
+	lambdaParams, err = SetLambdaParams(deployContext("-name=orders"))
+	assert.NoError(t, err)
+	assert.Nil(t, lambdaParams.EnvironmentVariables)
+}
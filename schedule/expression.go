@@ -0,0 +1,323 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Expression is a parsed EventBridge schedule expression
+type Expression interface {
+	// Next returns the first fire time strictly after the given time, or the zero time if there is none
+	Next(after time.Time) time.Time
+}
+
+type rateExpression struct {
+	interval time.Duration
+}
+
+func (r rateExpression) Next(after time.Time) time.Time {
+	return after.Add(r.interval)
+}
+
+type cronExpression struct {
+	minutes    []bool
+	hours      []bool
+	daysOfWeek []bool
+	months     []bool
+	years      []bool
+	// Day of month matching, one of these is set unless day of month is ?
+	daysOfMonth    []bool
+	lastDayOfMonth bool
+	nearestDay     int
+	// Day of week matching beyond plain values: nth weekday (d#n) or last weekday (dL) of the month
+	weekday       int
+	weekdayNth    int
+	lastWeekday   bool
+	anyDayOfMonth bool
+	anyDayOfWeek  bool
+}
+
+var monthNames = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+var dayNames = map[string]int{
+	"SUN": 1, "MON": 2, "TUE": 3, "WED": 4, "THU": 5, "FRI": 6, "SAT": 7,
+}
+
+const (
+	minYear = 1970
+	maxYear = 2199
+)
+
+// ParseExpression parses rate(value unit) and cron(minutes hours day-of-month month day-of-week year) expressions
+func ParseExpression(expression string) (Expression, error) {
+	expression = strings.TrimSpace(expression)
+	switch {
+	case strings.HasPrefix(expression, "rate(") && strings.HasSuffix(expression, ")"):
+		return parseRate(strings.TrimSuffix(strings.TrimPrefix(expression, "rate("), ")"))
+	case strings.HasPrefix(expression, "cron(") && strings.HasSuffix(expression, ")"):
+		return parseCron(strings.TrimSuffix(strings.TrimPrefix(expression, "cron("), ")"))
+	}
+	return nil, fmt.Errorf("schedule expression %q must be either rate(...) or cron(...)", expression)
+}
+
+// NextFireTimes returns the next count fire times of the expression after from.
+// Rate expressions are counted from from, since EventBridge counts them from the time the rule is created.
+func NextFireTimes(expression string, from time.Time, count int) ([]time.Time, error) {
+	parsed, err := ParseExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	var fireTimes []time.Time
+	next := from.UTC()
+	for len(fireTimes) < count {
+		next = parsed.Next(next)
+		if next.IsZero() {
+			break
+		}
+		fireTimes = append(fireTimes, next)
+	}
+	return fireTimes, nil
+}
+
+func parseRate(value string) (Expression, error) {
+	parts := strings.Fields(value)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("rate expression %q must be rate(value unit)", value)
+	}
+	amount, err := strconv.Atoi(parts[0])
+	if err != nil || amount <= 0 {
+		return nil, fmt.Errorf("rate value %q must be a positive number", parts[0])
+	}
+	units := map[string]time.Duration{"minute": time.Minute, "hour": time.Hour, "day": 24 * time.Hour}
+	unit := parts[1]
+	if amount == 1 && strings.HasSuffix(unit, "s") {
+		return nil, fmt.Errorf("rate unit must be singular for a value of 1, e.g. rate(1 %s)", strings.TrimSuffix(unit, "s"))
+	}
+	if amount > 1 {
+		if !strings.HasSuffix(unit, "s") {
+			return nil, fmt.Errorf("rate unit must be plural for a value greater than 1, e.g. rate(%d %ss)", amount, unit)
+		}
+		unit = strings.TrimSuffix(unit, "s")
+	}
+	duration, ok := units[unit]
+	if !ok {
+		return nil, fmt.Errorf("rate unit %q must be minutes, hours or days", parts[1])
+	}
+	return rateExpression{interval: time.Duration(amount) * duration}, nil
+}
+
+func parseCron(value string) (Expression, error) {
+	fields := strings.Fields(value)
+	if len(fields) != 6 {
+		return nil, fmt.Errorf("cron expression %q must have 6 fields: minutes hours day-of-month month day-of-week year", value)
+	}
+	cron := &cronExpression{}
+	var err error
+	if cron.minutes, err = parseField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("minutes: %w", err)
+	}
+	if cron.hours, err = parseField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("hours: %w", err)
+	}
+	if cron.months, err = parseField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	if cron.years, err = parseField(fields[5], minYear, maxYear, nil); err != nil {
+		return nil, fmt.Errorf("year: %w", err)
+	}
+	cron.anyDayOfMonth = fields[2] == "?"
+	cron.anyDayOfWeek = fields[4] == "?"
+	if cron.anyDayOfMonth == cron.anyDayOfWeek {
+		return nil, fmt.Errorf("exactly one of day-of-month and day-of-week must be ?")
+	}
+	if !cron.anyDayOfMonth {
+		if err = cron.parseDayOfMonth(fields[2]); err != nil {
+			return nil, fmt.Errorf("day-of-month: %w", err)
+		}
+	}
+	if !cron.anyDayOfWeek {
+		if err = cron.parseDayOfWeek(fields[4]); err != nil {
+			return nil, fmt.Errorf("day-of-week: %w", err)
+		}
+	}
+	return cron, nil
+}
+
+func (c *cronExpression) parseDayOfMonth(field string) error {
+	if field == "L" {
+		c.lastDayOfMonth = true
+		return nil
+	}
+	if strings.HasSuffix(field, "W") {
+		day, err := strconv.Atoi(strings.TrimSuffix(field, "W"))
+		if err != nil || day < 1 || day > 31 {
+			return fmt.Errorf("%q must be a day between 1 and 31 followed by W", field)
+		}
+		c.nearestDay = day
+		return nil
+	}
+	var err error
+	c.daysOfMonth, err = parseField(field, 1, 31, nil)
+	return err
+}
+
+func (c *cronExpression) parseDayOfWeek(field string) error {
+	if strings.Contains(field, "#") {
+		parts := strings.Split(field, "#")
+		day, err := parseValue(parts[0], 1, 7, dayNames)
+		if err != nil {
+			return err
+		}
+		nth, err := strconv.Atoi(parts[1])
+		if err != nil || nth < 1 || nth > 5 {
+			return fmt.Errorf("%q must be a weekday followed by # and a number between 1 and 5", field)
+		}
+		c.weekday, c.weekdayNth = day, nth
+		return nil
+	}
+	if strings.HasSuffix(field, "L") {
+		day, err := parseValue(strings.TrimSuffix(field, "L"), 1, 7, dayNames)
+		if err != nil {
+			return err
+		}
+		c.weekday, c.lastWeekday = day, true
+		return nil
+	}
+	var err error
+	c.daysOfWeek, err = parseField(field, 1, 7, dayNames)
+	return err
+}
+
+func parseValue(value string, min int, max int, names map[string]int) (int, error) {
+	if number, ok := names[strings.ToUpper(value)]; ok {
+		return number, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < min || number > max {
+		return 0, fmt.Errorf("%q must be between %d and %d", value, min, max)
+	}
+	return number, nil
+}
+
+// parseField parses lists of *, values, ranges and increments, e.g. 0,15,30-40,*/10
+func parseField(field string, min int, max int, names map[string]int) ([]bool, error) {
+	values := make([]bool, max+1)
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if index := strings.Index(part, "/"); index >= 0 {
+			var err error
+			step, err = strconv.Atoi(part[index+1:])
+			if err != nil || step <= 0 {
+				return nil, fmt.Errorf("increment in %q must be a positive number", part)
+			}
+			part = part[:index]
+		}
+		start, end := min, max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if start, err = parseValue(bounds[0], min, max, names); err != nil {
+				return nil, err
+			}
+			if end, err = parseValue(bounds[1], min, max, names); err != nil {
+				return nil, err
+			}
+			if start > end {
+				return nil, fmt.Errorf("range %q must go from low to high", part)
+			}
+		default:
+			var err error
+			if start, err = parseValue(part, min, max, names); err != nil {
+				return nil, err
+			}
+			if step == 1 {
+				end = start
+			}
+		}
+		for value := start; value <= end; value += step {
+			values[value] = true
+		}
+	}
+	return values, nil
+}
+
+func daysInMonth(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// nearestWeekday returns the weekday closest to the day without leaving the month
+func nearestWeekday(year int, month time.Month, day int) int {
+	lastDay := daysInMonth(year, month)
+	if day > lastDay {
+		day = lastDay
+	}
+	switch time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday() {
+	case time.Saturday:
+		if day == 1 {
+			return day + 2
+		}
+		return day - 1
+	case time.Sunday:
+		if day == lastDay {
+			return day - 2
+		}
+		return day + 1
+	}
+	return day
+}
+
+func (c *cronExpression) matchesDay(date time.Time) bool {
+	year, month, day := date.Date()
+	if !c.years[year] || !c.months[month] {
+		return false
+	}
+	if !c.anyDayOfMonth {
+		switch {
+		case c.lastDayOfMonth:
+			return day == daysInMonth(year, month)
+		case c.nearestDay > 0:
+			return day == nearestWeekday(year, month, c.nearestDay)
+		}
+		return c.daysOfMonth[day]
+	}
+	weekday := int(date.Weekday()) + 1
+	switch {
+	case c.weekdayNth > 0:
+		return weekday == c.weekday && (day-1)/7+1 == c.weekdayNth
+	case c.lastWeekday:
+		return weekday == c.weekday && day+7 > daysInMonth(year, month)
+	}
+	return c.daysOfWeek[weekday]
+}
+
+func (c *cronExpression) Next(after time.Time) time.Time {
+	after = after.UTC().Truncate(time.Minute)
+	date := time.Date(after.Year(), after.Month(), after.Day(), 0, 0, 0, 0, time.UTC)
+	for date.Year() <= maxYear {
+		if c.matchesDay(date) {
+			for hour := 0; hour < 24; hour++ {
+				if !c.hours[hour] {
+					continue
+				}
+				for minute := 0; minute < 60; minute++ {
+					if !c.minutes[minute] {
+						continue
+					}
+					fireTime := date.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+					if fireTime.After(after) {
+						return fireTime
+					}
+				}
+			}
+		}
+		date = date.AddDate(0, 0, 1)
+	}
+	return time.Time{}
+}
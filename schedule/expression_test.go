@@ -0,0 +1,58 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var from = time.Date(2024, time.January, 30, 10, 7, 0, 0, time.UTC)
+
+func fireTimes(t *testing.T, expression string, count int) []string {
+	times, err := NextFireTimes(expression, from, count)
+	assert.NoError(t, err)
+	var formatted []string
+	for _, fireTime := range times {
+		formatted = append(formatted, fireTime.Format("2006-01-02 15:04 Mon"))
+	}
+	return formatted
+}
+
+func TestRateExpression(t *testing.T) {
+	assert.Equal(t, []string{"2024-01-30 10:12 Tue", "2024-01-30 10:17 Tue"}, fireTimes(t, "rate(5 minutes)", 2))
+	assert.Equal(t, []string{"2024-01-31 10:07 Wed"}, fireTimes(t, "rate(1 day)", 1))
+
+	for _, expression := range []string{"rate(1 days)", "rate(2 hour)", "rate(0 minutes)", "rate(5 weeks)", "rate(5)"} {
+		_, err := ParseExpression(expression)
+		assert.Error(t, err, expression)
+	}
+}
+
+func TestCronExpression(t *testing.T) {
+	assert.Equal(t, []string{"2024-01-30 10:15 Tue", "2024-01-30 10:30 Tue", "2024-01-30 10:45 Tue"},
+		fireTimes(t, "cron(0/15 * * * ? *)", 3))
+	assert.Equal(t, []string{"2024-01-31 08:00 Wed", "2024-02-01 08:00 Thu", "2024-02-02 08:00 Fri", "2024-02-05 08:00 Mon"},
+		fireTimes(t, "cron(0 8 ? * MON-FRI *)", 4))
+	assert.Equal(t, []string{"2024-01-31 00:00 Wed", "2024-02-29 00:00 Thu"}, fireTimes(t, "cron(0 0 L * ? *)", 2))
+	assert.Equal(t, []string{"2024-02-12 12:00 Mon", "2024-03-11 12:00 Mon"}, fireTimes(t, "cron(0 12 ? * 2#2 *)", 2))
+	assert.Equal(t, []string{"2024-02-23 18:00 Fri"}, fireTimes(t, "cron(0 18 ? * 6L *)", 1))
+	// The 1st of June 2024 is a Saturday, so the nearest weekday is Monday the 3rd
+	assert.Equal(t, []string{"2024-06-03 09:00 Mon"}, fireTimes(t, "cron(0 9 1W JUN ? 2024)", 1))
+	assert.Empty(t, fireTimes(t, "cron(0 9 1 JAN ? 2023)", 1))
+}
+
+func TestInvalidCronExpression(t *testing.T) {
+	for _, expression := range []string{
+		"cron(0 8 * * *)",
+		"cron(0 8 * * * *)",
+		"cron(0 8 ? * ? *)",
+		"cron(60 8 * * ? *)",
+		"cron(0 8 ? * FUNDAY *)",
+		"cron(0 8 10-5 * ? *)",
+		"every day",
+	} {
+		_, err := ParseExpression(expression)
+		assert.Error(t, err, expression)
+	}
+}
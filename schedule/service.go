@@ -0,0 +1,189 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+)
+
+// Id of the function in the targets of the rules created by this tool
+const targetId = "lambda-deploy"
+
+const maxRuleNameLength = 64
+
+func Client(ctx context.Context) *eventbridge.Client {
+	cfg, _ := config.LoadDefaultConfig(ctx)
+	return eventbridge.NewFromConfig(cfg)
+}
+
+// RuleName is the name of the EventBridge rule of the schedule. Rules targeting the function
+// whose name starts with the function name are treated as owned by this tool.
+func RuleName(functionName string, scheduleName string) string {
+	return functionName + "-" + scheduleName
+}
+
+func ValidateSchedules(functionName string, schedules []common.Schedule) error {
+	var errorMessage strings.Builder
+	var names []string
+	for _, schedule := range schedules {
+		if common.TrimAndCheckEmptyString(&schedule.Name) {
+			errorMessage.WriteString("Schedule Name cannot be null.\n")
+			continue
+		}
+		if slices.Contains(names, schedule.Name) {
+			errorMessage.WriteString(fmt.Sprintf("Schedule %s is declared more than once.\n", schedule.Name))
+		}
+		names = append(names, schedule.Name)
+		if len(RuleName(functionName, schedule.Name)) > maxRuleNameLength {
+			errorMessage.WriteString(fmt.Sprintf("Rule name %s is longer than %d characters.\n", RuleName(functionName, schedule.Name), maxRuleNameLength))
+		}
+		if _, err := ParseExpression(schedule.Expression); err != nil {
+			errorMessage.WriteString(fmt.Sprintf("Schedule %s has an invalid expression: %s.\n", schedule.Name, err.Error()))
+		}
+		if _, err := input(schedule); err != nil {
+			errorMessage.WriteString(fmt.Sprintf("Schedule %s has an invalid input: %s.\n", schedule.Name, err.Error()))
+		}
+	}
+	if len(errorMessage.String()) > 0 {
+		return &common.InputError{
+			Message: errorMessage.String(),
+		}
+	}
+	return nil
+}
+
+// input returns the payload of the schedule as JSON. Strings are expected to hold JSON already.
+func input(schedule common.Schedule) (*string, error) {
+	if schedule.Input == nil {
+		return nil, nil
+	}
+	if text, ok := schedule.Input.(string); ok {
+		if !json.Valid([]byte(text)) {
+			return nil, fmt.Errorf("input must be valid JSON")
+		}
+		return &text, nil
+	}
+	payload, err := json.Marshal(schedule.Input)
+	if err != nil {
+		return nil, err
+	}
+	return aws.String(string(payload)), nil
+}
+
+// managedRules returns the names of the rules targeting the function which were created by this tool
+func (wrapper ServiceWrapper) managedRules(ctx context.Context, functionName string, functionArn string) ([]string, error) {
+	var ruleNames []string
+	listInput := &eventbridge.ListRuleNamesByTargetInput{TargetArn: &functionArn}
+	for {
+		output, err := wrapper.Client.ListRuleNamesByTarget(ctx, listInput)
+		if err != nil {
+			return nil, err
+		}
+		for _, ruleName := range output.RuleNames {
+			if strings.HasPrefix(ruleName, functionName+"-") {
+				ruleNames = append(ruleNames, ruleName)
+			}
+		}
+		if output.NextToken == nil {
+			break
+		}
+		listInput.NextToken = output.NextToken
+	}
+	return ruleNames, nil
+}
+
+func (wrapper ServiceWrapper) deleteRule(ctx context.Context, ruleName string) error {
+	log.Println("Deleting schedule rule", ruleName)
+	_, err := wrapper.Client.RemoveTargets(ctx, &eventbridge.RemoveTargetsInput{
+		Rule: &ruleName,
+		Ids:  []string{targetId},
+	})
+	if err != nil {
+		log.Printf("Not able to remove the target of rule %s. The reason is %s", ruleName, err.Error())
+		return err
+	}
+	_, err = wrapper.Client.DeleteRule(ctx, &eventbridge.DeleteRuleInput{Name: &ruleName})
+	if err != nil {
+		log.Printf("Not able to delete rule %s. The reason is %s", ruleName, err.Error())
+	}
+	return err
+}
+
+// Reconcile creates or updates a rule for every schedule and deletes the rules of schedules which
+// are no longer declared, so leaving out the schedules list deletes all of them.
+// It returns the ARNs of the rules in place, which need permission to invoke the function.
+func (wrapper ServiceWrapper) Reconcile(ctx context.Context, functionName string, functionArn string, schedules []common.Schedule) ([]string, error) {
+	existing, err := wrapper.managedRules(ctx, functionName, functionArn)
+	if err != nil {
+		log.Printf("Not able to list the schedule rules. The reason is %s", err.Error())
+		return nil, err
+	}
+	var ruleArns []string
+	var declared []string
+	for _, schedule := range schedules {
+		ruleName := RuleName(functionName, schedule.Name)
+		declared = append(declared, ruleName)
+		state := types.RuleStateEnabled
+		if schedule.Enabled != nil && !*schedule.Enabled {
+			state = types.RuleStateDisabled
+		}
+		log.Printf("Updating schedule rule %s with %s\n", ruleName, schedule.Expression)
+		ruleOutput, err := wrapper.Client.PutRule(ctx, &eventbridge.PutRuleInput{
+			Name:               &ruleName,
+			ScheduleExpression: &schedule.Expression,
+			State:              state,
+			Description:        aws.String("Schedule " + schedule.Name + " of " + functionName),
+		})
+		if err != nil {
+			log.Printf("Not able to update rule %s. The reason is %s", ruleName, err.Error())
+			return nil, err
+		}
+		payload, _ := input(schedule)
+		targetOutput, err := wrapper.Client.PutTargets(ctx, &eventbridge.PutTargetsInput{
+			Rule: &ruleName,
+			Targets: []types.Target{{
+				Id:    aws.String(targetId),
+				Arn:   &functionArn,
+				Input: payload,
+			}},
+		})
+		if err == nil && targetOutput.FailedEntryCount > 0 {
+			err = fmt.Errorf("%s", aws.ToString(targetOutput.FailedEntries[0].ErrorMessage))
+		}
+		if err != nil {
+			log.Printf("Not able to add the function to rule %s. The reason is %s", ruleName, err.Error())
+			return nil, err
+		}
+		ruleArns = append(ruleArns, *ruleOutput.RuleArn)
+	}
+	for _, ruleName := range existing {
+		if slices.Contains(declared, ruleName) {
+			continue
+		}
+		if err := wrapper.deleteRule(ctx, ruleName); err != nil {
+			return nil, err
+		}
+	}
+	return ruleArns, nil
+}
+
+// Permissions returns the permissions allowing the rules to invoke the function
+func Permissions(ruleArns []string) []common.Permission {
+	var permissions []common.Permission
+	for _, ruleArn := range ruleArns {
+		permissions = append(permissions, common.Permission{
+			Principal: "events.amazonaws.com",
+			SourceArn: ruleArn,
+		})
+	}
+	return permissions
+}
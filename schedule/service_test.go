@@ -0,0 +1,102 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const functionArn = "arn:aws:lambda:us-east-1:123456789012:function:report"
+
+type mockEventBridgeApi struct {
+	rules   map[string]*eventbridge.PutRuleInput
+	targets map[string][]types.Target
+}
+
+func ruleArn(name string) *string {
+	return aws.String("arn:aws:events:us-east-1:123456789012:rule/" + name)
+}
+
+func (m *mockEventBridgeApi) PutRule(ctx context.Context, params *eventbridge.PutRuleInput, optFns ...func(*eventbridge.Options)) (*eventbridge.PutRuleOutput, error) {
+	m.rules[*params.Name] = params
+	return &eventbridge.PutRuleOutput{RuleArn: ruleArn(*params.Name)}, nil
+}
+
+func (m *mockEventBridgeApi) PutTargets(ctx context.Context, params *eventbridge.PutTargetsInput, optFns ...func(*eventbridge.Options)) (*eventbridge.PutTargetsOutput, error) {
+	m.targets[*params.Rule] = params.Targets
+	return &eventbridge.PutTargetsOutput{}, nil
+}
+
+func (m *mockEventBridgeApi) ListRuleNamesByTarget(ctx context.Context, params *eventbridge.ListRuleNamesByTargetInput, optFns ...func(*eventbridge.Options)) (*eventbridge.ListRuleNamesByTargetOutput, error) {
+	output := &eventbridge.ListRuleNamesByTargetOutput{}
+	for name, targets := range m.targets {
+		for _, target := range targets {
+			if *target.Arn == *params.TargetArn {
+				output.RuleNames = append(output.RuleNames, name)
+			}
+		}
+	}
+	return output, nil
+}
+
+func (m *mockEventBridgeApi) RemoveTargets(ctx context.Context, params *eventbridge.RemoveTargetsInput, optFns ...func(*eventbridge.Options)) (*eventbridge.RemoveTargetsOutput, error) {
+	delete(m.targets, *params.Rule)
+	return &eventbridge.RemoveTargetsOutput{}, nil
+}
+
+func (m *mockEventBridgeApi) DeleteRule(ctx context.Context, params *eventbridge.DeleteRuleInput, optFns ...func(*eventbridge.Options)) (*eventbridge.DeleteRuleOutput, error) {
+	delete(m.rules, *params.Name)
+	return &eventbridge.DeleteRuleOutput{}, nil
+}
+
+func TestValidateSchedules(t *testing.T) {
+	assert.NoError(t, ValidateSchedules("report", []common.Schedule{
+		{Name: "nightly", Expression: "cron(0 2 * * ? *)", Input: map[string]any{"full": true}},
+		{Name: "hourly", Expression: "rate(1 hour)", Input: `{"full": false}`},
+	}))
+	assert.Error(t, ValidateSchedules("report", []common.Schedule{{Name: "nightly", Expression: "cron(0 2 * * * *)"}}))
+	assert.Error(t, ValidateSchedules("report", []common.Schedule{{Name: "nightly", Expression: "rate(1 hour)", Input: "not json"}}))
+	assert.Error(t, ValidateSchedules("report", []common.Schedule{{Expression: "rate(1 hour)"}}))
+}
+
+func TestReconcile(t *testing.T) {
+	mock := &mockEventBridgeApi{
+		rules: map[string]*eventbridge.PutRuleInput{"report-old": {Name: aws.String("report-old")}},
+		targets: map[string][]types.Target{
+			"report-old":    {{Id: aws.String(targetId), Arn: aws.String(functionArn)}},
+			"someone-elses": {{Id: aws.String("other"), Arn: aws.String(functionArn)}},
+		},
+	}
+	wrapper := ServiceWrapper{Client: mock}
+	disabled := false
+	schedules := []common.Schedule{
+		{Name: "nightly", Expression: "cron(0 2 * * ? *)", Input: map[string]any{"full": true}},
+		{Name: "hourly", Expression: "rate(1 hour)", Enabled: &disabled},
+	}
+
+	ruleArns, err := wrapper.Reconcile(context.TODO(), "report", functionArn, schedules)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{*ruleArn("report-nightly"), *ruleArn("report-hourly")}, ruleArns)
+	assert.Equal(t, `{"full":true}`, *mock.targets["report-nightly"][0].Input)
+	assert.Equal(t, types.RuleStateDisabled, mock.rules["report-hourly"].State)
+	assert.NotContains(t, mock.rules, "report-old")
+	assert.Contains(t, mock.targets, "someone-elses")
+
+	assert.Len(t, Permissions(ruleArns), 2)
+
+	ruleArns, err = wrapper.Reconcile(context.TODO(), "report", functionArn, []common.Schedule{schedules[0]})
+	assert.NoError(t, err)
+	assert.Len(t, ruleArns, 1)
+	assert.NotContains(t, mock.rules, "report-hourly")
+
+	// Removing the schedules block deletes the remaining rules
+	ruleArns, err = wrapper.Reconcile(context.TODO(), "report", functionArn, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, ruleArns)
+	assert.Empty(t, mock.rules)
+}
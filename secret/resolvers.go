@@ -0,0 +1,106 @@
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+// Schemes of the references, ${<scheme>:<reference>}
+const (
+	SchemeSsm            = "ssm"
+	SchemeSecretsManager = "secretsmanager"
+	SchemeEnv            = "env"
+	SchemeFile           = "file"
+)
+
+// SsmResolver reads SSM parameters, decrypting SecureString ones
+type SsmResolver struct {
+	Client SsmApi
+}
+
+func (resolver SsmResolver) Resolve(ctx context.Context, reference string) (string, error) {
+	output, err := resolver.Client.GetParameter(ctx, &ssm.GetParameterInput{
+		Name:           aws.String(reference),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(output.Parameter.Value), nil
+}
+
+// SecretsManagerResolver reads secrets. name#key picks a key of a secret holding a JSON object,
+// name alone returns the whole secret string.
+type SecretsManagerResolver struct {
+	Client SecretsManagerApi
+}
+
+func (resolver SecretsManagerResolver) Resolve(ctx context.Context, reference string) (string, error) {
+	name, key, hasKey := strings.Cut(reference, "#")
+	output, err := resolver.Client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(name)})
+	if err != nil {
+		return "", err
+	}
+	if output.SecretString == nil {
+		return "", fmt.Errorf("secret %s is binary and cannot be used as a variable", name)
+	}
+	if !hasKey {
+		return *output.SecretString, nil
+	}
+	var fields map[string]any
+	if err = json.Unmarshal([]byte(*output.SecretString), &fields); err != nil {
+		return "", fmt.Errorf("secret %s is not a JSON object, so key %s cannot be read", name, key)
+	}
+	value, found := fields[key]
+	if !found {
+		return "", fmt.Errorf("secret %s has no key %s", name, key)
+	}
+	if text, ok := value.(string); ok {
+		return text, nil
+	}
+	encoded, err := json.Marshal(value)
+	return string(encoded), err
+}
+
+// EnvResolver reads variables of the environment the tool runs in
+type EnvResolver struct {
+	Lookup func(name string) (string, bool)
+}
+
+func (resolver EnvResolver) Resolve(ctx context.Context, reference string) (string, error) {
+	value, found := resolver.Lookup(reference)
+	if !found {
+		return "", fmt.Errorf("environment variable %s is not set", reference)
+	}
+	return value, nil
+}
+
+// FileResolver reads the contents of a file. Relative paths are relative to the working directory.
+type FileResolver struct{}
+
+func (resolver FileResolver) Resolve(ctx context.Context, reference string) (string, error) {
+	contents, err := os.ReadFile(reference)
+	if err != nil {
+		return "", err
+	}
+	return string(contents), nil
+}
+
+// DefaultResolvers returns the resolvers of all schemes, backed by AWS and the local machine
+func DefaultResolvers(ctx context.Context) map[string]Resolver {
+	cfg, _ := config.LoadDefaultConfig(ctx)
+	return map[string]Resolver{
+		SchemeSsm:            SsmResolver{Client: ssm.NewFromConfig(cfg)},
+		SchemeSecretsManager: SecretsManagerResolver{Client: secretsmanager.NewFromConfig(cfg)},
+		SchemeEnv:            EnvResolver{Lookup: os.LookupEnv},
+		SchemeFile:           FileResolver{},
+	}
+}
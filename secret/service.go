@@ -0,0 +1,96 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// A reference is ${scheme:reference}. $${ stays a literal ${.
+var referencePattern = regexp.MustCompile(`\$?\$\{([a-z]+):([^}]*)\}`)
+
+// WithoutReferences returns the value with its references removed, leaving what is written literally
+func WithoutReferences(value string) string {
+	return referencePattern.ReplaceAllStringFunc(value, func(match string) string {
+		if strings.HasPrefix(match, "$$") {
+			return match[1:]
+		}
+		return ""
+	})
+}
+
+// validateReferences checks the references in the variables before any is resolved
+func validateReferences(variables map[string]string, resolvers map[string]Resolver) error {
+	var errorMessage strings.Builder
+	for _, name := range sortedNames(variables) {
+		for _, match := range referencePattern.FindAllStringSubmatch(variables[name], -1) {
+			if strings.HasPrefix(match[0], "$$") {
+				continue
+			}
+			if _, found := resolvers[match[1]]; !found {
+				errorMessage.WriteString(fmt.Sprintf("Variable %s refers to unknown scheme %s.\n", name, match[1]))
+			}
+			if strings.TrimSpace(match[2]) == "" {
+				errorMessage.WriteString(fmt.Sprintf("Variable %s has an empty %s reference.\n", name, match[1]))
+			}
+		}
+	}
+	if len(errorMessage.String()) > 0 {
+		return &common.InputError{
+			Message: errorMessage.String(),
+		}
+	}
+	return nil
+}
+
+// Resolve returns a copy of the variables with every reference replaced by the value it points to.
+// References may be embedded in longer values. Errors name the variable and reference, never a value.
+func Resolve(ctx context.Context, variables map[string]string, resolvers map[string]Resolver) (map[string]string, error) {
+	if variables == nil {
+		return nil, nil
+	}
+	if err := validateReferences(variables, resolvers); err != nil {
+		return nil, err
+	}
+	resolved := map[string]string{}
+	// The same reference is looked up once
+	cache := map[string]string{}
+	for _, name := range sortedNames(variables) {
+		var resolveErr error
+		resolved[name] = referencePattern.ReplaceAllStringFunc(variables[name], func(match string) string {
+			if resolveErr != nil {
+				return match
+			}
+			if strings.HasPrefix(match, "$$") {
+				return match[1:]
+			}
+			if value, found := cache[match]; found {
+				return value
+			}
+			parts := referencePattern.FindStringSubmatch(match)
+			value, err := resolvers[parts[1]].Resolve(ctx, parts[2])
+			if err != nil {
+				resolveErr = fmt.Errorf("not able to resolve %s of variable %s: %w", match, name, err)
+				return match
+			}
+			cache[match] = value
+			return value
+		})
+		if resolveErr != nil {
+			return nil, resolveErr
+		}
+	}
+	return resolved, nil
+}
+
+func sortedNames(variables map[string]string) []string {
+	var names []string
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
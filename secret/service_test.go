@@ -0,0 +1,113 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSsmApi struct {
+	parameters map[string]string
+	calls      int
+}
+
+func (m *mockSsmApi) GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+	m.calls++
+	value, found := m.parameters[*params.Name]
+	if !found || !*params.WithDecryption {
+		return nil, &types.ParameterNotFound{}
+	}
+	return &ssm.GetParameterOutput{Parameter: &types.Parameter{Value: aws.String(value)}}, nil
+}
+
+type mockSecretsManagerApi struct {
+	secrets map[string]string
+}
+
+func (m *mockSecretsManagerApi) GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	value, found := m.secrets[*params.SecretId]
+	if !found {
+		return nil, errors.New("secret not found")
+	}
+	return &secretsmanager.GetSecretValueOutput{SecretString: aws.String(value)}, nil
+}
+
+func fakeResolvers(ssmApi *mockSsmApi) map[string]Resolver {
+	return map[string]Resolver{
+		SchemeSsm: SsmResolver{Client: ssmApi},
+		SchemeSecretsManager: SecretsManagerResolver{Client: &mockSecretsManagerApi{secrets: map[string]string{
+			"orders/db":  `{"password":"s3cr3t","port":5432}`,
+			"orders/key": "plain-secret",
+		}}},
+		SchemeEnv: EnvResolver{Lookup: func(name string) (string, bool) {
+			value, found := map[string]string{"TOKEN": "from-env"}[name]
+			return value, found
+		}},
+		SchemeFile: FileResolver{},
+	}
+}
+
+func TestResolve(t *testing.T) {
+	certificate := filepath.Join(t.TempDir(), "cert.pem")
+	assert.NoError(t, os.WriteFile(certificate, []byte("-----BEGIN CERTIFICATE-----"), 0600))
+	ssmApi := &mockSsmApi{parameters: map[string]string{"/app/db_url": "postgres://db:5432/orders"}}
+
+	resolved, err := Resolve(context.Background(), map[string]string{
+		"DB_URL":      "${ssm:/app/db_url}",
+		"DB_URL_COPY": "${ssm:/app/db_url}",
+		"DB_PASSWORD": "${secretsmanager:orders/db#password}",
+		"DB_PORT":     "${secretsmanager:orders/db#port}",
+		"API_KEY":     "${secretsmanager:orders/key}",
+		"TOKEN":       "Bearer ${env:TOKEN}",
+		"CERT":        "${file:" + certificate + "}",
+		"LITERAL":     "$${env:TOKEN}",
+		"PLAIN":       "value",
+	}, fakeResolvers(ssmApi))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"DB_URL":      "postgres://db:5432/orders",
+		"DB_URL_COPY": "postgres://db:5432/orders",
+		"DB_PASSWORD": "s3cr3t",
+		"DB_PORT":     "5432",
+		"API_KEY":     "plain-secret",
+		"TOKEN":       "Bearer from-env",
+		"CERT":        "-----BEGIN CERTIFICATE-----",
+		"LITERAL":     "${env:TOKEN}",
+		"PLAIN":       "value",
+	}, resolved)
+	assert.Equal(t, 1, ssmApi.calls)
+
+	resolved, err = Resolve(context.Background(), nil, fakeResolvers(ssmApi))
+	assert.NoError(t, err)
+	assert.Nil(t, resolved)
+}
+
+func TestResolveErrors(t *testing.T) {
+	resolvers := fakeResolvers(&mockSsmApi{})
+	_, err := Resolve(context.Background(), map[string]string{"KEY": "${vault:secret/key}"}, resolvers)
+	assert.Error(t, err)
+
+	_, err = Resolve(context.Background(), map[string]string{"KEY": "${env:}"}, resolvers)
+	assert.Error(t, err)
+
+	_, err = Resolve(context.Background(), map[string]string{"KEY": "${secretsmanager:orders/db#user}"}, resolvers)
+	assert.Error(t, err)
+
+	// Resolved values never make it into the error
+	_, err = Resolve(context.Background(), map[string]string{
+		"A_TOKEN": "${env:TOKEN}",
+		"B_KEY":   "${env:MISSING}",
+	}, resolvers)
+	assert.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "B_KEY"))
+	assert.False(t, strings.Contains(err.Error(), "from-env"))
+}
@@ -0,0 +1,21 @@
+package secret
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+// Resolver looks up the value a reference points to. The reference is the part after the scheme,
+// /app/db_url for ${ssm:/app/db_url}.
+type Resolver interface {
+	Resolve(ctx context.Context, reference string) (string, error)
+}
+
+type SsmApi interface {
+	GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error)
+}
+
+type SecretsManagerApi interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
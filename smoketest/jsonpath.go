@@ -0,0 +1,78 @@
+package smoketest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pathStep is either an object key or an array index
+type pathStep struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parsePath parses a JSONPath made of $, .key and [index] steps, e.g. $.items[0].id
+func parsePath(path string) ([]pathStep, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("JSONPath %s must start with $", path)
+	}
+	var steps []pathStep
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[") + 1
+			if end == 0 {
+				end = len(rest)
+			}
+			if end == 1 {
+				return nil, fmt.Errorf("JSONPath %s has an empty key", path)
+			}
+			steps = append(steps, pathStep{key: rest[1:end]})
+			rest = rest[end:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("JSONPath %s has an unclosed [", path)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("JSONPath %s has an invalid index %s", path, rest[1:end])
+			}
+			steps = append(steps, pathStep{index: index, isIndex: true})
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("JSONPath %s is not supported, only .key and [index] steps are", path)
+		}
+	}
+	return steps, nil
+}
+
+// evaluatePath resolves the JSONPath against a document decoded by encoding/json
+func evaluatePath(document any, path string) (any, error) {
+	steps, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	current := document
+	for _, step := range steps {
+		if step.isIndex {
+			array, ok := current.([]any)
+			if !ok || step.index >= len(array) {
+				return nil, fmt.Errorf("%s has no element %d", path, step.index)
+			}
+			current = array[step.index]
+			continue
+		}
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s is not an object at .%s", path, step.key)
+		}
+		if current, ok = object[step.key]; !ok {
+			return nil, fmt.Errorf("%s has no key %s", path, step.key)
+		}
+	}
+	return current, nil
+}
@@ -0,0 +1,58 @@
+package smoketest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Details string `xml:",chardata"`
+}
+
+// WriteJUnitReport writes the results as a JUnit XML test suite named after the function
+func WriteJUnitReport(writer io.Writer, functionName string, results []Result) error {
+	suite := junitTestSuite{Name: functionName + " smoke tests", Tests: len(results)}
+	total := 0.0
+	for _, result := range results {
+		testCase := junitTestCase{
+			Name:      result.Name,
+			ClassName: functionName,
+			Time:      fmt.Sprintf("%.3f", result.Latency.Seconds()),
+		}
+		if !result.Passed() {
+			suite.Failures++
+			testCase.Failure = &junitFailure{
+				Message: result.Failures[0],
+				Details: strings.Join(result.Failures, "\n"),
+			}
+		}
+		total += result.Latency.Seconds()
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	suite.Time = fmt.Sprintf("%.3f", total)
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	return encoder.Encode(suite)
+}
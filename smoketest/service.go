@@ -0,0 +1,172 @@
+package smoketest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/a-pavithraa/lambda-deploy/events"
+	"github.com/a-pavithraa/lambda-deploy/lambda"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+func ValidateSmokeTests(smokeTests []common.SmokeTest) error {
+	var errorMessage strings.Builder
+	for index, smokeTest := range smokeTests {
+		name := smokeTest.Name
+		if common.TrimAndCheckEmptyString(&name) {
+			name = fmt.Sprint(index + 1)
+		}
+		payloadSources := 0
+		for _, present := range []bool{smokeTest.Payload != nil, smokeTest.PayloadFile != "", smokeTest.Event != ""} {
+			if present {
+				payloadSources++
+			}
+		}
+		if payloadSources > 1 {
+			errorMessage.WriteString(fmt.Sprintf("Smoke test %s can have only one of payload, payload file or event.\n", name))
+		}
+		if smokeTest.Equals != nil && smokeTest.JsonPath == "" {
+			errorMessage.WriteString(fmt.Sprintf("Smoke test %s needs a JSON path to compare with equals.\n", name))
+		}
+		if smokeTest.JsonPath != "" {
+			if _, err := parsePath(smokeTest.JsonPath); err != nil {
+				errorMessage.WriteString(fmt.Sprintf("Smoke test %s: %s.\n", name, err.Error()))
+			}
+		}
+		// The tool does not publish versions or move aliases, so only $LATEST runs the deployed code
+		// and can be rolled back
+		if qualifier := smokeTest.Qualifier; !common.TrimAndCheckEmptyString(&qualifier) && qualifier != "$LATEST" {
+			errorMessage.WriteString(fmt.Sprintf("Smoke test %s can only invoke $LATEST, not qualifier %s.\n", name, qualifier))
+		}
+		if _, err := regexp.Compile(smokeTest.Matches); err != nil {
+			errorMessage.WriteString(fmt.Sprintf("Smoke test %s has an invalid regular expression: %s.\n", name, err.Error()))
+		}
+	}
+	if len(errorMessage.String()) > 0 {
+		return &common.InputError{
+			Message: errorMessage.String(),
+		}
+	}
+	return nil
+}
+
+func payload(smokeTest common.SmokeTest, functionName string, eventsDirectory string) ([]byte, error) {
+	if smokeTest.Event != "" {
+		eventFile, err := events.Find(eventsDirectory, functionName, smokeTest.Event)
+		if err != nil {
+			return nil, err
+		}
+		return lambda.ReadPayload(eventFile, nil)
+	}
+	if smokeTest.PayloadFile != "" {
+		return lambda.ReadPayload(smokeTest.PayloadFile, os.Stdin)
+	}
+	if smokeTest.Payload == nil {
+		return nil, nil
+	}
+	if text, ok := smokeTest.Payload.(string); ok {
+		return []byte(text), nil
+	}
+	return json.Marshal(smokeTest.Payload)
+}
+
+// Run invokes the function once per smoke test and checks the responses. Events are looked up in eventsDirectory.
+func Run(ctx context.Context, invoker Invoker, functionName string, eventsDirectory string, smokeTests []common.SmokeTest) []Result {
+	var results []Result
+	for index, smokeTest := range smokeTests {
+		result := Result{Name: smokeTest.Name}
+		if result.Name == "" {
+			result.Name = fmt.Sprintf("smoke test %d", index+1)
+		}
+		invokePayload, err := payload(smokeTest, functionName, eventsDirectory)
+		if err != nil {
+			result.Failures = append(result.Failures, err.Error())
+			results = append(results, result)
+			continue
+		}
+		start := time.Now()
+		invokeResult, err := invoker.Invoke(ctx, lambda.InvokeParams{
+			FunctionName: functionName,
+			Qualifier:    smokeTest.Qualifier,
+			Payload:      invokePayload,
+		})
+		result.Latency = time.Since(start)
+		if err != nil {
+			result.Failures = append(result.Failures, err.Error())
+		} else {
+			result.Failures = check(smokeTest, invokeResult, result.Latency)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// Failed reports whether any of the smoke tests failed
+func Failed(results []Result) bool {
+	for _, result := range results {
+		if !result.Passed() {
+			return true
+		}
+	}
+	return false
+}
+
+func check(smokeTest common.SmokeTest, invokeResult *lambda.InvokeResult, latency time.Duration) []string {
+	var failures []string
+	statusCode := smokeTest.StatusCode
+	if statusCode == 0 {
+		statusCode = 200
+	}
+	if int(invokeResult.StatusCode) != statusCode {
+		failures = append(failures, fmt.Sprintf("expected status code %d but was %d", statusCode, invokeResult.StatusCode))
+	}
+	if invokeResult.FunctionError != "" && !smokeTest.AllowFunctionError {
+		failures = append(failures, fmt.Sprintf("function error %s: %s", invokeResult.FunctionError, invokeResult.Payload))
+	}
+	if smokeTest.MaxLatencyMs > 0 && latency > time.Duration(smokeTest.MaxLatencyMs)*time.Millisecond {
+		failures = append(failures, fmt.Sprintf("latency %v exceeds %d ms", latency.Round(time.Millisecond), smokeTest.MaxLatencyMs))
+	}
+	actual := string(invokeResult.Payload)
+	if smokeTest.JsonPath != "" {
+		var document any
+		if err := json.Unmarshal(invokeResult.Payload, &document); err != nil {
+			return append(failures, "response is not valid JSON")
+		}
+		value, err := evaluatePath(document, smokeTest.JsonPath)
+		if err != nil {
+			return append(failures, err.Error())
+		}
+		if smokeTest.Equals != nil && !equal(smokeTest.Equals, value) {
+			failures = append(failures, fmt.Sprintf("expected %s to equal %v but was %v", smokeTest.JsonPath, smokeTest.Equals, value))
+		}
+		if text, ok := value.(string); ok {
+			actual = text
+		} else {
+			encoded, _ := json.Marshal(value)
+			actual = string(encoded)
+		}
+	}
+	if smokeTest.Matches != "" && !regexp.MustCompile(smokeTest.Matches).MatchString(actual) {
+		failures = append(failures, fmt.Sprintf("expected %s to match %s", actual, smokeTest.Matches))
+	}
+	return failures
+}
+
+// equal compares the expected value decoded from yaml with the value decoded from the JSON response.
+// The expected value goes through JSON as well, so that numbers and maps have the same types.
+func equal(expected any, actual any) bool {
+	encoded, err := json.Marshal(expected)
+	if err != nil {
+		return false
+	}
+	var normalized any
+	if err = json.Unmarshal(encoded, &normalized); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(normalized, actual)
+}
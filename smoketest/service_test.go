@@ -0,0 +1,115 @@
+package smoketest
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/a-pavithraa/lambda-deploy/events"
+	"github.com/a-pavithraa/lambda-deploy/lambda"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeInvoker returns the same result for every invocation and records the payloads
+type fakeInvoker struct {
+	result   *lambda.InvokeResult
+	payloads []string
+}
+
+func (f *fakeInvoker) Invoke(ctx context.Context, invokeParams lambda.InvokeParams) (*lambda.InvokeResult, error) {
+	f.payloads = append(f.payloads, string(invokeParams.Payload))
+	return f.result, nil
+}
+
+func TestEvaluatePath(t *testing.T) {
+	document := map[string]any{"items": []any{map[string]any{"id": "a1"}}, "total": 3.0}
+	value, err := evaluatePath(document, "$.items[0].id")
+	assert.NoError(t, err)
+	assert.Equal(t, "a1", value)
+	value, err = evaluatePath(document, "$")
+	assert.NoError(t, err)
+	assert.Equal(t, document, value)
+	_, err = evaluatePath(document, "$.items[1]")
+	assert.ErrorContains(t, err, "has no element 1")
+	_, err = evaluatePath(document, "$.total.amount")
+	assert.ErrorContains(t, err, "is not an object")
+	_, err = parsePath("$..items")
+	assert.ErrorContains(t, err, "empty key")
+}
+
+func TestValidateSmokeTests(t *testing.T) {
+	assert.NoError(t, ValidateSmokeTests([]common.SmokeTest{{Name: "health", JsonPath: "$.status", Equals: "ok"}}))
+	err := ValidateSmokeTests([]common.SmokeTest{
+		{Name: "health", Equals: "ok", Matches: "("},
+		{Payload: map[string]any{}, PayloadFile: "event.json", JsonPath: "status"},
+		{Name: "alias", Qualifier: "live"},
+	})
+	assert.ErrorContains(t, err, "needs a JSON path")
+	assert.ErrorContains(t, err, "invalid regular expression")
+	assert.ErrorContains(t, err, "Smoke test 2 can have only one of payload, payload file or event")
+	assert.ErrorContains(t, err, "must start with $")
+	assert.ErrorContains(t, err, "Smoke test alias can only invoke $LATEST")
+	assert.NoError(t, ValidateSmokeTests([]common.SmokeTest{{Name: "latest", Qualifier: "$LATEST"}}))
+}
+
+func TestRun(t *testing.T) {
+	invoker := &fakeInvoker{result: &lambda.InvokeResult{
+		StatusCode: 200,
+		Payload:    []byte(`{"status":"ok","order":{"id":42,"tags":["new"]}}`),
+	}}
+	smokeTests := []common.SmokeTest{
+		{Name: "status", Payload: map[string]any{"path": "/health"}, JsonPath: "$.status", Equals: "ok"},
+		{Name: "order", JsonPath: "$.order", Equals: map[string]any{"id": 42, "tags": []any{"new"}}},
+		{Name: "regex", Matches: `"id":\d+`},
+		{Name: "wrong", JsonPath: "$.order.id", Equals: 43, StatusCode: 202},
+	}
+	results := Run(context.TODO(), invoker, "test", "", smokeTests)
+	assert.Equal(t, `{"path":"/health"}`, invoker.payloads[0])
+	assert.True(t, results[0].Passed())
+	assert.True(t, results[1].Passed())
+	assert.True(t, results[2].Passed())
+	assert.Equal(t, []string{"expected status code 202 but was 200", "expected $.order.id to equal 43 but was 42"}, results[3].Failures)
+	assert.True(t, Failed(results))
+
+	invoker.result = &lambda.InvokeResult{StatusCode: 200, FunctionError: "Unhandled", Payload: []byte(`{"errorMessage":"boom"}`)}
+	results = Run(context.TODO(), invoker, "test", "", []common.SmokeTest{{}, {AllowFunctionError: true, JsonPath: "$.errorMessage", Matches: "^bo+m$"}})
+	assert.Equal(t, "smoke test 1", results[0].Name)
+	assert.Equal(t, []string{`function error Unhandled: {"errorMessage":"boom"}`}, results[0].Failures)
+	assert.True(t, results[1].Passed())
+}
+
+func TestCheckLatency(t *testing.T) {
+	failures := check(common.SmokeTest{MaxLatencyMs: 100}, &lambda.InvokeResult{StatusCode: 200}, 250*time.Millisecond)
+	assert.Equal(t, []string{"latency 250ms exceeds 100 ms"}, failures)
+}
+
+func TestWriteJUnitReport(t *testing.T) {
+	results := []Result{
+		{Name: "status", Latency: 120 * time.Millisecond},
+		{Name: "wrong", Latency: 80 * time.Millisecond, Failures: []string{"expected status code 202 but was 200"}},
+	}
+	var buffer bytes.Buffer
+	assert.NoError(t, WriteJUnitReport(&buffer, "orders", results))
+	var suite junitTestSuite
+	assert.NoError(t, xml.Unmarshal(buffer.Bytes(), &suite))
+	assert.Equal(t, "orders smoke tests", suite.Name)
+	assert.Equal(t, 2, suite.Tests)
+	assert.Equal(t, 1, suite.Failures)
+	assert.Equal(t, "0.200", suite.Time)
+	assert.Nil(t, suite.TestCases[0].Failure)
+	assert.Equal(t, "expected status code 202 but was 200", suite.TestCases[1].Failure.Message)
+}
+
+func TestRunWithEvent(t *testing.T) {
+	directory := t.TempDir()
+	_, err := events.Save(directory, "test", "placed", []byte(`{"orderId":"42"}`))
+	assert.NoError(t, err)
+	invoker := &fakeInvoker{result: &lambda.InvokeResult{StatusCode: 200}}
+	results := Run(context.TODO(), invoker, "test", directory, []common.SmokeTest{{Event: "placed"}, {Event: "missing"}})
+	assert.JSONEq(t, `{"orderId":"42"}`, invoker.payloads[0])
+	assert.True(t, results[0].Passed())
+	assert.Contains(t, results[1].Failures[0], "Event missing not found")
+}
@@ -0,0 +1,23 @@
+package smoketest
+
+import (
+	"context"
+	"github.com/a-pavithraa/lambda-deploy/lambda"
+	"time"
+)
+
+// Invoker is satisfied by lambda.ServiceWrapper
+type Invoker interface {
+	Invoke(ctx context.Context, invokeParams lambda.InvokeParams) (*lambda.InvokeResult, error)
+}
+
+// Result holds the outcome of one smoke test. Failures is empty when the test passed.
+type Result struct {
+	Name     string
+	Latency  time.Duration
+	Failures []string
+}
+
+func (result Result) Passed() bool {
+	return len(result.Failures) == 0
+}
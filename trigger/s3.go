@@ -0,0 +1,150 @@
+package trigger
+
+import (
+	"context"
+	"fmt"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const S3Principal = "s3.amazonaws.com"
+
+func S3Client(ctx context.Context) *s3.Client {
+	cfg, _ := config.LoadDefaultConfig(ctx)
+	return s3.NewFromConfig(cfg)
+}
+
+func ValidateS3Triggers(triggers []common.S3Trigger) error {
+	var errorMessage strings.Builder
+	for _, trigger := range triggers {
+		if common.TrimAndCheckEmptyString(&trigger.Bucket) {
+			errorMessage.WriteString("S3 Trigger Bucket cannot be null.\n")
+		}
+		if len(trigger.Events) == 0 {
+			errorMessage.WriteString(fmt.Sprintf("S3 Trigger on %s must have at least one event.\n", trigger.Bucket))
+		}
+		for _, event := range trigger.Events {
+			if !strings.HasPrefix(event, "s3:") {
+				errorMessage.WriteString(fmt.Sprintf("S3 Trigger event %s must start with s3:, e.g. s3:ObjectCreated:*.\n", event))
+			}
+		}
+	}
+	if len(errorMessage.String()) > 0 {
+		return &common.InputError{
+			Message: errorMessage.String(),
+		}
+	}
+	return nil
+}
+
+func BucketArn(bucket string) string {
+	return "arn:aws:s3:::" + bucket
+}
+
+// BucketNames returns the bucket names of the bucket ARNs
+func BucketNames(bucketArns []string) []string {
+	var buckets []string
+	for _, bucketArn := range bucketArns {
+		buckets = append(buckets, strings.TrimPrefix(bucketArn, BucketArn("")))
+	}
+	return buckets
+}
+
+// Buckets returns the distinct buckets of the triggers
+func Buckets(triggers []common.S3Trigger) []string {
+	var buckets []string
+	for _, trigger := range triggers {
+		if !slices.Contains(buckets, trigger.Bucket) {
+			buckets = append(buckets, trigger.Bucket)
+		}
+	}
+	return buckets
+}
+
+// S3Permissions returns the permissions allowing the buckets in the account to invoke the function
+func S3Permissions(buckets []string, account string) []common.Permission {
+	var permissions []common.Permission
+	for _, bucket := range buckets {
+		permissions = append(permissions, common.Permission{
+			Principal:     S3Principal,
+			SourceArn:     BucketArn(bucket),
+			SourceAccount: account,
+		})
+	}
+	return permissions
+}
+
+func notificationFilter(trigger common.S3Trigger) *types.NotificationConfigurationFilter {
+	var rules []types.FilterRule
+	if trigger.Prefix != "" {
+		rules = append(rules, types.FilterRule{Name: types.FilterRuleNamePrefix, Value: aws.String(trigger.Prefix)})
+	}
+	if trigger.Suffix != "" {
+		rules = append(rules, types.FilterRule{Name: types.FilterRuleNameSuffix, Value: aws.String(trigger.Suffix)})
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	return &types.NotificationConfigurationFilter{Key: &types.S3KeyFilter{FilterRules: rules}}
+}
+
+// Reconcile replaces the notifications of the function in each bucket with the declared triggers.
+// Notifications of other consumers are kept. Buckets which no longer have triggers are passed in
+// previousBuckets, so the notifications of the function are removed from them.
+func (wrapper S3ServiceWrapper) Reconcile(ctx context.Context, functionName string, functionArn string, previousBuckets []string, triggers []common.S3Trigger) error {
+	buckets := Buckets(triggers)
+	for _, bucket := range previousBuckets {
+		if !slices.Contains(buckets, bucket) {
+			buckets = append(buckets, bucket)
+		}
+	}
+	for _, bucket := range buckets {
+		current, err := wrapper.Client.GetBucketNotificationConfiguration(ctx, &s3.GetBucketNotificationConfigurationInput{Bucket: aws.String(bucket)})
+		if err != nil {
+			log.Printf("Not able to read the notifications of bucket %s. The reason is %s", bucket, err.Error())
+			return err
+		}
+		notifications := &types.NotificationConfiguration{
+			EventBridgeConfiguration: current.EventBridgeConfiguration,
+			QueueConfigurations:      current.QueueConfigurations,
+			TopicConfigurations:      current.TopicConfigurations,
+		}
+		for _, configuration := range current.LambdaFunctionConfigurations {
+			if aws.ToString(configuration.LambdaFunctionArn) != functionArn {
+				notifications.LambdaFunctionConfigurations = append(notifications.LambdaFunctionConfigurations, configuration)
+			}
+		}
+		for index, trigger := range triggers {
+			if trigger.Bucket != bucket {
+				continue
+			}
+			var events []types.Event
+			for _, event := range trigger.Events {
+				events = append(events, types.Event(event))
+			}
+			notifications.LambdaFunctionConfigurations = append(notifications.LambdaFunctionConfigurations, types.LambdaFunctionConfiguration{
+				Id:                aws.String(fmt.Sprintf("%s-%d", functionName, index)),
+				LambdaFunctionArn: &functionArn,
+				Events:            events,
+				Filter:            notificationFilter(trigger),
+			})
+		}
+		log.Println("Updating notifications of bucket", bucket)
+		_, err = wrapper.Client.PutBucketNotificationConfiguration(ctx, &s3.PutBucketNotificationConfigurationInput{
+			Bucket:                    aws.String(bucket),
+			NotificationConfiguration: notifications,
+		})
+		if err != nil {
+			log.Printf("Not able to update the notifications of bucket %s. The reason is %s", bucket, err.Error())
+			return err
+		}
+	}
+	return nil
+}
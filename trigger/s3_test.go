@@ -0,0 +1,85 @@
+package trigger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const functionArn = "arn:aws:lambda:us-east-1:123456789012:function:orders"
+
+// fakeS3 keeps the notification configuration of each bucket in memory
+type fakeS3 struct {
+	notifications map[string]*types.NotificationConfiguration
+}
+
+func (f *fakeS3) GetBucketNotificationConfiguration(ctx context.Context, params *s3.GetBucketNotificationConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetBucketNotificationConfigurationOutput, error) {
+	output := &s3.GetBucketNotificationConfigurationOutput{}
+	if notifications := f.notifications[*params.Bucket]; notifications != nil {
+		output.LambdaFunctionConfigurations = notifications.LambdaFunctionConfigurations
+		output.QueueConfigurations = notifications.QueueConfigurations
+		output.TopicConfigurations = notifications.TopicConfigurations
+	}
+	return output, nil
+}
+
+func (f *fakeS3) PutBucketNotificationConfiguration(ctx context.Context, params *s3.PutBucketNotificationConfigurationInput, optFns ...func(*s3.Options)) (*s3.PutBucketNotificationConfigurationOutput, error) {
+	f.notifications[*params.Bucket] = params.NotificationConfiguration
+	return &s3.PutBucketNotificationConfigurationOutput{}, nil
+}
+
+func TestValidateS3Triggers(t *testing.T) {
+	assert.NoError(t, ValidateS3Triggers([]common.S3Trigger{{Bucket: "uploads", Events: []string{"s3:ObjectCreated:*"}}}))
+	err := ValidateS3Triggers([]common.S3Trigger{{Events: []string{"ObjectCreated"}}, {Bucket: "uploads"}})
+	assert.ErrorContains(t, err, "Bucket cannot be null")
+	assert.ErrorContains(t, err, "must start with s3:")
+	assert.ErrorContains(t, err, "at least one event")
+}
+
+func TestReconcileS3(t *testing.T) {
+	otherConsumer := types.LambdaFunctionConfiguration{
+		Id:                aws.String("other"),
+		LambdaFunctionArn: aws.String("arn:aws:lambda:us-east-1:123456789012:function:other"),
+		Events:            []types.Event{"s3:ObjectRemoved:*"},
+	}
+	queue := types.QueueConfiguration{QueueArn: aws.String("arn:aws:sqs:us-east-1:123456789012:audit")}
+	fake := &fakeS3{notifications: map[string]*types.NotificationConfiguration{
+		"uploads": {
+			LambdaFunctionConfigurations: []types.LambdaFunctionConfiguration{otherConsumer},
+			QueueConfigurations:          []types.QueueConfiguration{queue},
+		},
+	}}
+	wrapper := S3ServiceWrapper{Client: fake}
+	triggers := []common.S3Trigger{{Bucket: "uploads", Events: []string{"s3:ObjectCreated:*"}, Prefix: "incoming/", Suffix: ".csv"}}
+
+	assert.NoError(t, wrapper.Reconcile(context.TODO(), "orders", functionArn, nil, triggers))
+	// Running again replaces the entry of the function instead of adding another one
+	assert.NoError(t, wrapper.Reconcile(context.TODO(), "orders", functionArn, []string{"uploads"}, triggers))
+	uploads := fake.notifications["uploads"]
+	assert.Equal(t, []types.QueueConfiguration{queue}, uploads.QueueConfigurations)
+	assert.Len(t, uploads.LambdaFunctionConfigurations, 2)
+	assert.Equal(t, otherConsumer, uploads.LambdaFunctionConfigurations[0])
+	ours := uploads.LambdaFunctionConfigurations[1]
+	assert.Equal(t, functionArn, *ours.LambdaFunctionArn)
+	assert.Equal(t, []types.Event{"s3:ObjectCreated:*"}, ours.Events)
+	assert.Equal(t, []types.FilterRule{
+		{Name: types.FilterRuleNamePrefix, Value: aws.String("incoming/")},
+		{Name: types.FilterRuleNameSuffix, Value: aws.String(".csv")},
+	}, ours.Filter.Key.FilterRules)
+
+	// Removing the triggers keeps the other consumers
+	assert.NoError(t, wrapper.Reconcile(context.TODO(), "orders", functionArn, []string{"uploads"}, []common.S3Trigger{}))
+	assert.Equal(t, []types.LambdaFunctionConfiguration{otherConsumer}, fake.notifications["uploads"].LambdaFunctionConfigurations)
+}
+
+func TestS3Permissions(t *testing.T) {
+	assert.Equal(t, []string{"uploads", "archive"}, Buckets([]common.S3Trigger{{Bucket: "uploads"}, {Bucket: "archive"}, {Bucket: "uploads"}}))
+	assert.Equal(t, []string{"uploads"}, BucketNames([]string{"arn:aws:s3:::uploads"}))
+	assert.Equal(t, []common.Permission{{Principal: S3Principal, SourceArn: "arn:aws:s3:::uploads", SourceAccount: "123456789012"}},
+		S3Permissions([]string{"uploads"}, "123456789012"))
+}
@@ -0,0 +1,154 @@
+package trigger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+)
+
+const SnsPrincipal = "sns.amazonaws.com"
+
+func SnsClient(ctx context.Context) *sns.Client {
+	cfg, _ := config.LoadDefaultConfig(ctx)
+	return sns.NewFromConfig(cfg)
+}
+
+func ValidateSnsTriggers(triggers []common.SnsTrigger) error {
+	var errorMessage strings.Builder
+	for _, trigger := range triggers {
+		if common.ArnService(trigger.TopicArn) != "sns" {
+			errorMessage.WriteString(fmt.Sprintf("SNS Trigger Topic ARN %q must be the ARN of an SNS topic.\n", trigger.TopicArn))
+		}
+		if _, err := filterPolicy(trigger); err != nil {
+			errorMessage.WriteString(fmt.Sprintf("SNS Trigger on %s has an invalid filter policy: %s.\n", trigger.TopicArn, err.Error()))
+		}
+	}
+	if len(errorMessage.String()) > 0 {
+		return &common.InputError{
+			Message: errorMessage.String(),
+		}
+	}
+	return nil
+}
+
+// filterPolicy returns the filter policy as JSON. Strings are expected to hold JSON already.
+func filterPolicy(trigger common.SnsTrigger) (string, error) {
+	if trigger.FilterPolicy == nil {
+		return "", nil
+	}
+	if text, ok := trigger.FilterPolicy.(string); ok {
+		if !json.Valid([]byte(text)) {
+			return "", fmt.Errorf("filter policy must be valid JSON")
+		}
+		return text, nil
+	}
+	policy, err := json.Marshal(trigger.FilterPolicy)
+	return string(policy), err
+}
+
+// SnsPermissions returns the permissions allowing the topics to invoke the function
+func SnsPermissions(topicArns []string) []common.Permission {
+	var permissions []common.Permission
+	for _, topicArn := range topicArns {
+		permissions = append(permissions, common.Permission{
+			Principal: SnsPrincipal,
+			SourceArn: topicArn,
+		})
+	}
+	return permissions
+}
+
+// Subscriptions returns the subscription ARNs of the function to the topics keyed by topic ARN.
+// Topics which no longer exist are skipped.
+func (wrapper SnsServiceWrapper) Subscriptions(ctx context.Context, functionArn string, topicArns []string) (map[string]string, error) {
+	subscriptions := map[string]string{}
+	for _, topicArn := range topicArns {
+		listInput := &sns.ListSubscriptionsByTopicInput{TopicArn: aws.String(topicArn)}
+		for {
+			output, err := wrapper.Client.ListSubscriptionsByTopic(ctx, listInput)
+			var notFound *types.NotFoundException
+			if errors.As(err, &notFound) {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			for _, subscription := range output.Subscriptions {
+				if aws.ToString(subscription.Protocol) == "lambda" && aws.ToString(subscription.Endpoint) == functionArn {
+					subscriptions[topicArn] = *subscription.SubscriptionArn
+				}
+			}
+			if output.NextToken == nil {
+				break
+			}
+			listInput.NextToken = output.NextToken
+		}
+	}
+	return subscriptions, nil
+}
+
+// Reconcile subscribes the function to the declared topics and unsubscribes it from the previous
+// topics which are no longer declared. The previous topics are the ones the function policy allows.
+func (wrapper SnsServiceWrapper) Reconcile(ctx context.Context, functionArn string, previousTopicArns []string, triggers []common.SnsTrigger) error {
+	topicArns := slices.Clone(previousTopicArns)
+	for _, trigger := range triggers {
+		if !slices.Contains(topicArns, trigger.TopicArn) {
+			topicArns = append(topicArns, trigger.TopicArn)
+		}
+	}
+	subscriptions, err := wrapper.Subscriptions(ctx, functionArn, topicArns)
+	if err != nil {
+		log.Printf("Not able to list the SNS subscriptions. The reason is %s", err.Error())
+		return err
+	}
+	for _, trigger := range triggers {
+		policy, _ := filterPolicy(trigger)
+		subscriptionArn, found := subscriptions[trigger.TopicArn]
+		delete(subscriptions, trigger.TopicArn)
+		if !found {
+			log.Println("Subscribing to topic", trigger.TopicArn)
+			subscribeInput := &sns.SubscribeInput{
+				TopicArn:              aws.String(trigger.TopicArn),
+				Protocol:              aws.String("lambda"),
+				Endpoint:              &functionArn,
+				ReturnSubscriptionArn: true,
+			}
+			if policy != "" {
+				subscribeInput.Attributes = map[string]string{"FilterPolicy": policy}
+			}
+			if _, err := wrapper.Client.Subscribe(ctx, subscribeInput); err != nil {
+				log.Printf("Not able to subscribe to topic %s. The reason is %s", trigger.TopicArn, err.Error())
+				return err
+			}
+			continue
+		}
+		// An empty value clears the filter policy removed from the config
+		_, err := wrapper.Client.SetSubscriptionAttributes(ctx, &sns.SetSubscriptionAttributesInput{
+			SubscriptionArn: &subscriptionArn,
+			AttributeName:   aws.String("FilterPolicy"),
+			AttributeValue:  &policy,
+		})
+		if err != nil {
+			log.Printf("Not able to update the filter policy of %s. The reason is %s", subscriptionArn, err.Error())
+			return err
+		}
+	}
+	for topicArn, subscriptionArn := range subscriptions {
+		log.Println("Unsubscribing from topic", topicArn)
+		if _, err := wrapper.Client.Unsubscribe(ctx, &sns.UnsubscribeInput{SubscriptionArn: aws.String(subscriptionArn)}); err != nil {
+			log.Printf("Not able to unsubscribe from topic %s. The reason is %s", topicArn, err.Error())
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package trigger
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/a-pavithraa/lambda-deploy/common"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	topicArn        = "arn:aws:sns:us-east-1:123456789012:orders"
+	deletedTopicArn = "arn:aws:sns:us-east-1:123456789012:deleted"
+)
+
+// fakeSns keeps the subscriptions and their filter policies in memory
+type fakeSns struct {
+	subscriptions []types.Subscription
+	filterPolicy  map[string]string
+}
+
+func (f *fakeSns) ListSubscriptionsByTopic(ctx context.Context, params *sns.ListSubscriptionsByTopicInput, optFns ...func(*sns.Options)) (*sns.ListSubscriptionsByTopicOutput, error) {
+	if *params.TopicArn == deletedTopicArn {
+		return nil, &types.NotFoundException{}
+	}
+	output := &sns.ListSubscriptionsByTopicOutput{}
+	for _, subscription := range f.subscriptions {
+		if *subscription.TopicArn == *params.TopicArn {
+			output.Subscriptions = append(output.Subscriptions, subscription)
+		}
+	}
+	return output, nil
+}
+
+func (f *fakeSns) Subscribe(ctx context.Context, params *sns.SubscribeInput, optFns ...func(*sns.Options)) (*sns.SubscribeOutput, error) {
+	subscriptionArn := *params.TopicArn + ":" + strconv.Itoa(len(f.subscriptions))
+	f.subscriptions = append(f.subscriptions, types.Subscription{
+		SubscriptionArn: &subscriptionArn,
+		TopicArn:        params.TopicArn,
+		Protocol:        params.Protocol,
+		Endpoint:        params.Endpoint,
+	})
+	f.filterPolicy[subscriptionArn] = params.Attributes["FilterPolicy"]
+	return &sns.SubscribeOutput{SubscriptionArn: &subscriptionArn}, nil
+}
+
+func (f *fakeSns) SetSubscriptionAttributes(ctx context.Context, params *sns.SetSubscriptionAttributesInput, optFns ...func(*sns.Options)) (*sns.SetSubscriptionAttributesOutput, error) {
+	f.filterPolicy[*params.SubscriptionArn] = *params.AttributeValue
+	return &sns.SetSubscriptionAttributesOutput{}, nil
+}
+
+func (f *fakeSns) Unsubscribe(ctx context.Context, params *sns.UnsubscribeInput, optFns ...func(*sns.Options)) (*sns.UnsubscribeOutput, error) {
+	var subscriptions []types.Subscription
+	for _, subscription := range f.subscriptions {
+		if *subscription.SubscriptionArn != *params.SubscriptionArn {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	f.subscriptions = subscriptions
+	return &sns.UnsubscribeOutput{}, nil
+}
+
+func TestValidateSnsTriggers(t *testing.T) {
+	assert.NoError(t, ValidateSnsTriggers([]common.SnsTrigger{{TopicArn: topicArn, FilterPolicy: `{"type":["created"]}`}}))
+	err := ValidateSnsTriggers([]common.SnsTrigger{{TopicArn: "orders"}, {TopicArn: topicArn, FilterPolicy: "type=created"}})
+	assert.ErrorContains(t, err, "must be the ARN of an SNS topic")
+	assert.ErrorContains(t, err, "invalid filter policy")
+}
+
+func TestReconcileSns(t *testing.T) {
+	other := types.Subscription{
+		SubscriptionArn: aws.String(topicArn + ":other"),
+		TopicArn:        aws.String(topicArn),
+		Protocol:        aws.String("sqs"),
+		Endpoint:        aws.String("arn:aws:sqs:us-east-1:123456789012:audit"),
+	}
+	fake := &fakeSns{subscriptions: []types.Subscription{other}, filterPolicy: map[string]string{}}
+	wrapper := SnsServiceWrapper{Client: fake}
+
+	triggers := []common.SnsTrigger{{TopicArn: topicArn, FilterPolicy: map[string]any{"type": []any{"created"}}}}
+	assert.NoError(t, wrapper.Reconcile(context.TODO(), functionArn, []string{deletedTopicArn}, triggers))
+	assert.Len(t, fake.subscriptions, 2)
+	subscriptionArn := *fake.subscriptions[1].SubscriptionArn
+	assert.JSONEq(t, `{"type":["created"]}`, fake.filterPolicy[subscriptionArn])
+
+	// Existing subscriptions only get their filter policy updated
+	triggers[0].FilterPolicy = `{"type":["cancelled"]}`
+	assert.NoError(t, wrapper.Reconcile(context.TODO(), functionArn, []string{topicArn}, triggers))
+	assert.Len(t, fake.subscriptions, 2)
+	assert.JSONEq(t, `{"type":["cancelled"]}`, fake.filterPolicy[subscriptionArn])
+
+	// Removing the filter policy clears it
+	triggers[0].FilterPolicy = nil
+	assert.NoError(t, wrapper.Reconcile(context.TODO(), functionArn, []string{topicArn}, triggers))
+	assert.Equal(t, "", fake.filterPolicy[subscriptionArn])
+
+	assert.NoError(t, wrapper.Reconcile(context.TODO(), functionArn, []string{topicArn}, []common.SnsTrigger{}))
+	assert.Equal(t, []types.Subscription{other}, fake.subscriptions)
+}